@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/systems"
+)
+
+// runCommand runs the command line subcommand in args, such as
+// "systems graph", writing its output to out
+func runCommand(args []string, out io.Writer) error {
+	if len(args) >= 2 && args[0] == "systems" && args[1] == "graph" {
+		return runSystemsGraph(args[2:], out)
+	}
+	return fmt.Errorf("unknown command %q (want: systems graph)", args)
+}
+
+// runSystemsGraph renders the resolved system graph, e.g.
+//
+//	lbbaspack systems graph --format=mermaid --systems systems.json
+func runSystemsGraph(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("systems graph", flag.ContinueOnError)
+	flags.SetOutput(out)
+	formatFlag := flags.String("format", "dot", "graph format: dot or mermaid")
+	systemsFlag := flags.String("systems", "", "JSON file choosing the enabled systems")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := systems.ParseGraphFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+	config := systems.SystemConfig{}
+	if *systemsFlag != "" {
+		if config, err = systems.LoadSystemConfig(*systemsFlag); err != nil {
+			return err
+		}
+	}
+
+	var nextID uint64
+	factory := systems.NewSystemFactory(func() systems.Entity {
+		nextID++
+		return entities.NewEntity(nextID)
+	}, events.NewEventDispatcher())
+	factory.SetQuiet(true)
+	manager, err := factory.ResolveSystemManager(config)
+	if err != nil {
+		return err
+	}
+
+	return manager.WriteGraph(out, format)
+}
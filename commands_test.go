@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunSystemsGraph tests rendering the system graph from the command line
+func TestRunSystemsGraph(t *testing.T) {
+	var out bytes.Buffer
+	if err := runCommand([]string{"systems", "graph"}, &out); err != nil {
+		t.Fatalf("systems graph failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "digraph systems {") {
+		t.Errorf("Expected DOT output by default, got:\n%s", out.String())
+	}
+
+	config := filepath.Join(t.TempDir(), "systems.json")
+	if err := os.WriteFile(config, []byte(`{"disabled": ["particle"]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	out.Reset()
+	if err := runCommand([]string{"systems", "graph", "--format=mermaid", "--systems", config}, &out); err != nil {
+		t.Fatalf("systems graph failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "flowchart LR") || !strings.Contains(out.String(), "particle (disabled)") {
+		t.Errorf("Expected a Mermaid graph with particle disabled, got:\n%s", out.String())
+	}
+
+	if err := runCommand([]string{"systems", "graph", "--format=svg"}, &out); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+	if err := runCommand([]string{"systems", "list"}, &out); err == nil {
+		t.Error("Expected an unknown command to fail")
+	}
+}
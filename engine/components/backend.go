@@ -16,6 +16,12 @@ func (ba *BackendAssignment) GetType() string {
 	return "BackendAssignment"
 }
 
+// Clone implements Cloneable interface
+func (ba *BackendAssignment) Clone() Component {
+	clone := *ba
+	return &clone
+}
+
 // GetBackendID implements BackendAssignmentComponent interface
 func (ba *BackendAssignment) GetBackendID() int {
 	return ba.BackendID
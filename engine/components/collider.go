@@ -22,6 +22,17 @@ func (c *Collider) GetType() string {
 	return "Collider"
 }
 
+// Clone implements Cloneable interface
+func (c *Collider) Clone() Component {
+	clone := *c
+	return &clone
+}
+
+// Reset implements Resettable
+func (c *Collider) Reset() {
+	*c = Collider{Active: true}
+}
+
 // GetTag implements ColliderComponent interface
 func (c *Collider) GetTag() string {
 	return c.Tag
@@ -14,6 +14,12 @@ func (c *Combo) GetType() string {
 	return "Combo"
 }
 
+// Clone implements Cloneable interface
+func (c *Combo) Clone() Component {
+	clone := *c
+	return &clone
+}
+
 // GetCount implements ComboComponent interface
 func (c *Combo) GetCount() int {
 	return c.Streak
@@ -0,0 +1,23 @@
+package components
+
+import "fmt"
+
+// EntityHandle is a generational reference to an entity. Index names a slot
+// in the world's entity table and Generation the occupant of that slot, so a
+// handle to a removed entity goes stale instead of pointing at whichever
+// entity reuses the slot. The zero handle refers to no entity.
+type EntityHandle struct {
+	Index      uint32 `json:"index"`
+	Generation uint32 `json:"generation"`
+}
+
+// IsValid reports whether the handle was issued for an entity. A valid
+// handle may still be stale; only the world can tell.
+func (h EntityHandle) IsValid() bool {
+	return h.Generation != 0
+}
+
+// String formats the handle as index:generation
+func (h EntityHandle) String() string {
+	return fmt.Sprintf("%d:%d", h.Index, h.Generation)
+}
@@ -13,6 +13,7 @@ type TransformComponent interface {
 	GetX() float64
 	GetY() float64
 	SetPosition(x, y float64)
+	GetInterpolatedPosition(alpha float64) (float64, float64)
 }
 
 // SpriteComponent represents sprite functionality
@@ -23,6 +24,7 @@ type SpriteComponent interface {
 	GetColor() color.RGBA
 	IsVisible() bool
 	SetVisible(visible bool)
+	GetLayer() int
 }
 
 // ColliderComponent represents collider functionality
@@ -95,9 +97,24 @@ type PowerUpTypeComponent interface {
 // RoutingComponent represents routing functionality
 type RoutingComponent interface {
 	Component
-	GetTargetBackendID() int
+	GetTargetBackend() EntityHandle
 	IsPacketRouted() bool
 	GetRouteProgress() float64
 	SetRouteProgress(progress float64)
 	GetOriginalSpeed() float64
 }
+
+// Resettable is implemented by components that can be returned to the state
+// their constructor gives them, so that pooled instances can be reused
+type Resettable interface {
+	Component
+	Reset()
+}
+
+// Cloneable is implemented by components that can be copied, so that worlds
+// can be cloned for lookahead simulations. Clones share no state with the
+// original.
+type Cloneable interface {
+	Component
+	Clone() Component
+}
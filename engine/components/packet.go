@@ -20,6 +20,17 @@ func (pt *PacketType) GetType() string {
 	return "PacketType"
 }
 
+// Clone implements Cloneable interface
+func (pt *PacketType) Clone() Component {
+	clone := *pt
+	return &clone
+}
+
+// Reset implements Resettable
+func (pt *PacketType) Reset() {
+	*pt = PacketType{}
+}
+
 // GetName implements PacketTypeComponent interface
 func (pt *PacketType) GetName() string {
 	return pt.Name
@@ -30,20 +41,32 @@ func (pt *PacketType) GetPriority() int {
 	return pt.Value
 }
 
+var packetColors = []color.RGBA{
+	{255, 0, 0, 255},   // Red for HTTP
+	{0, 255, 0, 255},   // Green for HTTPS
+	{0, 0, 255, 255},   // Blue for TCP
+	{255, 255, 0, 255}, // Yellow for UDP
+	{255, 0, 255, 255}, // Magenta for WebSocket
+}
+
+var packetNames = []string{"HTTP", "HTTPS", "TCP", "UDP", "WebSocket"}
+
 // RandomPacketColor returns a random packet color
 func RandomPacketColor() color.RGBA {
-	packetColors := []color.RGBA{
-		{255, 0, 0, 255},   // Red for HTTP
-		{0, 255, 0, 255},   // Green for HTTPS
-		{0, 0, 255, 255},   // Blue for TCP
-		{255, 255, 0, 255}, // Yellow for UDP
-		{255, 0, 255, 255}, // Magenta for WebSocket
-	}
 	return packetColors[rand.Intn(len(packetColors))]
 }
 
+// RandomPacketColorFrom returns a random packet color drawn from r
+func RandomPacketColorFrom(r *rand.Rand) color.RGBA {
+	return packetColors[r.Intn(len(packetColors))]
+}
+
 // RandomPacketName returns a random packet type name
 func RandomPacketName() string {
-	packetNames := []string{"HTTP", "HTTPS", "TCP", "UDP", "WebSocket"}
 	return packetNames[rand.Intn(len(packetNames))]
 }
+
+// RandomPacketNameFrom returns a random packet type name drawn from r
+func RandomPacketNameFrom(r *rand.Rand) string {
+	return packetNames[r.Intn(len(packetNames))]
+}
@@ -23,6 +23,17 @@ func (p *Physics) GetType() string {
 	return "Physics"
 }
 
+// Clone implements Cloneable interface
+func (p *Physics) Clone() Component {
+	clone := *p
+	return &clone
+}
+
+// Reset implements Resettable
+func (p *Physics) Reset() {
+	*p = Physics{Mass: 1.0, Friction: 1.0}
+}
+
 // GetVelocityX implements PhysicsComponent interface
 func (p *Physics) GetVelocityX() float64 {
 	return p.VelocityX
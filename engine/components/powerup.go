@@ -15,6 +15,17 @@ func (p *PowerUpType) GetType() string {
 	return "PowerUpType"
 }
 
+// Clone implements Cloneable interface
+func (p *PowerUpType) Clone() Component {
+	clone := *p
+	return &clone
+}
+
+// Reset implements Resettable
+func (p *PowerUpType) Reset() {
+	*p = PowerUpType{}
+}
+
 // GetName implements PowerUpTypeComponent interface
 func (p *PowerUpType) GetName() string {
 	return p.Name
@@ -0,0 +1,60 @@
+package components
+
+import (
+	"sort"
+	"sync"
+)
+
+// Factory creates an empty component of a registered type, ready to be
+// populated by a decoder
+type Factory func() Component
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+func init() {
+	Register("Transform", func() Component { return &Transform{} })
+	Register("Sprite", func() Component { return &Sprite{} })
+	Register("Collider", func() Component { return &Collider{} })
+	Register("Physics", func() Component { return &Physics{} })
+	Register("PacketType", func() Component { return &PacketType{} })
+	Register("State", func() Component { return &State{} })
+	Register("Combo", func() Component { return &Combo{} })
+	Register("SLA", func() Component { return &SLA{} })
+	Register("BackendAssignment", func() Component { return &BackendAssignment{} })
+	Register("PowerUpType", func() Component { return &PowerUpType{} })
+	Register("Routing", func() Component { return &Routing{} })
+}
+
+// Register associates a component type name, as returned by GetType, with a
+// factory. Registering an existing name replaces its factory.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// New creates an empty component for a registered type name
+func New(name string) (Component, bool) {
+	registryMu.RLock()
+	factory, exists := registry[name]
+	registryMu.RUnlock()
+	if !exists {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// RegisteredTypes returns all registered component type names in sorted order
+func RegisteredTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,18 +2,18 @@ package components
 
 // Routing component tracks packets that are being routed to backends
 type Routing struct {
-	TargetBackendID int
-	IsRouted        bool
-	RouteProgress   float64
-	OriginalSpeed   float64 // Store the original packet speed
+	TargetBackend EntityHandle
+	IsRouted      bool
+	RouteProgress float64
+	OriginalSpeed float64 // Store the original packet speed
 }
 
-func NewRouting(targetBackendID int, originalSpeed float64) *Routing {
+func NewRouting(targetBackend EntityHandle, originalSpeed float64) *Routing {
 	return &Routing{
-		TargetBackendID: targetBackendID,
-		IsRouted:        true,
-		RouteProgress:   0.0,
-		OriginalSpeed:   originalSpeed,
+		TargetBackend: targetBackend,
+		IsRouted:      true,
+		RouteProgress: 0.0,
+		OriginalSpeed: originalSpeed,
 	}
 }
 
@@ -22,9 +22,20 @@ func (r *Routing) GetType() string {
 	return "Routing"
 }
 
-// GetTargetBackendID returns the target backend ID
-func (r *Routing) GetTargetBackendID() int {
-	return r.TargetBackendID
+// Clone implements Cloneable interface
+func (r *Routing) Clone() Component {
+	clone := *r
+	return &clone
+}
+
+// Reset implements Resettable
+func (r *Routing) Reset() {
+	*r = Routing{IsRouted: true}
+}
+
+// GetTargetBackend returns the handle of the backend the packet is routed to
+func (r *Routing) GetTargetBackend() EntityHandle {
+	return r.TargetBackend
 }
 
 // IsPacketRouted returns whether the packet is being routed
@@ -24,6 +24,12 @@ func (s *SLA) GetType() string {
 	return "SLA"
 }
 
+// Clone implements Cloneable interface
+func (s *SLA) Clone() Component {
+	clone := *s
+	return &clone
+}
+
 // GetCurrent implements SLAComponent interface
 func (s *SLA) GetCurrent() float64 {
 	return s.Current
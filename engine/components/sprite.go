@@ -2,6 +2,16 @@ package components
 
 import "image/color"
 
+// Rendering layers of the game's drawables, from back to front
+const (
+	LayerBackends = iota
+	LayerRoutes
+	LayerLoadBalancer
+	LayerPackets
+	LayerPowerUps
+	LayerEffects
+)
+
 // Sprite component represents visual representation
 type Sprite struct {
 	Width, Height float64
@@ -26,6 +36,17 @@ func (s *Sprite) GetType() string {
 	return "Sprite"
 }
 
+// Clone implements Cloneable interface
+func (s *Sprite) Clone() Component {
+	clone := *s
+	return &clone
+}
+
+// Reset implements Resettable
+func (s *Sprite) Reset() {
+	*s = Sprite{Visible: true}
+}
+
 // GetWidth implements SpriteComponent interface
 func (s *Sprite) GetWidth() float64 {
 	return s.Width
@@ -46,6 +67,11 @@ func (s *Sprite) IsVisible() bool {
 	return s.Visible
 }
 
+// GetLayer implements SpriteComponent interface
+func (s *Sprite) GetLayer() int {
+	return s.Layer
+}
+
 // SetColor sets the sprite color
 func (s *Sprite) SetColor(color color.RGBA) {
 	s.Color = color
@@ -6,6 +6,7 @@ const (
 	StateMenu StateType = iota
 	StatePlaying
 	StateGameOver
+	StatePaused
 )
 
 type State struct {
@@ -21,6 +22,12 @@ func (s *State) GetType() string {
 	return "State"
 }
 
+// Clone implements Cloneable interface
+func (s *State) Clone() Component {
+	clone := *s
+	return &clone
+}
+
 // GetState implements StateComponent interface
 func (s *State) GetState() string {
 	switch s.Current {
@@ -30,6 +37,8 @@ func (s *State) GetState() string {
 		return "playing"
 	case StateGameOver:
 		return "gameover"
+	case StatePaused:
+		return "paused"
 	default:
 		return "unknown"
 	}
@@ -44,5 +53,7 @@ func (s *State) SetState(state string) {
 		s.Current = StatePlaying
 	case "gameover":
 		s.Current = StateGameOver
+	case "paused":
+		s.Current = StatePaused
 	}
 }
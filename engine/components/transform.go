@@ -5,12 +5,16 @@ type Transform struct {
 	X, Y           float64
 	Rotation       float64
 	ScaleX, ScaleY float64
+	// PrevX and PrevY hold the position at the start of the current
+	// simulation step, for render interpolation
+	PrevX, PrevY float64
 }
 
 // NewTransform creates a new transform component
 func NewTransform(x, y float64) *Transform {
 	return &Transform{
 		X: x, Y: y,
+		PrevX: x, PrevY: y,
 		Rotation: 0,
 		ScaleX:   1, ScaleY: 1,
 	}
@@ -21,6 +25,17 @@ func (t *Transform) GetType() string {
 	return "Transform"
 }
 
+// Clone implements Cloneable interface
+func (t *Transform) Clone() Component {
+	clone := *t
+	return &clone
+}
+
+// Reset implements Resettable
+func (t *Transform) Reset() {
+	*t = Transform{ScaleX: 1, ScaleY: 1}
+}
+
 // GetX implements TransformComponent interface
 func (t *Transform) GetX() float64 {
 	return t.X
@@ -37,6 +52,17 @@ func (t *Transform) SetPosition(x, y float64) {
 	t.Y = y
 }
 
+// StorePrevious records the current position as the start of a simulation step
+func (t *Transform) StorePrevious() {
+	t.PrevX = t.X
+	t.PrevY = t.Y
+}
+
+// GetInterpolatedPosition implements TransformComponent interface
+func (t *Transform) GetInterpolatedPosition(alpha float64) (float64, float64) {
+	return t.PrevX + (t.X-t.PrevX)*alpha, t.PrevY + (t.Y-t.PrevY)*alpha
+}
+
 // GetPosition returns the current position
 func (t *Transform) GetPosition() (float64, float64) {
 	return t.X, t.Y
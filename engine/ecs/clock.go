@@ -0,0 +1,130 @@
+package ecs
+
+import "time"
+
+// DefaultStep is the default fixed simulation step: 60 updates per second
+const DefaultStep = 1.0 / 60.0
+
+// DefaultMaxSteps is the default limit of catch-up steps run in one tick
+const DefaultMaxSteps = 5
+
+// stepEpsilon absorbs rounding so that exactly one step of elapsed time,
+// measured in whole nanoseconds, still counts as a full step
+const stepEpsilon = 1e-6
+
+// Clock is an accumulator-based fixed-timestep clock. Each tick adds the real
+// time elapsed since the previous tick and reports how many fixed steps the
+// simulation should run; the leftover fraction of a step is exposed as an
+// interpolation alpha for rendering.
+type Clock struct {
+	step        float64
+	maxSteps    int
+	accumulator float64
+	now         func() time.Time
+	last        time.Time
+	started     bool
+	steps       uint64
+	dropped     float64
+}
+
+// NewClock creates a clock with the given step in seconds
+func NewClock(step float64) *Clock {
+	c := &Clock{
+		maxSteps: DefaultMaxSteps,
+		now:      time.Now,
+	}
+	c.SetStep(step)
+	return c
+}
+
+// SetStep changes the fixed step in seconds. Non-positive values select DefaultStep.
+func (c *Clock) SetStep(step float64) {
+	if step <= 0 {
+		step = DefaultStep
+	}
+	c.step = step
+}
+
+// SetMaxSteps limits how many steps a single tick may run. Time beyond the
+// limit is dropped rather than carried over, so a slow frame cannot snowball
+// into ever longer catch-up frames.
+func (c *Clock) SetMaxSteps(maxSteps int) {
+	if maxSteps < 1 {
+		maxSteps = 1
+	}
+	c.maxSteps = maxSteps
+}
+
+// SetTimeSource replaces the wall clock, for tests and replays
+func (c *Clock) SetTimeSource(now func() time.Time) {
+	c.now = now
+	c.started = false
+}
+
+// Step returns the fixed step in seconds
+func (c *Clock) Step() float64 {
+	return c.step
+}
+
+// Tick measures the real time since the previous tick and returns the number
+// of fixed steps to run. The first tick always runs exactly one step.
+func (c *Clock) Tick() int {
+	now := c.now()
+	elapsed := c.step
+	if c.started {
+		elapsed = now.Sub(c.last).Seconds()
+	}
+	c.last = now
+	c.started = true
+	return c.Advance(elapsed)
+}
+
+// Advance adds elapsed seconds to the clock and returns the number of fixed
+// steps to run
+func (c *Clock) Advance(elapsed float64) int {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	c.accumulator += elapsed
+
+	due := int((c.accumulator + stepEpsilon*c.step) / c.step)
+	c.accumulator = max(c.accumulator-float64(due)*c.step, 0)
+
+	steps := due
+	if steps > c.maxSteps {
+		c.dropped += float64(steps-c.maxSteps) * c.step
+		steps = c.maxSteps
+	}
+	c.steps += uint64(steps)
+	return steps
+}
+
+// Alpha returns how far the present lies between the last completed step and
+// the next one, in [0, 1]. Renderers blend previous and current state with it.
+func (c *Clock) Alpha() float64 {
+	pending := c.accumulator
+	if c.started {
+		pending += c.now().Sub(c.last).Seconds()
+	}
+	alpha := pending / c.step
+	if alpha > 1 {
+		return 1
+	}
+	return alpha
+}
+
+// Steps returns the total number of steps run
+func (c *Clock) Steps() uint64 {
+	return c.steps
+}
+
+// Dropped returns the simulation time, in seconds, discarded by the catch-up limit
+func (c *Clock) Dropped() float64 {
+	return c.dropped
+}
+
+// Reset discards accumulated time, e.g. when resuming from a pause
+func (c *Clock) Reset() {
+	c.accumulator = 0
+	c.started = false
+}
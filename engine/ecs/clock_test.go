@@ -0,0 +1,121 @@
+package ecs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeTime is a manually advanced time source
+type fakeTime struct {
+	now time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	return ft.now
+}
+
+func (ft *fakeTime) Advance(seconds float64) {
+	ft.now = ft.now.Add(time.Duration(seconds * float64(time.Second)))
+}
+
+// TestClock_Advance tests that elapsed time is converted into whole steps
+func TestClock_Advance(t *testing.T) {
+	clock := NewClock(0.1)
+
+	if steps := clock.Advance(0.05); steps != 0 {
+		t.Errorf("Expected 0 steps for half a step, got %d", steps)
+	}
+	if alpha := clock.Alpha(); math.Abs(alpha-0.5) > 1e-9 {
+		t.Errorf("Expected alpha 0.5, got %f", alpha)
+	}
+	if steps := clock.Advance(0.26); steps != 3 {
+		t.Errorf("Expected 3 steps, got %d", steps)
+	}
+	if alpha := clock.Alpha(); math.Abs(alpha-0.1) > 1e-6 {
+		t.Errorf("Expected alpha 0.1, got %f", alpha)
+	}
+	if clock.Steps() != 3 {
+		t.Errorf("Expected 3 total steps, got %d", clock.Steps())
+	}
+	if steps := clock.Advance(-1); steps != 0 {
+		t.Errorf("Expected negative time to run no steps, got %d", steps)
+	}
+}
+
+// TestClock_MaxSteps tests that long frames are capped instead of spiralling
+func TestClock_MaxSteps(t *testing.T) {
+	clock := NewClock(0.1)
+	clock.SetMaxSteps(3)
+
+	if steps := clock.Advance(1.05); steps != 3 {
+		t.Errorf("Expected steps to be capped at 3, got %d", steps)
+	}
+	if math.Abs(clock.Dropped()-0.7) > 1e-6 {
+		t.Errorf("Expected 0.7s dropped, got %f", clock.Dropped())
+	}
+	if steps := clock.Advance(0.05); steps != 1 {
+		t.Errorf("Expected leftover fraction to carry over into 1 step, got %d", steps)
+	}
+}
+
+// TestClock_Tick tests ticking against a time source
+func TestClock_Tick(t *testing.T) {
+	source := &fakeTime{now: time.Unix(0, 0)}
+	clock := NewClock(DefaultStep)
+	clock.SetTimeSource(source.Now)
+
+	if steps := clock.Tick(); steps != 1 {
+		t.Errorf("Expected first tick to run 1 step, got %d", steps)
+	}
+
+	total := 0
+	for range 60 {
+		source.Advance(DefaultStep)
+		total += clock.Tick()
+	}
+	if total != 60 {
+		t.Errorf("Expected 60 steps for one second, got %d", total)
+	}
+
+	// A 144 Hz display ticks between steps
+	source.Advance(DefaultStep / 2)
+	if steps := clock.Tick(); steps != 0 {
+		t.Errorf("Expected no step for half a step, got %d", steps)
+	}
+	if alpha := clock.Alpha(); math.Abs(alpha-0.5) > 1e-3 {
+		t.Errorf("Expected alpha near 0.5, got %f", alpha)
+	}
+
+	// Alpha keeps growing between ticks but never passes 1
+	source.Advance(DefaultStep * 10)
+	if alpha := clock.Alpha(); alpha != 1 {
+		t.Errorf("Expected alpha to clamp at 1, got %f", alpha)
+	}
+}
+
+// TestClock_Reset tests that a reset discards accumulated time
+func TestClock_Reset(t *testing.T) {
+	source := &fakeTime{now: time.Unix(0, 0)}
+	clock := NewClock(DefaultStep)
+	clock.SetTimeSource(source.Now)
+	clock.Tick()
+
+	source.Advance(10)
+	clock.Reset()
+	if steps := clock.Tick(); steps != 1 {
+		t.Errorf("Expected a single step after reset, got %d", steps)
+	}
+}
+
+// TestClock_Defaults tests invalid configuration falls back to defaults
+func TestClock_Defaults(t *testing.T) {
+	clock := NewClock(0)
+	if clock.Step() != DefaultStep {
+		t.Errorf("Expected default step, got %f", clock.Step())
+	}
+	clock.SetMaxSteps(0)
+	if steps := clock.Advance(1); steps != 1 {
+		t.Errorf("Expected at least one step per tick, got %d", steps)
+	}
+}
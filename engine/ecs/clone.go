@@ -0,0 +1,67 @@
+package ecs
+
+import (
+	"fmt"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+)
+
+// Clone returns an independent deep copy of the world for lookahead
+// simulations. Entities keep their IDs and handles, so that references
+// between them resolve in the clone, and the random streams and clock
+// continue from where the world's are. Every component must implement
+// components.Cloneable.
+//
+// The clone shares only the prefab library. It starts with its own empty
+// event dispatcher and no systems or views; NewSimulation gives it systems
+// to step it with.
+func (w *World) Clone() (*World, error) {
+	clock := *w.Clock
+	clone := newWorld(w.RNG.Clone(), &clock, w.Prefabs)
+	clone.nextEntityID = w.nextEntityID
+	clone.freeSlots = append(clone.freeSlots, w.freeSlots...)
+	clone.slots = make([]entitySlot, len(w.slots))
+	for i, slot := range w.slots {
+		clone.slots[i] = entitySlot{generation: slot.generation, reserved: slot.reserved}
+	}
+
+	w.pool.mu.Lock()
+	for componentType, hook := range w.pool.hooks {
+		clone.pool.hooks[componentType] = hook
+	}
+	pooled := make(map[*entities.Entity]bool, len(w.pool.pooled))
+	for entity := range w.pool.pooled {
+		pooled[entity] = true
+	}
+	w.pool.mu.Unlock()
+
+	copies := make([]*entities.Entity, 0, len(w.Entities))
+	for _, entity := range w.Entities {
+		if entity == nil {
+			copies = append(copies, nil)
+			continue
+		}
+		copied := entities.NewEntity(entity.ID)
+		copied.SetHandle(entity.Handle())
+		for _, name := range entity.GetComponentNames() {
+			cloneable, ok := entity.GetComponent(name).(components.Cloneable)
+			if !ok {
+				return nil, fmt.Errorf("entity %d: component %q cannot be cloned", entity.ID, name)
+			}
+			copied.AddComponent(cloneable.Clone())
+		}
+		copied.SetActive(entity.IsActive())
+		if handle := entity.Handle(); handle.IsValid() && int(handle.Index) < len(w.slots) && w.slots[handle.Index].entity == entity {
+			clone.slots[handle.Index].entity = copied
+		}
+		if pooled[entity] {
+			clone.pool.pooled[copied] = struct{}{}
+		}
+		copies = append(copies, copied)
+	}
+
+	for _, copied := range copies {
+		clone.AddEntity(copied)
+	}
+	return clone, nil
+}
@@ -0,0 +1,168 @@
+package ecs
+
+import (
+	"errors"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/random"
+	"lbbaspack/engine/systems"
+	"math"
+	"testing"
+)
+
+// uncloneable is a component without a Clone method
+type uncloneable struct{}
+
+func (u *uncloneable) GetType() string { return "Uncloneable" }
+
+// TestWorld_CloneIsIndependent tests that a clone copies entities without sharing state
+func TestWorld_CloneIsIndependent(t *testing.T) {
+	original := newSnapshotTestWorld()
+	packet := original.Entities[0]
+	original.Clock.accumulator = 0.01
+
+	clone, err := original.Clone()
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if len(clone.Entities) != len(original.Entities) {
+		t.Fatalf("Expected %d entities, got %d", len(original.Entities), len(clone.Entities))
+	}
+
+	copied := clone.Entities[0]
+	if copied == packet || copied.ID != packet.ID || copied.Handle() != packet.Handle() {
+		t.Fatalf("Expected a new entity with the same ID and handle, got %d %s", copied.ID, copied.Handle())
+	}
+	if clone.Entities[2].IsActive() {
+		t.Error("Expected the inactive entity to stay inactive")
+	}
+
+	transform, _ := Get[*components.Transform](clone, packet.ID)
+	transform.SetPosition(99, 99)
+	if original, _ := Get[*components.Transform](original, packet.ID); original.X != 10 {
+		t.Errorf("Expected the original transform to be unaffected, got %+v", original)
+	}
+
+	// References between entities resolve to the clone's entities
+	routing, _ := Get[*components.Routing](clone, packet.ID)
+	target, ok := clone.Resolve(routing.TargetBackend)
+	if !ok || target != clone.Entities[2] {
+		t.Error("Expected the routing target to resolve in the clone")
+	}
+
+	// New entities take the same IDs and handles in both worlds
+	if a, b := original.NewEntity(), clone.NewEntity(); a.ID != b.ID || a.Handle() != b.Handle() {
+		t.Errorf("Expected matching new entities, got %d %s and %d %s", a.ID, a.Handle(), b.ID, b.Handle())
+	}
+	if clone.Clock.Alpha() != original.Clock.Alpha() {
+		t.Error("Expected the clock to be copied")
+	}
+	if clone.EventDispatcher == original.EventDispatcher {
+		t.Error("Expected the clone to have its own event dispatcher")
+	}
+}
+
+// TestWorld_CloneContinuesRandomStreams tests that the clone draws the same numbers as the world
+func TestWorld_CloneContinuesRandomStreams(t *testing.T) {
+	world := NewWorldWithSeed(5)
+	world.RNG.Stream(random.StreamSpawn).Int63()
+
+	clone, err := world.Clone()
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if clone.RNG == world.RNG {
+		t.Fatal("Expected the clone to have its own random streams")
+	}
+	if clone.RNG.Stream(random.StreamSpawn).Int63() != world.RNG.Stream(random.StreamSpawn).Int63() {
+		t.Error("Expected the clone to continue the spawn stream")
+	}
+}
+
+// TestWorld_ClonePooledEntities tests that pooled entities of the clone return to the clone's pool
+func TestWorld_ClonePooledEntities(t *testing.T) {
+	world := NewWorld()
+	packet := world.AcquireEntity()
+	packet.AddComponent(components.NewPhysics())
+
+	clone, err := world.Clone()
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	clone.ClearAllEntities()
+
+	if stats := clone.PoolStats(); stats.Entities.Released != 1 || stats.Components["Physics"].Released != 1 {
+		t.Errorf("Expected the cloned packet to be recycled, got %+v", stats)
+	}
+	if world.PoolStats().Entities.Released != 0 || !packet.HasComponent("Physics") {
+		t.Error("Expected the original packet to be untouched")
+	}
+}
+
+// TestWorld_CloneRequiresCloneableComponents tests that every component type can be cloned
+func TestWorld_CloneRequiresCloneableComponents(t *testing.T) {
+	for _, name := range components.RegisteredTypes() {
+		component, _ := components.New(name)
+		if _, ok := component.(components.Cloneable); !ok {
+			t.Errorf("Expected component %q to be cloneable", name)
+		}
+	}
+
+	world := NewWorld()
+	world.NewEntity().AddComponent(&uncloneable{})
+	if _, err := world.Clone(); err == nil {
+		t.Error("Expected an error for a component that cannot be cloned")
+	}
+}
+
+// TestSimulation_Step tests that a simulation steps a copy of the world with the state of its systems
+func TestSimulation_Step(t *testing.T) {
+	newManager := func(world *World) (*systems.SystemManager, error) {
+		manager := systems.NewSystemManager()
+		info := systems.NewMovementSystem().GetSystemInfo()
+		// Nothing spawns, so the movement system does without a spawner
+		info.Requires = nil
+		if err := manager.RegisterSystem(info); err != nil {
+			return nil, err
+		}
+		if err := manager.BuildExecutionOrder(); err != nil {
+			return nil, err
+		}
+		manager.AttachTables(world)
+		return manager, nil
+	}
+	world := NewWorldWithSeed(3)
+	manager, err := newManager(world)
+	if err != nil {
+		t.Fatalf("Failed to create systems: %v", err)
+	}
+	manager.SetExecutionMode(systems.ExecutionDeterministic)
+	packet := world.NewEntity()
+	packet.AddComponent(components.NewTransform(0, 0))
+	physics := components.NewPhysics()
+	physics.SetVelocity(0, 60)
+	packet.AddComponent(physics)
+
+	sim, err := NewSimulation(world, manager, newManager)
+	if err != nil {
+		t.Fatalf("NewSimulation failed: %v", err)
+	}
+	if sim.Systems.GetExecutionMode() != systems.ExecutionDeterministic {
+		t.Error("Expected the simulation to run its systems like the world's")
+	}
+	for range 30 {
+		sim.Step(1.0 / 60)
+	}
+
+	if packet.GetTransform().GetY() != 0 {
+		t.Errorf("Expected the world untouched, got the packet at %v", packet.GetTransform().GetY())
+	}
+	simulated, ok := sim.World.Resolve(packet.Handle())
+	if !ok || math.Abs(simulated.GetTransform().GetY()-30) > 1e-9 {
+		t.Errorf("Expected the simulated packet half a second further, got %v", simulated.GetTransform().GetY())
+	}
+
+	failing := func(*World) (*systems.SystemManager, error) { return nil, errors.New("no systems") }
+	if _, err := NewSimulation(world, manager, failing); err == nil {
+		t.Error("Expected an error when the systems cannot be created")
+	}
+}
@@ -0,0 +1,133 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/systems"
+	"sync"
+)
+
+// commandKind identifies a deferred structural change
+type commandKind int
+
+const (
+	commandSpawn commandKind = iota
+	commandDestroy
+	commandAddComponent
+	commandRemoveComponent
+)
+
+// command is a single recorded structural change
+type command struct {
+	kind          commandKind
+	entity        systems.Entity
+	component     components.Component
+	componentType string
+	build         func(systems.Entity)
+}
+
+// CommandBuffer records entity creation, destruction and component changes
+// and applies them to the world in recording order when flushed. It
+// implements systems.CommandBuffer and is safe to record into concurrently.
+type CommandBuffer struct {
+	world    *World
+	mu       sync.Mutex
+	commands []command
+	spare    []command
+}
+
+// NewCommandBuffer creates a command buffer that applies to the world
+func NewCommandBuffer(world *World) *CommandBuffer {
+	return &CommandBuffer{
+		world:    world,
+		commands: make([]command, 0),
+	}
+}
+
+// record appends a command
+func (cb *CommandBuffer) record(c command) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.commands = append(cb.commands, c)
+}
+
+// Spawn records the creation of an entity; build receives the new entity,
+// taken from the world's pool, at flush time and adds its components
+func (cb *CommandBuffer) Spawn(build func(entity systems.Entity)) {
+	cb.record(command{kind: commandSpawn, build: build})
+}
+
+// Destroy records the deactivation and removal of an entity
+func (cb *CommandBuffer) Destroy(entity systems.Entity) {
+	cb.record(command{kind: commandDestroy, entity: entity})
+}
+
+// AddComponent records adding a component to an entity
+func (cb *CommandBuffer) AddComponent(entity systems.Entity, component components.Component) {
+	cb.record(command{kind: commandAddComponent, entity: entity, component: component})
+}
+
+// RemoveComponent records removing a component from an entity
+func (cb *CommandBuffer) RemoveComponent(entity systems.Entity, componentType string) {
+	cb.record(command{kind: commandRemoveComponent, entity: entity, componentType: componentType})
+}
+
+// Len returns the number of commands waiting to be flushed
+func (cb *CommandBuffer) Len() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return len(cb.commands)
+}
+
+// Flush applies every recorded command in order and returns the world's
+// entity list. Commands recorded while flushing, e.g. by a spawn's build
+// function, are applied in the same flush.
+func (cb *CommandBuffer) Flush() []systems.Entity {
+	destroyed := make(map[uint64]struct{})
+	for {
+		cb.mu.Lock()
+		pending := cb.commands
+		cb.commands = cb.spare[:0]
+		cb.mu.Unlock()
+		if len(pending) == 0 {
+			break
+		}
+
+		for i := range pending {
+			cb.apply(&pending[i], destroyed)
+			pending[i] = command{}
+		}
+
+		cb.mu.Lock()
+		cb.spare = pending
+		cb.mu.Unlock()
+	}
+
+	if len(destroyed) > 0 {
+		cb.world.removeWhere(func(entity *entities.Entity) bool {
+			_, gone := destroyed[entity.ID]
+			return gone
+		})
+	}
+	return cb.world.EntityList()
+}
+
+// apply performs a single command
+func (cb *CommandBuffer) apply(c *command, destroyed map[uint64]struct{}) {
+	switch c.kind {
+	case commandSpawn:
+		entity := cb.world.AcquireEntity()
+		if c.build != nil {
+			c.build(entity)
+		}
+	case commandDestroy:
+		if active, ok := c.entity.(interface{ SetActive(bool) }); ok {
+			active.SetActive(false)
+		}
+		destroyed[c.entity.GetID()] = struct{}{}
+	case commandAddComponent:
+		c.entity.AddComponent(c.component)
+	case commandRemoveComponent:
+		c.entity.RemoveComponent(c.componentType)
+	}
+}
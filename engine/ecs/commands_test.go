@@ -0,0 +1,116 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/systems"
+	"sync"
+	"testing"
+)
+
+// TestCommandBuffer_DefersChanges tests that recorded commands only apply on flush
+func TestCommandBuffer_DefersChanges(t *testing.T) {
+	world := NewWorld()
+	packet := world.NewEntity()
+	packet.AddComponent(components.NewTransform(0, 0))
+	doomed := world.NewEntity()
+	routed := world.NewEntity()
+	routed.AddComponent(components.NewRouting(EntityHandle{Index: 1, Generation: 1}, 100))
+
+	commands := NewCommandBuffer(world)
+	commands.Spawn(func(entity systems.Entity) {
+		entity.AddComponent(components.NewPacketType("HTTP", 10))
+	})
+	commands.Destroy(doomed)
+	commands.AddComponent(packet, components.NewRouting(EntityHandle{Index: 2, Generation: 1}, 150))
+	commands.RemoveComponent(routed, "Routing")
+
+	if commands.Len() != 4 {
+		t.Errorf("Expected 4 recorded commands, got %d", commands.Len())
+	}
+	if len(world.Entities) != 3 || !doomed.IsActive() || packet.HasComponent("Routing") || !routed.HasComponent("Routing") {
+		t.Fatal("Expected no changes before flush")
+	}
+
+	entityList := commands.Flush()
+
+	if commands.Len() != 0 {
+		t.Errorf("Expected buffer to be empty after flush, got %d", commands.Len())
+	}
+	if len(world.Entities) != 3 || len(entityList) != 3 {
+		t.Errorf("Expected 3 entities after spawning one and destroying one, got %d (list %d)", len(world.Entities), len(entityList))
+	}
+	if doomed.IsActive() {
+		t.Error("Expected destroyed entity to be deactivated")
+	}
+	for _, entity := range world.Entities {
+		if entity == doomed {
+			t.Error("Expected destroyed entity to be removed from the world")
+		}
+	}
+	if !packet.HasComponent("Routing") {
+		t.Error("Expected Routing to be added on flush")
+	}
+	if routed.HasComponent("Routing") {
+		t.Error("Expected Routing to be removed on flush")
+	}
+	if Count[*components.PacketType](world) != 1 {
+		t.Error("Expected spawned entity to be built on flush")
+	}
+}
+
+// TestCommandBuffer_PreservesOrder tests that commands apply in recording order
+func TestCommandBuffer_PreservesOrder(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	commands := NewCommandBuffer(world)
+
+	commands.AddComponent(entity, components.NewRouting(EntityHandle{Index: 1, Generation: 1}, 100))
+	commands.RemoveComponent(entity, "Routing")
+	commands.AddComponent(entity, components.NewRouting(EntityHandle{Index: 3, Generation: 1}, 100))
+	commands.Flush()
+
+	routing, ok := Get[*components.Routing](world, entity.ID)
+	if !ok || routing.TargetBackend.Index != 3 {
+		t.Errorf("Expected the last add to win, got %+v", routing)
+	}
+}
+
+// TestCommandBuffer_NestedCommands tests that commands recorded while flushing are applied too
+func TestCommandBuffer_NestedCommands(t *testing.T) {
+	world := NewWorld()
+	commands := NewCommandBuffer(world)
+
+	commands.Spawn(func(parent systems.Entity) {
+		commands.Spawn(func(child systems.Entity) {
+			child.AddComponent(components.NewTransform(1, 1))
+		})
+	})
+	commands.Flush()
+
+	if len(world.Entities) != 2 || Count[*components.Transform](world) != 1 {
+		t.Errorf("Expected parent and child to be spawned, got %d entities", len(world.Entities))
+	}
+}
+
+// TestCommandBuffer_ConcurrentRecording tests that commands may be recorded from several goroutines
+func TestCommandBuffer_ConcurrentRecording(t *testing.T) {
+	world := NewWorld()
+	commands := NewCommandBuffer(world)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				commands.Spawn(nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	commands.Flush()
+	if len(world.Entities) != 200 {
+		t.Errorf("Expected 200 spawned entities, got %d", len(world.Entities))
+	}
+}
@@ -0,0 +1,112 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/systems"
+)
+
+// EntityHandle is a generational reference to an entity in a world. Store
+// handles rather than entity pointers wherever one entity refers to another.
+type EntityHandle = components.EntityHandle
+
+// entitySlot is one row of the world's handle table
+type entitySlot struct {
+	generation uint32
+	entity     *entities.Entity
+	reserved   bool // held for an entity being restored from a snapshot
+}
+
+// Resolve returns the entity a handle refers to, or false if the entity has
+// been removed from the world
+func (w *World) Resolve(handle EntityHandle) (*entities.Entity, bool) {
+	if !handle.IsValid() || int(handle.Index) >= len(w.slots) {
+		return nil, false
+	}
+	slot := w.slots[handle.Index]
+	if slot.generation != handle.Generation || slot.entity == nil {
+		return nil, false
+	}
+	return slot.entity, true
+}
+
+// ResolveEntity is Resolve for systems. It implements systems.EntityResolver.
+func (w *World) ResolveEntity(handle EntityHandle) (systems.Entity, bool) {
+	entity, ok := w.Resolve(handle)
+	if !ok {
+		return nil, false
+	}
+	return entity, true
+}
+
+// claimHandle issues a handle for an entity joining the world, reusing a free
+// slot under a new generation. Entities restored from a snapshot take back the
+// slot reserved for them.
+func (w *World) claimHandle(entity *entities.Entity) {
+	handle := entity.Handle()
+	if handle.IsValid() && int(handle.Index) < len(w.slots) {
+		slot := &w.slots[handle.Index]
+		if slot.generation == handle.Generation && (slot.entity == entity || slot.reserved) {
+			slot.entity = entity
+			slot.reserved = false
+			return
+		}
+	}
+
+	var index uint32
+	if n := len(w.freeSlots); n > 0 {
+		index = w.freeSlots[n-1]
+		w.freeSlots = w.freeSlots[:n-1]
+	} else {
+		index = uint32(len(w.slots))
+		w.slots = append(w.slots, entitySlot{})
+	}
+	slot := &w.slots[index]
+	slot.generation++
+	if slot.generation == 0 {
+		slot.generation = 1 // skip the zero handle on wraparound
+	}
+	slot.entity = entity
+	entity.SetHandle(EntityHandle{Index: index, Generation: slot.generation})
+}
+
+// releaseHandle frees an entity's slot; its handle is stale from then on
+func (w *World) releaseHandle(entity *entities.Entity) {
+	handle := entity.Handle()
+	if !handle.IsValid() || int(handle.Index) >= len(w.slots) {
+		return
+	}
+	slot := &w.slots[handle.Index]
+	if slot.entity != entity || slot.generation != handle.Generation {
+		return
+	}
+	slot.entity = nil
+	w.freeSlots = append(w.freeSlots, handle.Index)
+}
+
+// slotGenerations returns the current generation of every slot
+func (w *World) slotGenerations() []uint32 {
+	generations := make([]uint32, len(w.slots))
+	for i, slot := range w.slots {
+		generations[i] = slot.generation
+	}
+	return generations
+}
+
+// resetSlots rebuilds an empty handle table with the given generations and
+// reserves the slots named by handles
+func (w *World) resetSlots(generations []uint32, handles []EntityHandle) {
+	w.slots = make([]entitySlot, len(generations))
+	for i, generation := range generations {
+		w.slots[i].generation = generation
+	}
+	for _, handle := range handles {
+		w.slots[handle.Index].reserved = true
+	}
+	w.freeSlots = make([]uint32, 0, len(generations))
+	for i := len(w.slots) - 1; i >= 0; i-- {
+		if !w.slots[i].reserved {
+			w.freeSlots = append(w.freeSlots, uint32(i))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ecs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestWorld_ResolveHandles tests that handles resolve until their entity is removed
+func TestWorld_ResolveHandles(t *testing.T) {
+	world := NewWorld()
+	first := world.NewEntity()
+	second := world.NewEntity()
+
+	if !first.Handle().IsValid() || first.Handle() == second.Handle() {
+		t.Fatalf("Expected distinct valid handles, got %s and %s", first.Handle(), second.Handle())
+	}
+	if entity, ok := world.Resolve(first.Handle()); !ok || entity != first {
+		t.Errorf("Expected handle %s to resolve to entity %d", first.Handle(), first.ID)
+	}
+	if _, ok := world.Resolve(EntityHandle{}); ok {
+		t.Error("Expected the zero handle not to resolve")
+	}
+	if _, ok := world.Resolve(EntityHandle{Index: 99, Generation: 1}); ok {
+		t.Error("Expected an out of range handle not to resolve")
+	}
+
+	stale := first.Handle()
+	world.RemoveEntity(first)
+	if _, ok := world.Resolve(stale); ok {
+		t.Error("Expected the handle of a removed entity to be stale")
+	}
+	if entity, ok := world.ResolveEntity(second.Handle()); !ok || entity.GetID() != second.ID {
+		t.Error("Expected the remaining entity to still resolve")
+	}
+}
+
+// TestWorld_HandleSlotReuse tests that a reused slot gets a new generation
+func TestWorld_HandleSlotReuse(t *testing.T) {
+	world := NewWorld()
+	removed := world.NewEntity()
+	stale := removed.Handle()
+	world.RemoveEntity(removed)
+
+	reused := world.NewEntity()
+	if reused.Handle().Index != stale.Index {
+		t.Fatalf("Expected slot %d to be reused, got %s", stale.Index, reused.Handle())
+	}
+	if reused.Handle().Generation == stale.Generation {
+		t.Errorf("Expected a new generation for the reused slot, got %s", reused.Handle())
+	}
+	if entity, ok := world.Resolve(stale); ok {
+		t.Errorf("Expected the stale handle not to resolve to entity %d", entity.ID)
+	}
+
+	// Re-adding a removed entity issues it a fresh handle
+	world.AddEntity(removed)
+	if entity, ok := world.Resolve(removed.Handle()); !ok || entity != removed {
+		t.Error("Expected a re-added entity to resolve through its new handle")
+	}
+	if removed.Handle() == stale {
+		t.Error("Expected a re-added entity to get a new handle")
+	}
+}
+
+// TestWorld_RestoreRejectsBadHandles tests that snapshots with inconsistent handles are rejected
+func TestWorld_RestoreRejectsBadHandles(t *testing.T) {
+	entity := func(id uint64, handle EntityHandle) EntitySnapshot {
+		return EntitySnapshot{
+			ID:         id,
+			Handle:     handle,
+			Active:     true,
+			Components: []ComponentSnapshot{{Type: "Transform", Data: json.RawMessage(`{"X":1,"Y":2}`)}},
+		}
+	}
+	snapshots := map[string]*Snapshot{
+		"wrong generation": {
+			Version:     SnapshotVersion,
+			Generations: []uint32{1},
+			Entities:    []EntitySnapshot{entity(1, EntityHandle{Index: 0, Generation: 2})},
+		},
+		"out of range": {
+			Version:     SnapshotVersion,
+			Generations: []uint32{1},
+			Entities:    []EntitySnapshot{entity(1, EntityHandle{Index: 1, Generation: 1})},
+		},
+		"duplicate": {
+			Version:     SnapshotVersion,
+			Generations: []uint32{1},
+			Entities: []EntitySnapshot{
+				entity(1, EntityHandle{Index: 0, Generation: 1}),
+				entity(2, EntityHandle{Index: 0, Generation: 1}),
+			},
+		},
+	}
+
+	for name, snapshot := range snapshots {
+		t.Run(name, func(t *testing.T) {
+			world := newSnapshotTestWorld()
+			if err := world.Restore(snapshot); err == nil {
+				t.Fatal("Expected restore to fail")
+			}
+			if len(world.Entities) != 3 {
+				t.Errorf("Expected world to keep its 3 entities, got %d", len(world.Entities))
+			}
+		})
+	}
+}
+
+// TestWorld_RestoreWithoutHandles tests that version 1 snapshots are issued fresh handles
+func TestWorld_RestoreWithoutHandles(t *testing.T) {
+	world := NewWorld()
+	snapshot := &Snapshot{
+		Version: 1,
+		Entities: []EntitySnapshot{
+			{ID: 1, Active: true, Components: []ComponentSnapshot{}},
+			{ID: 2, Active: true, Components: []ComponentSnapshot{}},
+		},
+	}
+	if err := world.Restore(snapshot); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	for _, entity := range world.Entities {
+		if resolved, ok := world.Resolve(entity.Handle()); !ok || resolved != entity {
+			t.Errorf("Expected entity %d to resolve through a fresh handle", entity.ID)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ecs
+
+import (
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+)
+
+// observed reports whether anything receives lifecycle events of eventType,
+// so that they are only built when they are
+func (w *World) observed(eventType events.EventType) bool {
+	return w.EventDispatcher != nil && w.EventDispatcher.HasSubscribers(eventType)
+}
+
+// publishEntityCreated publishes EventEntityCreated on the world's dispatcher
+func (w *World) publishEntityCreated(entity *entities.Entity) {
+	if !w.observed(events.EventEntityCreated) {
+		return
+	}
+	events.PublishImmediate(w.EventDispatcher, events.EntityCreated{Entity: entity.Handle(), EntityID: entity.ID})
+}
+
+// publishEntityDestroyed publishes EventEntityDestroyed on the world's dispatcher.
+// Lifecycle events bypass the queue, since the entity is recycled right after.
+func (w *World) publishEntityDestroyed(entity *entities.Entity) {
+	if !w.observed(events.EventEntityDestroyed) {
+		return
+	}
+	events.PublishImmediate(w.EventDispatcher, events.EntityDestroyed{Entity: entity.Handle(), EntityID: entity.ID})
+}
+
+// publishComponentEvent publishes EventComponentAdded or EventComponentRemoved
+// for a change made through the world's storage
+func (w *World) publishComponentEvent(id uint64, componentType string, added bool) {
+	eventType := events.EventComponentRemoved
+	if added {
+		eventType = events.EventComponentAdded
+	}
+	if !w.observed(eventType) {
+		return
+	}
+	entity, exists := w.byID[id]
+	if !exists {
+		return
+	}
+	if added {
+		events.PublishImmediate(w.EventDispatcher, events.ComponentAdded{Entity: entity.Handle(), EntityID: id, Component: componentType})
+	} else {
+		events.PublishImmediate(w.EventDispatcher, events.ComponentRemoved{Entity: entity.Handle(), EntityID: id, Component: componentType})
+	}
+}
+
+// OnComponentAdded calls handler whenever a component of the given type is
+// added to an entity in the world, until the subscription is cancelled
+func (w *World) OnComponentAdded(componentType string, handler func(entity *entities.Entity)) *events.Subscription {
+	return events.Subscribe(w.EventDispatcher, func(added events.ComponentAdded) {
+		w.handleComponentEvent(added.Component, added.Entity, componentType, handler)
+	})
+}
+
+// OnComponentRemoved calls handler whenever a component of the given type is
+// removed from an entity in the world, until the subscription is cancelled.
+// Destroyed entities keep their components and are reported through
+// EventEntityDestroyed instead.
+func (w *World) OnComponentRemoved(componentType string, handler func(entity *entities.Entity)) *events.Subscription {
+	return events.Subscribe(w.EventDispatcher, func(removed events.ComponentRemoved) {
+		w.handleComponentEvent(removed.Component, removed.Entity, componentType, handler)
+	})
+}
+
+// OnEntityDestroyed calls handler whenever an entity that has a component of
+// the given type is removed from the world, until the subscription is
+// cancelled
+func (w *World) OnEntityDestroyed(componentType string, handler func(entity *entities.Entity)) *events.Subscription {
+	return events.Subscribe(w.EventDispatcher, func(destroyed events.EntityDestroyed) {
+		if entity, ok := w.Resolve(destroyed.Entity); ok && entity.HasComponent(componentType) {
+			handler(entity)
+		}
+	})
+}
+
+// handleComponentEvent calls handler for a component event about the
+// component type it subscribed to
+func (w *World) handleComponentEvent(changed string, handle EntityHandle, componentType string, handler func(entity *entities.Entity)) {
+	if changed != componentType {
+		return
+	}
+	if entity, ok := w.Resolve(handle); ok {
+		handler(entity)
+	}
+}
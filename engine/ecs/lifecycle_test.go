@@ -0,0 +1,141 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+	"testing"
+)
+
+// recordLifecycle subscribes to all lifecycle events and records them in order
+func recordLifecycle(world *World) *[]string {
+	recorded := make([]string, 0)
+	for _, eventType := range []events.EventType{
+		events.EventEntityCreated,
+		events.EventEntityDestroyed,
+		events.EventComponentAdded,
+		events.EventComponentRemoved,
+	} {
+		world.EventDispatcher.Subscribe(eventType, func(event *events.Event) {
+			entry := string(event.Type)
+			if event.Data.Component != nil {
+				entry += ":" + *event.Data.Component
+			}
+			recorded = append(recorded, entry)
+		})
+	}
+	return &recorded
+}
+
+// TestWorld_LifecycleEvents tests that entity and component changes are published in order
+func TestWorld_LifecycleEvents(t *testing.T) {
+	world := NewWorld()
+	recorded := recordLifecycle(world)
+
+	entity := world.NewEntity()
+	entity.AddComponent(components.NewTransform(1, 2))
+	entity.AddComponent(components.NewTransform(3, 4)) // replacement, not a new type
+	entity.AddComponent(components.NewPhysics())
+	entity.RemoveComponent("Physics")
+	entity.RemoveComponent("Physics") // already gone
+	entity.SetActive(false)
+	world.RemoveInactiveEntities()
+
+	expected := []string{
+		"entity_created",
+		"component_added:Transform",
+		"component_added:Physics",
+		"component_removed:Physics",
+		"entity_destroyed",
+	}
+	if len(*recorded) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, *recorded)
+	}
+	for i := range expected {
+		if (*recorded)[i] != expected[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, expected[i], (*recorded)[i])
+		}
+	}
+}
+
+// TestWorld_EntityDestroyedEventData tests that destroyed entities resolve, with their components, only while handlers run
+func TestWorld_EntityDestroyedEventData(t *testing.T) {
+	world := NewWorld()
+	packet := world.NewEntity()
+	packet.AddComponent(components.NewPacketType("HTTPS", 15))
+
+	var destroyed *entities.Entity
+	var destroyedID uint64
+	world.EventDispatcher.Subscribe(events.EventEntityDestroyed, func(event *events.Event) {
+		destroyed, _ = world.Resolve(event.Data.Entity)
+		destroyedID = *event.Data.EntityID
+		if len(world.Entities) != 0 {
+			t.Error("Expected entity to be removed from the world before the event")
+		}
+	})
+
+	world.RemoveEntity(packet)
+
+	if destroyed != packet || destroyedID != packet.ID {
+		t.Fatalf("Expected destroyed event for entity %d, got %v", packet.ID, destroyedID)
+	}
+	if !destroyed.HasComponent("PacketType") {
+		t.Error("Expected destroyed entity to keep its components")
+	}
+	if _, ok := world.Resolve(packet.Handle()); ok {
+		t.Error("Expected the destroyed entity's handle to be stale after the event")
+	}
+}
+
+// TestWorld_ComponentObservers tests the per-component-type observer hooks
+func TestWorld_ComponentObservers(t *testing.T) {
+	world := NewWorld()
+	added, removed, destroyed := 0, 0, 0
+	scope := world.EventDispatcher.NewScope()
+	scope.Add(world.OnComponentAdded("PacketType", func(entity *entities.Entity) { added++ }))
+	scope.Add(world.OnComponentRemoved("PacketType", func(entity *entities.Entity) { removed++ }))
+	scope.Add(world.OnEntityDestroyed("PacketType", func(entity *entities.Entity) { destroyed++ }))
+
+	packet := world.NewEntity()
+	packet.AddComponent(components.NewTransform(0, 0))
+	packet.AddComponent(components.NewPacketType("HTTP", 10))
+	other := world.NewEntity()
+	other.AddComponent(components.NewTransform(0, 0))
+
+	world.ClearAllEntities()
+
+	if added != 1 {
+		t.Errorf("Expected 1 PacketType added, got %d", added)
+	}
+	if removed != 0 {
+		t.Errorf("Expected destroying an entity not to report component removals, got %d", removed)
+	}
+	if destroyed != 1 {
+		t.Errorf("Expected 1 packet entity destroyed, got %d", destroyed)
+	}
+
+	scope.Close()
+	packet = world.NewEntity()
+	packet.AddComponent(components.NewPacketType("HTTP", 10))
+	world.RemoveEntity(packet)
+	if added != 1 || destroyed != 1 {
+		t.Errorf("Expected no calls once unsubscribed, got %d added and %d destroyed", added, destroyed)
+	}
+}
+
+// TestWorld_LifecycleHandlerCanSpawn tests that handlers may add entities while others are removed
+func TestWorld_LifecycleHandlerCanSpawn(t *testing.T) {
+	world := NewWorld()
+	for i := 0; i < 3; i++ {
+		world.NewEntity().SetActive(false)
+	}
+	world.EventDispatcher.Subscribe(events.EventEntityDestroyed, func(event *events.Event) {
+		world.NewEntity()
+	})
+
+	world.RemoveInactiveEntities()
+
+	if len(world.Entities) != 3 {
+		t.Errorf("Expected 3 entities spawned by handlers to remain, got %d", len(world.Entities))
+	}
+}
@@ -0,0 +1,203 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"sync"
+)
+
+// ResetHook returns a pooled component to the state its constructor gives it
+type ResetHook func(component components.Component)
+
+// PoolCounts are the allocation statistics of one pool
+type PoolCounts struct {
+	Allocated uint64 // instances created because the pool was empty
+	Reused    uint64 // acquisitions served from the pool
+	Released  uint64 // instances handed back to the pool
+	Free      int    // instances currently waiting in the pool
+}
+
+// PoolStats are the allocation statistics of a world's entity and component pools
+type PoolStats struct {
+	Entities   PoolCounts
+	Components map[string]PoolCounts // keyed by component type name
+}
+
+// componentPool holds released instances of one component type
+type componentPool struct {
+	free   []components.Component
+	reset  ResetHook
+	counts PoolCounts
+}
+
+// pool recycles entities created through AcquireEntity and the components
+// they carry, so that high-churn entities such as packets stop allocating
+type pool struct {
+	mu         sync.Mutex
+	entities   []*entities.Entity
+	pooled     map[*entities.Entity]struct{} // live entities that return to the pool
+	counts     PoolCounts
+	components map[string]*componentPool
+	hooks      map[string]ResetHook
+}
+
+func newPool() *pool {
+	return &pool{
+		entities:   make([]*entities.Entity, 0),
+		pooled:     make(map[*entities.Entity]struct{}),
+		components: make(map[string]*componentPool),
+		hooks:      make(map[string]ResetHook),
+	}
+}
+
+// resetHook returns the reset hook for a component type, falling back to
+// Resettable.Reset. Callers must hold the lock.
+func (p *pool) resetHook(componentType string, component components.Component) ResetHook {
+	if hook, exists := p.hooks[componentType]; exists {
+		return hook
+	}
+	if _, ok := component.(components.Resettable); ok {
+		return func(component components.Component) {
+			component.(components.Resettable).Reset()
+		}
+	}
+	return nil
+}
+
+// componentPool returns the pool for a component type, creating it if the
+// type can be reset. Callers must hold the lock.
+func (p *pool) componentPool(componentType string, sample components.Component) *componentPool {
+	if cp, exists := p.components[componentType]; exists {
+		return cp
+	}
+	reset := p.resetHook(componentType, sample)
+	if reset == nil {
+		return nil
+	}
+	cp := &componentPool{free: make([]components.Component, 0), reset: reset}
+	p.components[componentType] = cp
+	return cp
+}
+
+// SetResetHook registers the hook that resets released components of the
+// given type. Types without a hook are pooled only if they implement
+// components.Resettable; all others are left to the garbage collector.
+func (w *World) SetResetHook(componentType string, reset ResetHook) {
+	w.pool.mu.Lock()
+	defer w.pool.mu.Unlock()
+	w.pool.hooks[componentType] = reset
+	if cp, exists := w.pool.components[componentType]; exists {
+		cp.reset = reset
+	}
+}
+
+// AcquireComponent returns a reset component of a registered type, reusing a
+// released instance when one is available. It implements
+// systems.ComponentPool.
+func (w *World) AcquireComponent(componentType string) (components.Component, bool) {
+	w.pool.mu.Lock()
+	defer w.pool.mu.Unlock()
+	if cp, exists := w.pool.components[componentType]; exists {
+		if n := len(cp.free); n > 0 {
+			component := cp.free[n-1]
+			cp.free[n-1] = nil
+			cp.free = cp.free[:n-1]
+			cp.counts.Reused++
+			return component, true
+		}
+	}
+
+	component, ok := components.New(componentType)
+	if !ok {
+		return nil, false
+	}
+	if cp := w.pool.componentPool(componentType, component); cp != nil {
+		cp.reset(component)
+		cp.counts.Allocated++
+	}
+	return component, true
+}
+
+// ReleaseComponent resets a component and keeps it for reuse. The caller must
+// not use the component afterwards. Components whose type cannot be reset
+// are dropped.
+func (w *World) ReleaseComponent(component components.Component) {
+	if component == nil {
+		return
+	}
+	w.pool.mu.Lock()
+	defer w.pool.mu.Unlock()
+	w.pool.release(component)
+}
+
+// release resets and stores a component. Callers must hold the lock.
+func (p *pool) release(component components.Component) {
+	cp := p.componentPool(component.GetType(), component)
+	if cp == nil {
+		return
+	}
+	cp.reset(component)
+	cp.free = append(cp.free, component)
+	cp.counts.Released++
+}
+
+// AcquireEntity adds an empty entity to the world like NewEntity, reusing a
+// recycled one when available. Entities acquired this way return to the pool
+// together with their components when they are removed from the world, so
+// they must only be referred to by handle once removed.
+func (w *World) AcquireEntity() *entities.Entity {
+	w.pool.mu.Lock()
+	var entity *entities.Entity
+	if n := len(w.pool.entities); n > 0 {
+		entity = w.pool.entities[n-1]
+		w.pool.entities[n-1] = nil
+		w.pool.entities = w.pool.entities[:n-1]
+		entity.Reset(w.nextEntityID)
+		w.pool.counts.Reused++
+	} else {
+		entity = entities.NewEntity(w.nextEntityID)
+		w.pool.counts.Allocated++
+	}
+	w.pool.pooled[entity] = struct{}{}
+	w.pool.mu.Unlock()
+
+	w.nextEntityID++
+	w.AddEntity(entity)
+	return entity
+}
+
+// recycle returns a removed entity and its components to the pool if it was
+// acquired from it. The entity is left inactive and empty, since systems may
+// still hold it in an entity list taken before it was removed.
+func (w *World) recycle(entity *entities.Entity) {
+	w.pool.mu.Lock()
+	defer w.pool.mu.Unlock()
+	if _, pooled := w.pool.pooled[entity]; !pooled {
+		return
+	}
+	delete(w.pool.pooled, entity)
+	for _, component := range entity.Components {
+		w.pool.release(component)
+	}
+	entity.Reset(0)
+	entity.SetActive(false)
+	w.pool.entities = append(w.pool.entities, entity)
+	w.pool.counts.Released++
+}
+
+// PoolStats returns the allocation statistics of the world's pools
+func (w *World) PoolStats() PoolStats {
+	w.pool.mu.Lock()
+	defer w.pool.mu.Unlock()
+	stats := PoolStats{
+		Entities:   w.pool.counts,
+		Components: make(map[string]PoolCounts, len(w.pool.components)),
+	}
+	stats.Entities.Free = len(w.pool.entities)
+	for componentType, cp := range w.pool.components {
+		counts := cp.counts
+		counts.Free = len(cp.free)
+		stats.Components[componentType] = counts
+	}
+	return stats
+}
@@ -0,0 +1,146 @@
+package ecs
+
+import (
+	"image/color"
+	"lbbaspack/engine/components"
+	"testing"
+)
+
+// TestWorld_AcquireEntityRecycles tests that removed pooled entities are reused
+func TestWorld_AcquireEntityRecycles(t *testing.T) {
+	world := NewWorld()
+	packet := world.AcquireEntity()
+	packet.AddComponent(components.NewTransform(10, 20))
+	stale := packet.Handle()
+
+	packet.SetActive(false)
+	world.RemoveInactiveEntities()
+
+	if packet.IsActive() || len(packet.GetComponentNames()) != 0 {
+		t.Errorf("Expected a recycled entity to be inactive and empty, got %v", packet.GetComponentNames())
+	}
+
+	reused := world.AcquireEntity()
+	if reused != packet {
+		t.Fatal("Expected the released entity to be reused")
+	}
+	if !reused.IsActive() || reused.HasComponent("Transform") {
+		t.Error("Expected the reused entity to be active and empty")
+	}
+	if reused.ID == 1 || reused.Handle() == stale {
+		t.Errorf("Expected a new ID and handle, got %d and %s", reused.ID, reused.Handle())
+	}
+	if _, ok := world.Resolve(stale); ok {
+		t.Error("Expected the handle of the recycled entity to be stale")
+	}
+
+	stats := world.PoolStats()
+	if stats.Entities.Allocated != 1 || stats.Entities.Reused != 1 || stats.Entities.Released != 1 || stats.Entities.Free != 0 {
+		t.Errorf("Unexpected entity pool stats %+v", stats.Entities)
+	}
+}
+
+// TestWorld_NewEntityNotPooled tests that entities created with NewEntity stay usable after removal
+func TestWorld_NewEntityNotPooled(t *testing.T) {
+	world := NewWorld()
+	backend := world.NewEntity()
+	backend.AddComponent(components.NewTransform(1, 2))
+	world.RemoveEntity(backend)
+
+	if !backend.HasComponent("Transform") {
+		t.Error("Expected a removed unpooled entity to keep its components")
+	}
+	if world.AcquireEntity() == backend {
+		t.Error("Expected an unpooled entity not to be reused")
+	}
+}
+
+// TestWorld_AcquireComponentResets tests that released components come back reset
+func TestWorld_AcquireComponentResets(t *testing.T) {
+	world := NewWorld()
+	sprite := components.NewSprite(15, 15, color.RGBA{255, 0, 0, 255})
+	sprite.SetVisible(false)
+	world.ReleaseComponent(sprite)
+
+	component, ok := world.AcquireComponent("Sprite")
+	if !ok || component != components.Component(sprite) {
+		t.Fatal("Expected the released sprite to be reused")
+	}
+	if !sprite.Visible || sprite.Width != 0 || sprite.Color != (color.RGBA{}) {
+		t.Errorf("Expected a reset sprite, got %+v", sprite)
+	}
+
+	fresh, ok := world.AcquireComponent("Transform")
+	if !ok {
+		t.Fatal("Expected a registered component type to be acquired")
+	}
+	if transform := fresh.(*components.Transform); transform.ScaleX != 1 || transform.ScaleY != 1 {
+		t.Errorf("Expected a newly allocated component to be reset too, got %+v", transform)
+	}
+	if _, ok := world.AcquireComponent("Unknown"); ok {
+		t.Error("Expected an unregistered component type not to be acquired")
+	}
+
+	stats := world.PoolStats()
+	if got := stats.Components["Sprite"]; got.Released != 1 || got.Reused != 1 || got.Allocated != 0 {
+		t.Errorf("Unexpected sprite pool stats %+v", got)
+	}
+	if got := stats.Components["Transform"]; got.Allocated != 1 {
+		t.Errorf("Unexpected transform pool stats %+v", got)
+	}
+}
+
+// TestWorld_ReleasesComponentsOfRecycledEntities tests that a recycled entity's components are pooled
+func TestWorld_ReleasesComponentsOfRecycledEntities(t *testing.T) {
+	world := NewWorld()
+	packet := world.AcquireEntity()
+	physics := components.NewPhysics()
+	physics.SetVelocity(0, 100)
+	packet.AddComponent(physics)
+	packet.AddComponent(&components.State{Current: components.StatePlaying})
+
+	world.ClearAllEntities()
+
+	component, _ := world.AcquireComponent("Physics")
+	if component != components.Component(physics) {
+		t.Fatal("Expected the recycled entity's physics to be reused")
+	}
+	if physics.VelocityY != 0 || physics.Mass != 1.0 {
+		t.Errorf("Expected reset physics, got %+v", physics)
+	}
+	if _, pooled := world.PoolStats().Components["State"]; pooled {
+		t.Error("Expected components that cannot be reset not to be pooled")
+	}
+}
+
+// TestWorld_SetResetHook tests that a registered hook replaces Resettable.Reset
+func TestWorld_SetResetHook(t *testing.T) {
+	world := NewWorld()
+	world.SetResetHook("State", func(component components.Component) {
+		component.(*components.State).Current = components.StateMenu
+	})
+
+	world.ReleaseComponent(&components.State{Current: components.StateGameOver})
+	component, _ := world.AcquireComponent("State")
+	if state := component.(*components.State); state.Current != components.StateMenu {
+		t.Errorf("Expected the hook to reset the state, got %v", state.Current)
+	}
+	if world.PoolStats().Components["State"].Reused != 1 {
+		t.Error("Expected the state to come from the pool")
+	}
+}
+
+// BenchmarkWorld_PacketChurn measures spawning and removing a packet through the pools
+func BenchmarkWorld_PacketChurn(b *testing.B) {
+	world := NewWorld()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		packet := world.AcquireEntity()
+		for _, name := range []string{"Transform", "Sprite", "Collider", "Physics", "PacketType"} {
+			component, _ := world.AcquireComponent(name)
+			packet.AddComponent(component)
+		}
+		packet.SetActive(false)
+		world.RemoveInactiveEntities()
+	}
+}
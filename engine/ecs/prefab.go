@@ -0,0 +1,25 @@
+package ecs
+
+import (
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/prefabs"
+)
+
+// Overrides replaces prefab field values, keyed by component type and field name
+type Overrides = prefabs.Overrides
+
+// Spawn adds an entity built from the named prefab in the world's library,
+// with overrides applied over the prefab's field values. Its components come
+// from the world's pool, and the entity is recycled on removal like one
+// created by AcquireEntity.
+func (w *World) Spawn(name string, overrides Overrides) (*entities.Entity, error) {
+	built, err := w.Prefabs.Instantiate(name, overrides, w)
+	if err != nil {
+		return nil, err
+	}
+	entity := w.AcquireEntity()
+	for _, component := range built {
+		entity.AddComponent(component)
+	}
+	return entity, nil
+}
@@ -0,0 +1,47 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"testing"
+)
+
+// TestWorld_Spawn tests that prefabs are spawned into the world from the pool
+func TestWorld_Spawn(t *testing.T) {
+	world := NewWorld()
+	packet, err := world.Spawn("packet.https", Overrides{"Transform": {"X": 42}})
+	if err != nil {
+		t.Fatalf("Failed to spawn packet: %v", err)
+	}
+
+	transform, ok := Get[*components.Transform](world, packet.ID)
+	if !ok || transform.X != 42 || transform.Y != -15 {
+		t.Errorf("Expected the packet at (42, -15), got %+v", transform)
+	}
+	if packet.GetPacketType().GetName() != "HTTPS" {
+		t.Errorf("Expected an HTTPS packet, got %s", packet.GetPacketType().GetName())
+	}
+	if entity, ok := world.Resolve(packet.Handle()); !ok || entity != packet {
+		t.Error("Expected the spawned packet to be in the world")
+	}
+
+	packet.SetActive(false)
+	world.RemoveInactiveEntities()
+	again, err := world.Spawn("packet.tcp", nil)
+	if err != nil {
+		t.Fatalf("Failed to spawn packet: %v", err)
+	}
+	stats := world.PoolStats()
+	if again != packet || stats.Components["Transform"].Reused != 1 {
+		t.Errorf("Expected the second packet to reuse the first, got stats %+v", stats)
+	}
+	if transform, _ := Get[*components.Transform](world, again.ID); transform.X != 0 {
+		t.Errorf("Expected a reused transform to take the prefab's values, got %+v", transform)
+	}
+
+	if _, err := world.Spawn("packet.ftp", nil); err == nil {
+		t.Error("Expected an unknown prefab to fail")
+	}
+	if len(world.Entities) != 1 {
+		t.Errorf("Expected a failed spawn to add no entity, got %d entities", len(world.Entities))
+	}
+}
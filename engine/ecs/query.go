@@ -0,0 +1,123 @@
+package ecs
+
+import (
+	"iter"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/systems"
+	"reflect"
+)
+
+// Row2 holds the components yielded by Query2 for a single entity
+type Row2[A, B components.Component] struct {
+	First  A
+	Second B
+}
+
+// Row3 holds the components yielded by Query3 for a single entity
+type Row3[A, B, C components.Component] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Get returns the component of concrete type T attached to the entity.
+// T must be the stored Go type, e.g. ecs.Get[*components.Transform](w, id).
+func Get[T components.Component](w *World, id uint64) (T, bool) {
+	w.store.mu.RLock()
+	defer w.store.mu.RUnlock()
+	component, ok := w.store.get(id, reflect.TypeFor[T]()).(T)
+	return component, ok
+}
+
+// Has reports whether the entity has a component of concrete type T
+func Has[T components.Component](w *World, id uint64) bool {
+	_, ok := Get[T](w, id)
+	return ok
+}
+
+// Queries iterate over the matching tables in place, as they were when
+// iteration started. The world may be changed from the loop body: a table is
+// copied before rows are moved out of it, and the changes are not seen until
+// the next query.
+
+// Query iterates over every entity that has a component of type A
+func Query[A components.Component](w *World) iter.Seq2[uint64, A] {
+	return func(yield func(uint64, A) bool) {
+		query(w, yield)
+	}
+}
+
+func query[A components.Component](w *World, yield func(uint64, A) bool) {
+	cache := w.store.read(systems.TableKey{reflect.TypeFor[A]()})
+	defer w.store.release()
+	for _, table := range cache.tables {
+		for row, id := range table.entities {
+			if !yield(id, table.columns[0][row].(A)) {
+				return
+			}
+		}
+	}
+}
+
+// Query2 iterates over every entity that has components of both type A and B
+func Query2[A, B components.Component](w *World) iter.Seq2[uint64, Row2[A, B]] {
+	return func(yield func(uint64, Row2[A, B]) bool) {
+		query2(w, yield)
+	}
+}
+
+func query2[A, B components.Component](w *World, yield func(uint64, Row2[A, B]) bool) {
+	cache := w.store.read(systems.TableKey{reflect.TypeFor[A](), reflect.TypeFor[B]()})
+	defer w.store.release()
+	for _, table := range cache.tables {
+		for row, id := range table.entities {
+			result := Row2[A, B]{
+				First:  table.columns[0][row].(A),
+				Second: table.columns[1][row].(B),
+			}
+			if !yield(id, result) {
+				return
+			}
+		}
+	}
+}
+
+// Query3 iterates over every entity that has components of type A, B and C
+func Query3[A, B, C components.Component](w *World) iter.Seq2[uint64, Row3[A, B, C]] {
+	return func(yield func(uint64, Row3[A, B, C]) bool) {
+		query3(w, yield)
+	}
+}
+
+func query3[A, B, C components.Component](w *World, yield func(uint64, Row3[A, B, C]) bool) {
+	cache := w.store.read(systems.TableKey{reflect.TypeFor[A](), reflect.TypeFor[B](), reflect.TypeFor[C]()})
+	defer w.store.release()
+	for _, table := range cache.tables {
+		for row, id := range table.entities {
+			result := Row3[A, B, C]{
+				First:  table.columns[0][row].(A),
+				Second: table.columns[1][row].(B),
+				Third:  table.columns[2][row].(C),
+			}
+			if !yield(id, result) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns the number of entities that have a component of type A
+func Count[A components.Component](w *World) int {
+	return w.store.count(systems.TableKey{reflect.TypeFor[A]()})
+}
+
+// Tables returns the tables whose entities have all of the types of key, to
+// be read in place until ReleaseTables is called
+func (w *World) Tables(key systems.TableKey) []systems.Table {
+	return w.store.read(key).shared
+}
+
+// ReleaseTables ends a read of the tables returned by Tables
+func (w *World) ReleaseTables() {
+	w.store.release()
+}
@@ -0,0 +1,223 @@
+package ecs
+
+import (
+	"image/color"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/systems"
+	"testing"
+)
+
+// TestGet tests typed component lookup
+func TestGet(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	transform := components.NewTransform(3, 4)
+	entity.AddComponent(transform)
+
+	got, ok := Get[*components.Transform](world, entity.ID)
+	if !ok || got != transform {
+		t.Error("Expected Get to return the stored transform")
+	}
+
+	if _, ok := Get[*components.Physics](world, entity.ID); ok {
+		t.Error("Expected Get to fail for a missing component")
+	}
+
+	if _, ok := Get[*components.Transform](world, 999); ok {
+		t.Error("Expected Get to fail for an unknown entity")
+	}
+
+	if !Has[*components.Transform](world, entity.ID) {
+		t.Error("Expected Has to report the stored transform")
+	}
+}
+
+// TestQuery2 tests iterating over entities with two component types
+func TestQuery2(t *testing.T) {
+	world := NewWorld()
+
+	moving := world.NewEntity()
+	moving.AddComponent(components.NewTransform(0, 0))
+	physics := components.NewPhysics()
+	physics.SetVelocity(0, 10)
+	moving.AddComponent(physics)
+
+	movingWithSprite := world.NewEntity()
+	movingWithSprite.AddComponent(components.NewTransform(0, 0))
+	movingWithSprite.AddComponent(components.NewPhysics())
+	movingWithSprite.AddComponent(components.NewSprite(1, 1, color.RGBA{}))
+
+	static := world.NewEntity()
+	static.AddComponent(components.NewTransform(0, 0))
+
+	seen := make(map[uint64]bool)
+	for id, row := range Query2[*components.Transform, *components.Physics](world) {
+		seen[id] = true
+		row.First.SetPosition(row.First.X+row.Second.VelocityX, row.First.Y+row.Second.VelocityY)
+	}
+
+	if len(seen) != 2 || !seen[moving.ID] || !seen[movingWithSprite.ID] {
+		t.Errorf("Expected Query2 to visit exactly the two moving entities, got %v", seen)
+	}
+
+	if moving.GetTransform().GetY() != 10 {
+		t.Errorf("Expected query to mutate stored components, got Y=%f", moving.GetTransform().GetY())
+	}
+
+	if Count[*components.Transform](world) != 3 {
+		t.Errorf("Expected 3 entities with Transform, got %d", Count[*components.Transform](world))
+	}
+}
+
+// TestQuery_EarlyBreak tests that iteration can be stopped early
+func TestQuery_EarlyBreak(t *testing.T) {
+	world := NewWorld()
+	for range 5 {
+		world.NewEntity().AddComponent(components.NewTransform(0, 0))
+	}
+
+	visited := 0
+	for range Query[*components.Transform](world) {
+		visited++
+		if visited == 2 {
+			break
+		}
+	}
+
+	if visited != 2 {
+		t.Errorf("Expected iteration to stop after 2 entities, got %d", visited)
+	}
+}
+
+// TestQuery_ChangeDuringIteration tests that entities moved between tables from the loop are visited once
+func TestQuery_ChangeDuringIteration(t *testing.T) {
+	world := NewWorld()
+	for range 4 {
+		world.NewEntity().AddComponent(components.NewTransform(0, 0))
+	}
+
+	visits := make(map[uint64]int)
+	for id := range Query[*components.Transform](world) {
+		visits[id]++
+		// Moves the entity into another table and adds one to the query's
+		world.byID[id].AddComponent(components.NewPhysics())
+		world.NewEntity().AddComponent(components.NewTransform(0, 0))
+	}
+
+	if len(visits) != 4 {
+		t.Errorf("Expected the 4 entities there when the query started, got %v", visits)
+	}
+	for id, count := range visits {
+		if count != 1 {
+			t.Errorf("Expected entity %d visited once, got %d", id, count)
+		}
+	}
+	if Count[*components.Transform](world) != 8 {
+		t.Errorf("Expected the entities added in the loop counted afterwards, got %d", Count[*components.Transform](world))
+	}
+}
+
+// TestWorld_Tables tests that systems query the world's tables for their entities and components
+func TestWorld_Tables(t *testing.T) {
+	world := NewWorld()
+	moving := world.NewEntity()
+	moving.AddComponent(components.NewTransform(0, 0))
+	moving.AddComponent(components.NewPhysics())
+	world.NewEntity().AddComponent(components.NewTransform(0, 0))
+
+	count := 0
+	for entity, row := range systems.Query2[*components.Transform, *components.Physics](world, nil) {
+		count++
+		if entity.GetID() != moving.ID || row.First != moving.GetTransform() {
+			t.Errorf("Unexpected row for entity %d", entity.GetID())
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 matching entity, got %d", count)
+	}
+}
+
+// TestQuery3 tests iterating over entities with three component types
+func TestQuery3(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	entity.AddComponent(components.NewTransform(0, 0))
+	entity.AddComponent(components.NewPhysics())
+	entity.AddComponent(components.NewCollider(1, 1, "packet"))
+	world.NewEntity().AddComponent(components.NewTransform(0, 0))
+
+	count := 0
+	for id, row := range Query3[*components.Transform, *components.Physics, *components.Collider](world) {
+		count++
+		if id != entity.ID || row.Third.GetTag() != "packet" {
+			t.Errorf("Unexpected row for entity %d", id)
+		}
+	}
+
+	if count != 1 {
+		t.Errorf("Expected 1 matching entity, got %d", count)
+	}
+}
+
+func BenchmarkQuery2(b *testing.B) {
+	world := NewWorld()
+	for i := range 5000 {
+		entity := world.NewEntity()
+		entity.AddComponent(components.NewTransform(float64(i), 0))
+		entity.AddComponent(components.NewPhysics())
+		if i%2 == 0 {
+			entity.AddComponent(components.NewSprite(15, 15, color.RGBA{}))
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range Query2[*components.Transform, *components.Physics](world) {
+			row.First.Y += row.Second.VelocityY
+		}
+	}
+}
+
+// TestWorld_UpdateAllDoesNotAllocate tests that systems querying the world's
+// tables run a frame without allocating once nothing is created or destroyed
+func TestWorld_UpdateAllDoesNotAllocate(t *testing.T) {
+	world := NewWorldWithSeed(1)
+	loadBalancer := world.NewEntity()
+	loadBalancer.AddComponent(components.NewTransform(0, 550))
+	loadBalancer.AddComponent(components.NewCollider(100, 20, "loadbalancer"))
+	for i := range 100 {
+		packet := world.NewEntity()
+		packet.AddComponent(components.NewTransform(float64(i), 0))
+		physics := components.NewPhysics()
+		physics.SetVelocity(0, 1)
+		packet.AddComponent(physics)
+		packet.AddComponent(components.NewCollider(15, 15, "packet"))
+		packet.AddComponent(components.NewPacketType("HTTP", 10))
+	}
+
+	manager := systems.NewSystemManager()
+	manager.SetExecutionMode(systems.ExecutionDeterministic)
+	for _, system := range []systems.SystemInfoer{systems.NewMovementSystem(), systems.NewCollisionSystem()} {
+		info := system.GetSystemInfo()
+		// Nothing spawns, so the movement system does without a spawner
+		info.Requires = nil
+		if err := manager.RegisterSystem(info); err != nil {
+			t.Fatalf("Failed to register %s: %v", info.Type, err)
+		}
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build the execution order: %v", err)
+	}
+	manager.AttachViews(world)
+	manager.AttachTables(world)
+	manager.AttachCommands(NewCommandBuffer(world))
+	manager.AttachEntityStore(world)
+
+	update := func() {
+		manager.UpdateAll(1.0/60, world.EntityList(), world.EventDispatcher)
+	}
+	update()
+	if allocs := testing.AllocsPerRun(100, update); allocs != 0 {
+		t.Errorf("Expected UpdateAll to run without allocating, got %v allocations per frame", allocs)
+	}
+}
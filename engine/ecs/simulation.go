@@ -0,0 +1,41 @@
+package ecs
+
+import (
+	"fmt"
+	"lbbaspack/engine/systems"
+)
+
+// Simulation is a copy of a world and its systems that can be stepped ahead
+// without affecting them, to preview where packets land or to try out other
+// moves
+type Simulation struct {
+	World   *World
+	Systems *systems.SystemManager
+}
+
+// NewSimulation clones world and the state of the systems of manager.
+// newManager creates the systems of the clone, attached to it, which should
+// match those of manager; their state is then copied from manager's. The
+// clone's systems and events run in the same modes as the world's.
+func NewSimulation(world *World, manager *systems.SystemManager, newManager func(clone *World) (*systems.SystemManager, error)) (*Simulation, error) {
+	clone, err := world.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone world: %w", err)
+	}
+	systemManager, err := newManager(clone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create system manager: %w", err)
+	}
+	if err := systemManager.CopyStatesFrom(manager); err != nil {
+		return nil, fmt.Errorf("failed to copy system state: %w", err)
+	}
+	systemManager.SetExecutionMode(manager.GetExecutionMode())
+	clone.EventDispatcher.SetMode(world.EventDispatcher.Mode())
+	return &Simulation{World: clone, Systems: systemManager}, nil
+}
+
+// Step advances the simulation by one fixed step, like the running game
+func (s *Simulation) Step(deltaTime float64) {
+	s.World.StorePreviousTransforms()
+	s.Systems.UpdateAll(deltaTime, s.World.EntityList(), s.World.EventDispatcher)
+}
@@ -0,0 +1,226 @@
+package ecs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/random"
+)
+
+// SnapshotVersion is the current snapshot format version. Decoders reject
+// snapshots written by a newer version. Version 2 added entity handles;
+// entities in version 1 snapshots are issued fresh ones. Version 3 added the
+// random stream positions; older snapshots leave the streams as they are.
+// Version 4 saves the state of each stream instead of its position; the
+// streams of version 3 snapshots start over.
+const SnapshotVersion = 4
+
+// binaryMagic prefixes every binary snapshot
+var binaryMagic = []byte("LBSNAP")
+
+// Snapshot is a serializable copy of a world's entities and, optionally, the
+// internal state of its systems
+type Snapshot struct {
+	Version      int                        `json:"version"`
+	NextEntityID uint64                     `json:"nextEntityId"`
+	Generations  []uint32                   `json:"generations,omitempty"`
+	RNG          *random.State              `json:"rng,omitempty"`
+	Entities     []EntitySnapshot           `json:"entities"`
+	Systems      map[string]json.RawMessage `json:"systems,omitempty"`
+}
+
+// EntitySnapshot is a serialized entity
+type EntitySnapshot struct {
+	ID         uint64              `json:"id"`
+	Handle     EntityHandle        `json:"handle"`
+	Active     bool                `json:"active"`
+	Components []ComponentSnapshot `json:"components"`
+}
+
+// ComponentSnapshot is a serialized component, keyed by its GetType name
+type ComponentSnapshot struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Snapshot captures every entity in the world and the positions of its random
+// streams. Components must have a type registered with components.Register.
+func (w *World) Snapshot() (*Snapshot, error) {
+	rng := w.RNG.State()
+	snapshot := &Snapshot{
+		Version:      SnapshotVersion,
+		NextEntityID: w.nextEntityID,
+		Generations:  w.slotGenerations(),
+		RNG:          &rng,
+		Entities:     make([]EntitySnapshot, 0, len(w.Entities)),
+		Systems:      make(map[string]json.RawMessage),
+	}
+
+	for _, entity := range w.Entities {
+		if entity == nil {
+			continue
+		}
+		entitySnapshot := EntitySnapshot{
+			ID:         entity.ID,
+			Handle:     entity.Handle(),
+			Active:     entity.IsActive(),
+			Components: make([]ComponentSnapshot, 0),
+		}
+		for _, name := range entity.GetComponentNames() {
+			if _, registered := components.New(name); !registered {
+				return nil, fmt.Errorf("entity %d: component %q has no registered type", entity.ID, name)
+			}
+			data, err := json.Marshal(entity.GetComponent(name))
+			if err != nil {
+				return nil, fmt.Errorf("entity %d: failed to encode component %q: %w", entity.ID, name, err)
+			}
+			entitySnapshot.Components = append(entitySnapshot.Components, ComponentSnapshot{Type: name, Data: data})
+		}
+		snapshot.Entities = append(snapshot.Entities, entitySnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// Restore replaces every entity in the world with the ones in the snapshot,
+// keeping their handles so that references between entities stay valid, and
+// brings the random streams to the saved positions. The world is left
+// untouched if any component or handle fails to decode.
+func (w *World) Restore(snapshot *Snapshot) error {
+	if snapshot == nil {
+		return errors.New("snapshot is nil")
+	}
+	if snapshot.Version > SnapshotVersion {
+		return fmt.Errorf("snapshot version %d is newer than supported version %d", snapshot.Version, SnapshotVersion)
+	}
+
+	handles := make([]EntityHandle, 0, len(snapshot.Entities))
+	if len(snapshot.Generations) > 0 {
+		claimed := make(map[uint32]bool, len(snapshot.Entities))
+		for _, entitySnapshot := range snapshot.Entities {
+			handle := entitySnapshot.Handle
+			if !handle.IsValid() || int(handle.Index) >= len(snapshot.Generations) ||
+				snapshot.Generations[handle.Index] != handle.Generation || claimed[handle.Index] {
+				return fmt.Errorf("entity %d: invalid handle %s", entitySnapshot.ID, handle)
+			}
+			claimed[handle.Index] = true
+			handles = append(handles, handle)
+		}
+	}
+
+	restored := make([]*entities.Entity, 0, len(snapshot.Entities))
+	for i, entitySnapshot := range snapshot.Entities {
+		entity := entities.NewEntity(entitySnapshot.ID)
+		if len(handles) > 0 {
+			entity.SetHandle(handles[i])
+		}
+		for _, componentSnapshot := range entitySnapshot.Components {
+			component, registered := components.New(componentSnapshot.Type)
+			if !registered {
+				return fmt.Errorf("entity %d: component %q has no registered type", entitySnapshot.ID, componentSnapshot.Type)
+			}
+			if err := json.Unmarshal(componentSnapshot.Data, component); err != nil {
+				return fmt.Errorf("entity %d: failed to decode component %q: %w", entitySnapshot.ID, componentSnapshot.Type, err)
+			}
+			entity.AddComponent(component)
+		}
+		entity.SetActive(entitySnapshot.Active)
+		restored = append(restored, entity)
+	}
+
+	if snapshot.RNG != nil {
+		if err := w.RNG.Restore(*snapshot.RNG); err != nil {
+			return fmt.Errorf("failed to restore random streams: %w", err)
+		}
+	}
+
+	w.ClearAllEntities()
+	if len(handles) > 0 {
+		w.resetSlots(snapshot.Generations, handles)
+	}
+	for _, entity := range restored {
+		w.AddEntity(entity)
+	}
+	if snapshot.NextEntityID > w.nextEntityID {
+		w.nextEntityID = snapshot.NextEntityID
+	}
+	return nil
+}
+
+// EncodeJSON writes the snapshot as indented JSON
+func (s *Snapshot) EncodeJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// DecodeJSONSnapshot reads a snapshot written by EncodeJSON
+func DecodeJSONSnapshot(in io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(in).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON snapshot: %w", err)
+	}
+	if snapshot.Version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than supported version %d", snapshot.Version, SnapshotVersion)
+	}
+	return snapshot, nil
+}
+
+// EncodeBinary writes the snapshot in the compact binary format: a magic
+// header and version byte followed by a gzip-compressed gob stream
+func (s *Snapshot) EncodeBinary(out io.Writer) error {
+	if _, err := out.Write(binaryMagic); err != nil {
+		return err
+	}
+	if _, err := out.Write([]byte{byte(s.Version)}); err != nil {
+		return err
+	}
+	compressor := gzip.NewWriter(out)
+	if err := gob.NewEncoder(compressor).Encode(s); err != nil {
+		return fmt.Errorf("failed to encode binary snapshot: %w", err)
+	}
+	return compressor.Close()
+}
+
+// DecodeBinarySnapshot reads a snapshot written by EncodeBinary
+func DecodeBinarySnapshot(in io.Reader) (*Snapshot, error) {
+	header := make([]byte, len(binaryMagic)+1)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if !bytes.Equal(header[:len(binaryMagic)], binaryMagic) {
+		return nil, errors.New("not a binary snapshot")
+	}
+	if version := int(header[len(binaryMagic)]); version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than supported version %d", version, SnapshotVersion)
+	}
+
+	decompressor, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open binary snapshot: %w", err)
+	}
+	defer decompressor.Close()
+
+	snapshot := &Snapshot{}
+	if err := gob.NewDecoder(decompressor).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode binary snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// DecodeSnapshot reads a snapshot in either format, detected from its header
+func DecodeSnapshot(in io.Reader) (*Snapshot, error) {
+	reader := bufio.NewReader(in)
+	header, _ := reader.Peek(len(binaryMagic))
+	if bytes.Equal(header, binaryMagic) {
+		return DecodeBinarySnapshot(reader)
+	}
+	return DecodeJSONSnapshot(reader)
+}
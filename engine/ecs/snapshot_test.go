@@ -0,0 +1,245 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/random"
+	"strings"
+	"testing"
+)
+
+// newSnapshotTestWorld creates a world with a packet, a power-up and an inactive entity
+func newSnapshotTestWorld() *World {
+	world := NewWorld()
+
+	packet := world.NewEntity()
+	packet.AddComponent(components.NewTransform(10, 20))
+	packet.AddComponent(components.NewSprite(8, 8, color.RGBA{255, 0, 0, 255}))
+	packet.AddComponent(components.NewPhysics())
+	packet.AddComponent(components.NewPacketType("HTTPS", 15))
+
+	powerUp := world.NewEntity()
+	powerUp.AddComponent(components.NewTransform(30, 40))
+	powerUp.AddComponent(components.NewPowerUpType("Speed Boost", 5))
+
+	// Churn a slot so that the next handle carries generation 2
+	world.RemoveEntity(world.NewEntity())
+
+	inactive := world.NewEntity()
+	inactive.AddComponent(components.NewBackendAssignment(3))
+	inactive.SetActive(false)
+	packet.AddComponent(components.NewRouting(inactive.Handle(), 120))
+
+	return world
+}
+
+// TestWorld_SnapshotRestore tests that a restored world matches the original
+func TestWorld_SnapshotRestore(t *testing.T) {
+	original := newSnapshotTestWorld()
+	snapshot, err := original.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	if snapshot.Version != SnapshotVersion {
+		t.Errorf("Expected version %d, got %d", SnapshotVersion, snapshot.Version)
+	}
+	if len(snapshot.Entities) != 3 {
+		t.Fatalf("Expected 3 entities in snapshot, got %d", len(snapshot.Entities))
+	}
+
+	restored := NewWorld()
+	restored.NewEntity() // replaced by Restore
+	if err := restored.Restore(snapshot); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if len(restored.Entities) != 3 {
+		t.Fatalf("Expected 3 restored entities, got %d", len(restored.Entities))
+	}
+
+	transform, ok := Get[*components.Transform](restored, original.Entities[0].ID)
+	if !ok || transform.X != 10 || transform.Y != 20 {
+		t.Errorf("Expected restored transform (10,20), got %+v", transform)
+	}
+
+	routing, ok := Get[*components.Routing](restored, original.Entities[0].ID)
+	if !ok || routing.OriginalSpeed != 120 {
+		t.Errorf("Expected restored routing with speed 120, got %+v", routing)
+	}
+	target, resolved := restored.Resolve(routing.TargetBackend)
+	if !resolved || target.GetBackendAssignment() == nil || target.GetBackendAssignment().GetBackendID() != 3 {
+		t.Errorf("Expected restored routing target to resolve to backend 3, got %v", target)
+	}
+	for i, entity := range restored.Entities {
+		if entity.Handle() != original.Entities[i].Handle() {
+			t.Errorf("Expected entity %d to keep handle %s, got %s", entity.ID, original.Entities[i].Handle(), entity.Handle())
+		}
+	}
+
+	sprite, ok := Get[*components.Sprite](restored, original.Entities[0].ID)
+	if !ok || sprite.Color != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected restored sprite color, got %+v", sprite)
+	}
+
+	powerUp, ok := Get[*components.PowerUpType](restored, original.Entities[1].ID)
+	if !ok || powerUp.Name != "Speed Boost" || powerUp.Duration != 5 {
+		t.Errorf("Expected restored power-up, got %+v", powerUp)
+	}
+
+	if restored.Entities[2].IsActive() {
+		t.Error("Expected inactive entity to stay inactive")
+	}
+
+	next := restored.NewEntity()
+	if next.ID != original.nextEntityID {
+		t.Errorf("Expected next entity ID %d, got %d", original.nextEntityID, next.ID)
+	}
+}
+
+// TestWorld_SnapshotUnregisteredComponent tests that unknown component types are rejected
+func TestWorld_SnapshotUnregisteredComponent(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	entity.AddComponent(&storageMockComponent{name: "Unregistered"})
+
+	if _, err := world.Snapshot(); err == nil {
+		t.Error("Expected snapshot of unregistered component to fail")
+	}
+}
+
+// TestWorld_RestoreRejectsBadSnapshotAtomically tests that a failed restore leaves the world untouched
+func TestWorld_RestoreRejectsBadSnapshotAtomically(t *testing.T) {
+	world := newSnapshotTestWorld()
+	snapshot := &Snapshot{
+		Version: SnapshotVersion,
+		Entities: []EntitySnapshot{{
+			ID:         1,
+			Active:     true,
+			Components: []ComponentSnapshot{{Type: "Unknown", Data: json.RawMessage("{}")}},
+		}},
+	}
+
+	if err := world.Restore(snapshot); err == nil {
+		t.Fatal("Expected restore of unknown component to fail")
+	}
+	if len(world.Entities) != 3 {
+		t.Errorf("Expected world to keep its 3 entities, got %d", len(world.Entities))
+	}
+
+	if err := world.Restore(&Snapshot{Version: SnapshotVersion + 1}); err == nil {
+		t.Error("Expected restore of newer snapshot version to fail")
+	}
+	if err := world.Restore(nil); err == nil {
+		t.Error("Expected restore of nil snapshot to fail")
+	}
+}
+
+// TestSnapshot_EncodingRoundtrip tests both encodings and format detection
+func TestSnapshot_EncodingRoundtrip(t *testing.T) {
+	source := newSnapshotTestWorld()
+	spawn := source.RNG.Stream(random.StreamSpawn)
+	for range 3 {
+		spawn.Int63()
+	}
+	snapshot, err := source.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	snapshot.Systems["sla"] = json.RawMessage(`{"caughtPackets":4}`)
+	next := spawn.Int63()
+
+	encoders := map[string]func(*bytes.Buffer) error{
+		"json":   func(buf *bytes.Buffer) error { return snapshot.EncodeJSON(buf) },
+		"binary": func(buf *bytes.Buffer) error { return snapshot.EncodeBinary(buf) },
+	}
+
+	for name, encode := range encoders {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encode(&buf); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			decoded, err := DecodeSnapshot(&buf)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			if decoded.NextEntityID != snapshot.NextEntityID {
+				t.Errorf("Expected next ID %d, got %d", snapshot.NextEntityID, decoded.NextEntityID)
+			}
+			if len(decoded.Entities) != len(snapshot.Entities) {
+				t.Fatalf("Expected %d entities, got %d", len(snapshot.Entities), len(decoded.Entities))
+			}
+			var slaState struct{ CaughtPackets int }
+			if err := json.Unmarshal(decoded.Systems["sla"], &slaState); err != nil || slaState.CaughtPackets != 4 {
+				t.Errorf("Expected system state to survive, got %s", decoded.Systems["sla"])
+			}
+
+			world := NewWorld()
+			if err := world.Restore(decoded); err != nil {
+				t.Fatalf("Restore failed: %v", err)
+			}
+			if Count[*components.Transform](world) != 2 {
+				t.Errorf("Expected 2 transforms, got %d", Count[*components.Transform](world))
+			}
+			if got := world.RNG.Stream(random.StreamSpawn).Int63(); got != next {
+				t.Errorf("Expected the spawn stream to resume at %d, got %d", next, got)
+			}
+		})
+	}
+}
+
+// TestSnapshot_BinaryIsSmaller tests that the binary format is more compact than JSON
+func TestSnapshot_BinaryIsSmaller(t *testing.T) {
+	world := NewWorld()
+	for i := 0; i < 100; i++ {
+		entity := world.NewEntity()
+		entity.AddComponent(components.NewTransform(float64(i), float64(i)))
+		entity.AddComponent(components.NewPhysics())
+	}
+	snapshot, err := world.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	var jsonBuf, binaryBuf bytes.Buffer
+	if err := snapshot.EncodeJSON(&jsonBuf); err != nil {
+		t.Fatalf("EncodeJSON failed: %v", err)
+	}
+	if err := snapshot.EncodeBinary(&binaryBuf); err != nil {
+		t.Fatalf("EncodeBinary failed: %v", err)
+	}
+
+	if binaryBuf.Len() >= jsonBuf.Len() {
+		t.Errorf("Expected binary (%d bytes) to be smaller than JSON (%d bytes)", binaryBuf.Len(), jsonBuf.Len())
+	}
+}
+
+// TestSnapshot_DecodeRejectsNewerVersion tests version checks in both decoders
+func TestSnapshot_DecodeRejectsNewerVersion(t *testing.T) {
+	future := &Snapshot{Version: SnapshotVersion + 1}
+
+	var jsonBuf bytes.Buffer
+	if err := future.EncodeJSON(&jsonBuf); err != nil {
+		t.Fatalf("EncodeJSON failed: %v", err)
+	}
+	if _, err := DecodeSnapshot(&jsonBuf); err == nil {
+		t.Error("Expected JSON decode of newer version to fail")
+	}
+
+	var binaryBuf bytes.Buffer
+	if err := future.EncodeBinary(&binaryBuf); err != nil {
+		t.Fatalf("EncodeBinary failed: %v", err)
+	}
+	if _, err := DecodeSnapshot(&binaryBuf); err == nil {
+		t.Error("Expected binary decode of newer version to fail")
+	}
+
+	if _, err := DecodeSnapshot(strings.NewReader("garbage")); err == nil {
+		t.Error("Expected decode of garbage to fail")
+	}
+}
@@ -0,0 +1,451 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/systems"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// archetype is a table holding every entity that has exactly the same set of
+// component Go types. Each component type owns one column; entity i of the
+// table has its components at row i of every column.
+type archetype struct {
+	key      string
+	types    []reflect.Type
+	index    map[reflect.Type]int
+	entities []uint64
+	owners   []systems.Entity // the entity of each row, for systems
+	columns  [][]components.Component
+	// edges caches the archetype reached by adding or removing a single type
+	addEdges    map[reflect.Type]*archetype
+	removeEdges map[reflect.Type]*archetype
+}
+
+// entityLocation is the table and row an entity currently occupies
+type entityLocation struct {
+	arch *archetype
+	row  int
+}
+
+// componentStorage stores the components of all world-owned entities in
+// archetype tables keyed by Go type. It implements entities.Storage so that
+// string-keyed access keeps working for existing systems.
+type componentStorage struct {
+	mu          sync.RWMutex
+	archetypes  map[string]*archetype
+	ordered     []*archetype // creation order, for deterministic iteration
+	empty       *archetype
+	locations   map[uint64]entityLocation
+	typesByName map[string]reflect.Type
+	version     uint64 // bumped whenever a row is added, moved or removed
+	tables      map[systems.TableKey]*tableCache
+	// readers counts the queries iterating the tables in place; while any
+	// is, an archetype is copied before its rows are moved
+	readers  atomic.Int64
+	onChange func(id uint64) // called when an entity's component set or activity changes
+	// onComponent is called, outside the lock, when a component type is
+	// added to or removed from an entity
+	onComponent func(id uint64, componentType string, added bool)
+}
+
+// table is the rows of an archetype as they were when it was looked up,
+// holding the columns of the types it was looked up for
+type table struct {
+	entities []uint64
+	columns  [][]components.Component
+}
+
+// tableCache holds the tables that satisfy a set of component types, both
+// for the world's own queries and for systems
+type tableCache struct {
+	version uint64
+	tables  []table
+	shared  []systems.Table
+}
+
+func newComponentStorage() *componentStorage {
+	cs := &componentStorage{
+		archetypes:  make(map[string]*archetype),
+		ordered:     make([]*archetype, 0),
+		locations:   make(map[uint64]entityLocation),
+		typesByName: make(map[string]reflect.Type),
+		tables:      make(map[systems.TableKey]*tableCache),
+	}
+	cs.empty = cs.archetypeFor(nil)
+	return cs
+}
+
+// typeKey returns a stable sort key for a component type
+func typeKey(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + t.Elem().PkgPath() + "." + t.Elem().Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// archetypeKey returns the lookup key for a sorted set of types
+func archetypeKey(types []reflect.Type) string {
+	keys := make([]string, len(types))
+	for i, t := range types {
+		keys[i] = typeKey(t)
+	}
+	return strings.Join(keys, ",")
+}
+
+// sortTypes sorts types by their type key in place
+func sortTypes(types []reflect.Type) {
+	sort.Slice(types, func(i, j int) bool {
+		return typeKey(types[i]) < typeKey(types[j])
+	})
+}
+
+// archetypeFor returns the archetype for the given sorted types, creating it if needed
+func (cs *componentStorage) archetypeFor(types []reflect.Type) *archetype {
+	key := archetypeKey(types)
+	if arch, exists := cs.archetypes[key]; exists {
+		return arch
+	}
+
+	arch := &archetype{
+		key:         key,
+		types:       types,
+		index:       make(map[reflect.Type]int, len(types)),
+		entities:    make([]uint64, 0),
+		owners:      make([]systems.Entity, 0),
+		columns:     make([][]components.Component, len(types)),
+		addEdges:    make(map[reflect.Type]*archetype),
+		removeEdges: make(map[reflect.Type]*archetype),
+	}
+	for i, t := range types {
+		arch.index[t] = i
+		arch.columns[i] = make([]components.Component, 0)
+	}
+
+	cs.archetypes[key] = arch
+	cs.ordered = append(cs.ordered, arch)
+	return arch
+}
+
+// withType returns the archetype reached by adding t to arch
+func (cs *componentStorage) withType(arch *archetype, t reflect.Type) *archetype {
+	if next, exists := arch.addEdges[t]; exists {
+		return next
+	}
+	types := make([]reflect.Type, 0, len(arch.types)+1)
+	types = append(types, arch.types...)
+	types = append(types, t)
+	sortTypes(types)
+	next := cs.archetypeFor(types)
+	arch.addEdges[t] = next
+	next.removeEdges[t] = arch
+	return next
+}
+
+// withoutType returns the archetype reached by removing t from arch
+func (cs *componentStorage) withoutType(arch *archetype, t reflect.Type) *archetype {
+	if next, exists := arch.removeEdges[t]; exists {
+		return next
+	}
+	types := make([]reflect.Type, 0, len(arch.types))
+	for _, existing := range arch.types {
+		if existing != t {
+			types = append(types, existing)
+		}
+	}
+	next := cs.archetypeFor(types)
+	arch.removeEdges[t] = next
+	next.addEdges[t] = arch
+	return next
+}
+
+// insert registers an entity with no components, owned by owner
+func (cs *componentStorage) insert(id uint64, owner systems.Entity) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if _, exists := cs.locations[id]; exists {
+		return false
+	}
+	cs.version++
+	cs.empty.entities = append(cs.empty.entities, id)
+	cs.empty.owners = append(cs.empty.owners, owner)
+	cs.locations[id] = entityLocation{arch: cs.empty, row: len(cs.empty.entities) - 1}
+	return true
+}
+
+// contains reports whether the storage holds the entity
+func (cs *componentStorage) contains(id uint64) bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	_, exists := cs.locations[id]
+	return exists
+}
+
+// delete removes an entity and all of its components
+func (cs *componentStorage) delete(id uint64) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	loc, exists := cs.locations[id]
+	if !exists {
+		return
+	}
+	cs.removeRow(loc.arch, loc.row)
+	delete(cs.locations, id)
+}
+
+// removeRow swap-removes a row from an archetype and fixes the moved entity's
+// location. Queries iterating the archetype in place keep the rows they
+// started with, as it is copied first.
+func (cs *componentStorage) removeRow(arch *archetype, row int) {
+	cs.version++
+	if cs.readers.Load() > 0 {
+		arch.entities = slices.Clone(arch.entities)
+		arch.owners = slices.Clone(arch.owners)
+		for c := range arch.columns {
+			arch.columns[c] = slices.Clone(arch.columns[c])
+		}
+	}
+	last := len(arch.entities) - 1
+	if row != last {
+		moved := arch.entities[last]
+		arch.entities[row] = moved
+		arch.owners[row] = arch.owners[last]
+		for c := range arch.columns {
+			arch.columns[c][row] = arch.columns[c][last]
+		}
+		cs.locations[moved] = entityLocation{arch: arch, row: row}
+	}
+	arch.entities = arch.entities[:last]
+	arch.owners[last] = nil
+	arch.owners = arch.owners[:last]
+	for c := range arch.columns {
+		arch.columns[c][last] = nil
+		arch.columns[c] = arch.columns[c][:last]
+	}
+}
+
+// move relocates an entity from its current archetype into target. The
+// component for extra, if any, is taken from extraValue.
+func (cs *componentStorage) move(id uint64, loc entityLocation, target *archetype, extra reflect.Type, extraValue components.Component) {
+	target.entities = append(target.entities, id)
+	target.owners = append(target.owners, loc.arch.owners[loc.row])
+	for c, t := range target.types {
+		var value components.Component
+		if t == extra {
+			value = extraValue
+		} else {
+			value = loc.arch.columns[loc.arch.index[t]][loc.row]
+		}
+		target.columns[c] = append(target.columns[c], value)
+	}
+	cs.removeRow(loc.arch, loc.row)
+	cs.locations[id] = entityLocation{arch: target, row: len(target.entities) - 1}
+}
+
+// AddComponent implements entities.Storage
+func (cs *componentStorage) AddComponent(id uint64, component components.Component) {
+	if component == nil {
+		return
+	}
+	if cs.add(id, component) && cs.onComponent != nil {
+		cs.onComponent(id, component.GetType(), true)
+	}
+}
+
+// add stores a component and reports whether its type is new to the entity
+// rather than replacing one it already had
+func (cs *componentStorage) add(id uint64, component components.Component) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	loc, exists := cs.locations[id]
+	if !exists {
+		return false
+	}
+
+	t := reflect.TypeOf(component)
+	name := component.GetType()
+	added := cs.getByName(id, name) == nil
+
+	// A different Go type registered under the same name is replaced, keeping
+	// names unique per entity like the map-backed storage does
+	if previous, known := cs.typesByName[name]; known && previous != t {
+		if _, has := loc.arch.index[previous]; has {
+			if loc.arch.columns[loc.arch.index[previous]][loc.row].GetType() == name {
+				cs.move(id, loc, cs.withoutType(loc.arch, previous), nil, nil)
+				loc = cs.locations[id]
+			}
+		}
+	}
+	cs.typesByName[name] = t
+
+	if column, has := loc.arch.index[t]; has {
+		loc.arch.columns[column][loc.row] = component
+		cs.changed(id)
+		return added
+	}
+	cs.move(id, loc, cs.withType(loc.arch, t), t, component)
+	cs.changed(id)
+	return added
+}
+
+// changed reports a change to the entity's component set or activity
+func (cs *componentStorage) changed(id uint64) {
+	if cs.onChange != nil {
+		cs.onChange(id)
+	}
+}
+
+// ActiveChanged implements entities.ActivityTracker
+func (cs *componentStorage) ActiveChanged(id uint64, active bool) {
+	cs.changed(id)
+}
+
+// GetComponent implements entities.Storage
+func (cs *componentStorage) GetComponent(id uint64, componentType string) components.Component {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.getByName(id, componentType)
+}
+
+// getByName looks up a component by its GetType name. Callers must hold the lock.
+func (cs *componentStorage) getByName(id uint64, componentType string) components.Component {
+	t, known := cs.typesByName[componentType]
+	if !known {
+		return nil
+	}
+	component := cs.get(id, t)
+	if component == nil || component.GetType() != componentType {
+		return nil
+	}
+	return component
+}
+
+// get looks up a component by Go type. Callers must hold the lock.
+func (cs *componentStorage) get(id uint64, t reflect.Type) components.Component {
+	loc, exists := cs.locations[id]
+	if !exists {
+		return nil
+	}
+	column, has := loc.arch.index[t]
+	if !has {
+		return nil
+	}
+	return loc.arch.columns[column][loc.row]
+}
+
+// HasComponent implements entities.Storage
+func (cs *componentStorage) HasComponent(id uint64, componentType string) bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.getByName(id, componentType) != nil
+}
+
+// RemoveComponent implements entities.Storage
+func (cs *componentStorage) RemoveComponent(id uint64, componentType string) {
+	if cs.remove(id, componentType) && cs.onComponent != nil {
+		cs.onComponent(id, componentType, false)
+	}
+}
+
+// remove deletes a component and reports whether the entity had it
+func (cs *componentStorage) remove(id uint64, componentType string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.getByName(id, componentType) == nil {
+		return false
+	}
+	t := cs.typesByName[componentType]
+	loc := cs.locations[id]
+	cs.move(id, loc, cs.withoutType(loc.arch, t), nil, nil)
+	cs.changed(id)
+	return true
+}
+
+// ComponentNames implements entities.Storage
+func (cs *componentStorage) ComponentNames(id uint64) []string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	loc, exists := cs.locations[id]
+	if !exists {
+		return []string{}
+	}
+	names := make([]string, 0, len(loc.arch.columns))
+	for c := range loc.arch.columns {
+		names = append(names, loc.arch.columns[c][loc.row].GetType())
+	}
+	return names
+}
+
+// hasAll reports whether the archetype has a column for each of types
+func (arch *archetype) hasAll(types []reflect.Type) bool {
+	for _, t := range types {
+		if _, has := arch.index[t]; !has {
+			return false
+		}
+	}
+	return true
+}
+
+// tablesFor returns the non-empty archetypes that contain all of the types
+// of key, with their columns in the order of key. The result is cached until
+// the next change to the rows; it holds the archetypes' own slices.
+func (cs *componentStorage) tablesFor(key systems.TableKey) *tableCache {
+	cs.mu.RLock()
+	cache, cached := cs.tables[key]
+	if cached && cache.version == cs.version {
+		cs.mu.RUnlock()
+		return cache
+	}
+	cs.mu.RUnlock()
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cache, cached := cs.tables[key]; cached && cache.version == cs.version {
+		return cache
+	}
+	types := key[:]
+	if end := slices.Index(types, nil); end >= 0 {
+		types = types[:end]
+	}
+	// Iterators may still hold the previous cache, so it is never reused
+	cache = &tableCache{version: cs.version}
+	for _, arch := range cs.ordered {
+		if len(arch.entities) == 0 || !arch.hasAll(types) {
+			continue
+		}
+		columns := make([][]components.Component, len(types))
+		for i, t := range types {
+			columns[i] = arch.columns[arch.index[t]]
+		}
+		cache.tables = append(cache.tables, table{entities: arch.entities, columns: columns})
+		cache.shared = append(cache.shared, systems.Table{Entities: arch.owners, Columns: columns})
+	}
+	cs.tables[key] = cache
+	return cache
+}
+
+// read returns the tables for key, to be iterated in place, and counts the
+// caller as a reader of them until it calls release
+func (cs *componentStorage) read(key systems.TableKey) *tableCache {
+	cs.readers.Add(1)
+	return cs.tablesFor(key)
+}
+
+// release ends a read started by read
+func (cs *componentStorage) release() {
+	cs.readers.Add(-1)
+}
+
+// count returns the number of entities in the archetypes that contain all of
+// the types of key
+func (cs *componentStorage) count(key systems.TableKey) int {
+	count := 0
+	for _, table := range cs.tablesFor(key).tables {
+		count += len(table.entities)
+	}
+	return count
+}
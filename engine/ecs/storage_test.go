@@ -0,0 +1,151 @@
+package ecs
+
+import (
+	"image/color"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"testing"
+)
+
+// Mock component sharing one Go type across several names
+type storageMockComponent struct {
+	name string
+}
+
+func (mc *storageMockComponent) GetType() string {
+	return mc.name
+}
+
+// TestWorld_EntityComponentsLiveInStorage tests that world entities are backed by archetype storage
+func TestWorld_EntityComponentsLiveInStorage(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	transform := components.NewTransform(10, 20)
+	entity.AddComponent(transform)
+
+	if !entity.HasStorage() {
+		t.Fatal("Expected world entity to be backed by world storage")
+	}
+
+	if len(entity.Components) != 0 {
+		t.Errorf("Expected entity map to stay empty, got %d components", len(entity.Components))
+	}
+
+	if entity.GetComponent("Transform") != transform {
+		t.Error("Expected string-keyed lookup to go through world storage")
+	}
+
+	if !entity.HasComponent("Transform") {
+		t.Error("Expected HasComponent to report stored component")
+	}
+
+	names := entity.GetComponentNames()
+	if len(names) != 1 || names[0] != "Transform" {
+		t.Errorf("Expected [Transform], got %v", names)
+	}
+}
+
+// TestWorld_AddEntityAdoptsComponents tests that AddEntity moves existing components into storage
+func TestWorld_AddEntityAdoptsComponents(t *testing.T) {
+	world := NewWorld()
+	entity := entities.NewEntity(7)
+	sprite := components.NewSprite(5, 5, color.RGBA{1, 2, 3, 255})
+	entity.AddComponent(sprite)
+
+	world.AddEntity(entity)
+
+	if got, ok := Get[*components.Sprite](world, 7); !ok || got != sprite {
+		t.Error("Expected adopted component to be available through Get")
+	}
+
+	// New entities must not reuse the adopted ID
+	next := world.NewEntity()
+	if next.ID != 8 {
+		t.Errorf("Expected next entity ID 8, got %d", next.ID)
+	}
+}
+
+// TestWorld_RemovedEntityKeepsComponents tests that removed entities get their components back
+func TestWorld_RemovedEntityKeepsComponents(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	entity.AddComponent(components.NewTransform(1, 2))
+	entity.SetActive(false)
+
+	world.RemoveInactiveEntities()
+
+	if entity.HasStorage() {
+		t.Error("Expected removed entity to be detached from storage")
+	}
+
+	if entity.GetTransform() == nil {
+		t.Error("Expected removed entity to keep its components")
+	}
+
+	if _, ok := Get[*components.Transform](world, entity.ID); ok {
+		t.Error("Expected removed entity to be gone from world storage")
+	}
+}
+
+// TestStorage_ArchetypeMigration tests that entities move between tables as components change
+func TestStorage_ArchetypeMigration(t *testing.T) {
+	world := NewWorld()
+	entity1 := world.NewEntity()
+	entity2 := world.NewEntity()
+
+	entity1.AddComponent(components.NewTransform(0, 0))
+	entity2.AddComponent(components.NewTransform(0, 0))
+	entity2.AddComponent(components.NewPhysics())
+
+	if world.store.locations[entity1.ID].arch == world.store.locations[entity2.ID].arch {
+		t.Error("Expected entities with different component sets to live in different archetypes")
+	}
+
+	entity1.AddComponent(components.NewPhysics())
+	if world.store.locations[entity1.ID].arch != world.store.locations[entity2.ID].arch {
+		t.Error("Expected entities with identical component sets to share an archetype")
+	}
+
+	entity2.RemoveComponent("Physics")
+	if entity2.HasComponent("Physics") {
+		t.Error("Expected Physics to be removed")
+	}
+	if !entity2.HasComponent("Transform") {
+		t.Error("Expected Transform to survive the migration")
+	}
+	if !entity1.HasComponent("Physics") {
+		t.Error("Expected swap-removal to keep the other entity intact")
+	}
+}
+
+// TestStorage_SameGoTypeDifferentNames tests that names stay unique when Go types are shared
+func TestStorage_SameGoTypeDifferentNames(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+
+	entity.AddComponent(&storageMockComponent{name: "First"})
+	entity.AddComponent(&storageMockComponent{name: "Second"})
+
+	if entity.HasComponent("First") {
+		t.Error("Expected component sharing a Go type to be replaced")
+	}
+	if !entity.HasComponent("Second") {
+		t.Error("Expected latest component to be stored")
+	}
+}
+
+// TestStorage_ClearAllEntities tests that clearing the world empties storage
+func TestStorage_ClearAllEntities(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	entity.AddComponent(components.NewTransform(0, 0))
+
+	world.ClearAllEntities()
+
+	if world.store.contains(entity.ID) {
+		t.Error("Expected storage to be empty after ClearAllEntities")
+	}
+	if entity.GetTransform() == nil {
+		t.Error("Expected cleared entity to keep its components")
+	}
+}
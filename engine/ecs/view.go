@@ -0,0 +1,152 @@
+package ecs
+
+import (
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/systems"
+	"sync"
+)
+
+// View is a live set of the active world entities that have all of a list of
+// required components. It is maintained incrementally: component and activity
+// changes only queue the affected entity, and the view re-checks queued
+// entities the next time it is read.
+type View struct {
+	world    *World
+	required []string
+	// Two buffers are alternated when compacting so that a caller still
+	// iterating the previous slice never sees it rewritten underneath it
+	buffers [2][]systems.Entity
+	current int
+	index   map[uint64]int
+	removed int
+}
+
+// NewView creates a view of the entities with all of the required components.
+// It implements systems.ViewProvider.
+func (w *World) NewView(required []string) systems.EntityView {
+	w.refreshViews()
+
+	view := &View{
+		world:    w,
+		required: append([]string(nil), required...),
+		index:    make(map[uint64]int),
+	}
+	for _, entity := range w.Entities {
+		if entity != nil && w.byID[entity.ID] == entity {
+			view.update(entity.ID, entity)
+		}
+	}
+	w.views = append(w.views, view)
+	return view
+}
+
+// Entities implements systems.EntityView. The returned slice is owned by the
+// view and must not be modified.
+func (v *View) Entities() []systems.Entity {
+	v.world.refreshViews()
+	return v.buffers[v.current]
+}
+
+// Len returns the number of entities in the view
+func (v *View) Len() int {
+	return len(v.Entities())
+}
+
+// matches reports whether the entity belongs in the view
+func (v *View) matches(entity *entities.Entity) bool {
+	if entity == nil || !entity.IsActive() {
+		return false
+	}
+	for _, componentType := range v.required {
+		if !entity.HasComponent(componentType) {
+			return false
+		}
+	}
+	return true
+}
+
+// update adds or removes a single entity; entity is nil if it left the world
+func (v *View) update(id uint64, entity *entities.Entity) {
+	_, present := v.index[id]
+	wanted := v.matches(entity)
+
+	switch {
+	case wanted && !present:
+		v.buffers[v.current] = append(v.buffers[v.current], entity)
+		v.index[id] = len(v.buffers[v.current]) - 1
+	case !wanted && present:
+		// The entry is left in place for callers still iterating the slice
+		// and dropped by the next compaction
+		delete(v.index, id)
+		v.removed++
+	}
+}
+
+// compact drops removed entries, preserving insertion order
+func (v *View) compact() {
+	if v.removed == 0 {
+		return
+	}
+	next := 1 - v.current
+	compacted := v.buffers[next][:0]
+	for position, entity := range v.buffers[v.current] {
+		id := entity.GetID()
+		if indexed, kept := v.index[id]; !kept || indexed != position {
+			continue
+		}
+		v.index[id] = len(compacted)
+		compacted = append(compacted, entity)
+	}
+	v.buffers[next] = compacted
+	v.current = next
+	v.removed = 0
+}
+
+// viewTracker queues entities whose membership in views may have changed
+type viewTracker struct {
+	// refresh serializes refreshViews so that systems reading views from
+	// parallel workers never see a view while another worker updates it
+	refresh sync.Mutex
+	mu      sync.Mutex
+	pending []uint64
+	spare   []uint64
+	queued  map[uint64]struct{}
+}
+
+// markDirty queues an entity for re-evaluation by all views
+func (w *World) markDirty(id uint64) {
+	w.tracker.mu.Lock()
+	defer w.tracker.mu.Unlock()
+	if _, exists := w.tracker.queued[id]; exists {
+		return
+	}
+	w.tracker.queued[id] = struct{}{}
+	w.tracker.pending = append(w.tracker.pending, id)
+}
+
+// refreshViews applies all queued entity changes to every view
+func (w *World) refreshViews() {
+	w.tracker.refresh.Lock()
+	defer w.tracker.refresh.Unlock()
+
+	w.tracker.mu.Lock()
+	if len(w.tracker.pending) == 0 {
+		w.tracker.mu.Unlock()
+		return
+	}
+	pending := w.tracker.pending
+	w.tracker.pending = w.tracker.spare[:0]
+	w.tracker.spare = pending
+	clear(w.tracker.queued)
+	w.tracker.mu.Unlock()
+
+	for _, id := range pending {
+		entity := w.byID[id]
+		for _, view := range w.views {
+			view.update(id, entity)
+		}
+	}
+	for _, view := range w.views {
+		view.compact()
+	}
+}
@@ -0,0 +1,148 @@
+package ecs
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/systems"
+	"testing"
+)
+
+// viewMockSystem is a system that accepts a cached view
+type viewMockSystem struct {
+	systems.BaseSystem
+	seen int
+}
+
+func (vs *viewMockSystem) Update(deltaTime float64, entities []systems.Entity, eventDispatcher *events.EventDispatcher) {
+	vs.seen = len(vs.RequiredEntities(entities))
+}
+
+// TestView_TracksComponentChanges tests that views follow AddComponent and RemoveComponent
+func TestView_TracksComponentChanges(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	entity.AddComponent(components.NewTransform(0, 0))
+
+	view := world.NewView([]string{"Transform", "Physics"})
+	if len(view.Entities()) != 0 {
+		t.Fatalf("Expected empty view, got %d entities", len(view.Entities()))
+	}
+
+	entity.AddComponent(components.NewPhysics())
+	if len(view.Entities()) != 1 || view.Entities()[0] != entity {
+		t.Error("Expected entity to join the view after gaining Physics")
+	}
+
+	entity.RemoveComponent("Physics")
+	if len(view.Entities()) != 0 {
+		t.Error("Expected entity to leave the view after losing Physics")
+	}
+}
+
+// TestView_TracksActivityAndRemoval tests that views follow SetActive and entity removal
+func TestView_TracksActivityAndRemoval(t *testing.T) {
+	world := NewWorld()
+	view := world.NewView([]string{"Transform"})
+
+	entity1 := world.NewEntity()
+	entity1.AddComponent(components.NewTransform(0, 0))
+	entity2 := world.NewEntity()
+	entity2.AddComponent(components.NewTransform(0, 0))
+	entity3 := world.NewEntity()
+	entity3.AddComponent(components.NewTransform(0, 0))
+
+	if len(view.Entities()) != 3 {
+		t.Fatalf("Expected 3 entities, got %d", len(view.Entities()))
+	}
+
+	entity2.SetActive(false)
+	result := view.Entities()
+	if len(result) != 2 || result[0] != entity1 || result[1] != entity3 {
+		t.Error("Expected inactive entity to leave the view with order preserved")
+	}
+
+	entity2.SetActive(true)
+	if len(view.Entities()) != 3 {
+		t.Error("Expected reactivated entity to rejoin the view")
+	}
+
+	world.RemoveEntity(entity1)
+	for _, e := range view.Entities() {
+		if e == entity1 {
+			t.Error("Expected removed entity to leave the view")
+		}
+	}
+}
+
+// TestView_PreviousSliceSurvivesCompaction tests that a slice being iterated is not rewritten
+func TestView_PreviousSliceSurvivesCompaction(t *testing.T) {
+	world := NewWorld()
+	view := world.NewView([]string{"Transform"})
+	for range 3 {
+		world.NewEntity().AddComponent(components.NewTransform(0, 0))
+	}
+
+	before := view.Entities()
+	before[0].(*entities.Entity).SetActive(false)
+	after := view.Entities()
+
+	if len(after) != 2 {
+		t.Fatalf("Expected 2 entities after deactivation, got %d", len(after))
+	}
+	for i, e := range before {
+		if e == nil {
+			t.Errorf("Expected previous slice entry %d to stay intact", i)
+		}
+	}
+}
+
+// TestWorld_AddSystemAttachesView tests that AddSystem hands views to view-capable systems
+func TestWorld_AddSystemAttachesView(t *testing.T) {
+	world := NewWorld()
+	system := &viewMockSystem{BaseSystem: systems.BaseSystem{RequiredComponents: []string{"Transform"}}}
+	world.AddSystem(system)
+
+	world.NewEntity().AddComponent(components.NewTransform(0, 0))
+	world.NewEntity()
+
+	world.Update(0.016)
+	if system.seen != 1 {
+		t.Errorf("Expected system to see 1 entity through its view, got %d", system.seen)
+	}
+}
+
+// TestWorld_EntityListIsCached tests that the entity list is only rebuilt on structural changes
+func TestWorld_EntityListIsCached(t *testing.T) {
+	world := NewWorld()
+	world.NewEntity()
+	world.NewEntity()
+
+	first := world.EntityList()
+	second := world.EntityList()
+	if len(first) != 2 || &first[0] != &second[0] {
+		t.Error("Expected EntityList to return the cached slice")
+	}
+
+	world.NewEntity()
+	if len(world.EntityList()) != 3 {
+		t.Error("Expected EntityList to be rebuilt after adding an entity")
+	}
+}
+
+func BenchmarkView_Entities(b *testing.B) {
+	world := NewWorld()
+	view := world.NewView([]string{"Transform", "Physics"})
+	for range 5000 {
+		entity := world.NewEntity()
+		entity.AddComponent(components.NewTransform(0, 0))
+		entity.AddComponent(components.NewPhysics())
+	}
+	view.Entities()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = view.Entities()
+	}
+}
@@ -1,29 +1,107 @@
 package ecs
 
 import (
+	"lbbaspack/engine/components"
 	"lbbaspack/engine/entities"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/prefabs"
+	"lbbaspack/engine/random"
 	"lbbaspack/engine/systems"
+	"slices"
 )
 
 type World struct {
 	Entities        []*entities.Entity
 	Systems         []systems.System
 	EventDispatcher *events.EventDispatcher
+	RNG             *random.RNG
+	Clock           *Clock
+	Prefabs         *prefabs.Library
 	nextEntityID    uint64
+	store           *componentStorage
+	pool            *pool
+	byID            map[uint64]*entities.Entity
+	slots           []entitySlot
+	freeSlots       []uint32
+	views           []*View
+	tracker         viewTracker
+	entityList      []systems.Entity
+	entityListDirty bool
 }
 
 func NewWorld() *World {
-	return &World{
+	return NewWorldWithSeed(random.NewSeed())
+}
+
+// NewWorldWithSeed creates a world whose random streams derive from seed
+func NewWorldWithSeed(seed int64) *World {
+	return newWorld(random.New(seed), NewClock(DefaultStep), prefabs.Default())
+}
+
+// newWorld creates an empty world around the given random streams, clock and
+// prefab library
+func newWorld(rng *random.RNG, clock *Clock, library *prefabs.Library) *World {
+	w := &World{
 		Entities:        make([]*entities.Entity, 0),
 		Systems:         make([]systems.System, 0),
 		EventDispatcher: events.NewEventDispatcher(),
+		RNG:             rng,
+		Clock:           clock,
+		Prefabs:         library,
 		nextEntityID:    1,
+		store:           newComponentStorage(),
+		pool:            newPool(),
+		byID:            make(map[uint64]*entities.Entity),
+		slots:           make([]entitySlot, 0),
+		freeSlots:       make([]uint32, 0),
+		views:           make([]*View, 0),
+		tracker:         viewTracker{queued: make(map[uint64]struct{})},
+		entityList:      make([]systems.Entity, 0),
 	}
+	w.store.onChange = w.markDirty
+	w.store.onComponent = w.publishComponentEvent
+	return w
 }
 
+// AddEntity adds an entity to the world, issues its handle, moves its
+// components into the world's archetype storage and publishes
+// EventEntityCreated
 func (w *World) AddEntity(entity *entities.Entity) {
 	w.Entities = append(w.Entities, entity)
+	w.entityListDirty = true
+	if entity == nil {
+		return
+	}
+	if entity.ID >= w.nextEntityID {
+		w.nextEntityID = entity.ID + 1
+	}
+	// Entities sharing an ID with one already stored keep their own map
+	if !entity.HasStorage() && w.store.insert(entity.ID, entity) {
+		entity.AttachStorage(w.store)
+		w.byID[entity.ID] = entity
+		w.markDirty(entity.ID)
+	}
+	w.claimHandle(entity)
+	w.publishEntityCreated(entity)
+}
+
+// detach hands an entity's components back to it, drops its storage row,
+// publishes EventEntityDestroyed, frees its handle and recycles it if it came
+// from the pool. Callers must have removed the entity from Entities already,
+// so that handlers see the world without it.
+func (w *World) detach(entity *entities.Entity) {
+	if entity == nil {
+		return
+	}
+	if entity.HasStorage() {
+		entity.DetachStorage()
+		w.store.delete(entity.ID)
+		delete(w.byID, entity.ID)
+		w.markDirty(entity.ID)
+	}
+	w.publishEntityDestroyed(entity)
+	w.releaseHandle(entity)
+	w.recycle(entity)
 }
 
 func (w *World) NewEntity() *entities.Entity {
@@ -39,37 +117,105 @@ func (w *World) RemoveEntity(entity *entities.Entity) {
 			// Remove entity by swapping with last element and truncating
 			w.Entities[i] = w.Entities[len(w.Entities)-1]
 			w.Entities = w.Entities[:len(w.Entities)-1]
+			w.entityListDirty = true
+			w.detach(e)
 			return
 		}
 	}
 }
 
 func (w *World) RemoveInactiveEntities() {
-	activeEntities := make([]*entities.Entity, 0, len(w.Entities))
-	for _, entity := range w.Entities {
-		if entity.IsActive() {
-			activeEntities = append(activeEntities, entity)
+	w.removeWhere(func(entity *entities.Entity) bool {
+		return !entity.IsActive()
+	})
+}
+
+// removeWhere removes every entity matching remove, preserving the order of
+// the remaining entities
+func (w *World) removeWhere(remove func(entity *entities.Entity) bool) {
+	first := slices.IndexFunc(w.Entities, remove)
+	if first < 0 {
+		return
+	}
+	kept := make([]*entities.Entity, 0, len(w.Entities))
+	kept = append(kept, w.Entities[:first]...)
+	removed := []*entities.Entity{w.Entities[first]}
+	for _, entity := range w.Entities[first+1:] {
+		if remove(entity) {
+			removed = append(removed, entity)
+		} else {
+			kept = append(kept, entity)
 		}
 	}
-	w.Entities = activeEntities
+	w.Entities = kept
+	w.entityListDirty = true
+	for _, entity := range removed {
+		w.detach(entity)
+	}
 }
 
 func (w *World) ClearAllEntities() {
+	removed := w.Entities
 	w.Entities = make([]*entities.Entity, 0)
+	w.entityListDirty = true
+	for _, entity := range removed {
+		w.detach(entity)
+	}
+}
+
+// EntityList returns all world entities as the interface type used by systems.
+// The slice is cached and only rebuilt after entities are added or removed, so
+// callers must not modify it. A rebuild never reuses the previous slice, which
+// callers may still be iterating.
+func (w *World) EntityList() []systems.Entity {
+	if w.entityListDirty || len(w.entityList) != len(w.Entities) {
+		w.entityList = make([]systems.Entity, 0, len(w.Entities))
+		for _, entity := range w.Entities {
+			w.entityList = append(w.entityList, entity)
+		}
+		w.entityListDirty = false
+	}
+	return w.entityList
 }
 
+// AddSystem adds a system to the world, attaching a live entity view, random
+// streams, the handle resolver, the component pool and the prefab library to
+// it if the system supports them
 func (w *World) AddSystem(system systems.System) {
 	w.Systems = append(w.Systems, system)
+	if user, ok := system.(systems.ViewUser); ok {
+		user.SetView(w.NewView(user.GetRequiredComponents()))
+	}
+	if user, ok := system.(systems.TablesUser); ok {
+		user.SetTables(w)
+	}
+	if user, ok := system.(systems.RandomUser); ok {
+		user.SetRNG(w.RNG)
+	}
+	if user, ok := system.(systems.ResolverUser); ok {
+		user.SetResolver(w)
+	}
+	if user, ok := system.(systems.PoolUser); ok {
+		user.SetComponentPool(w)
+	}
+	if user, ok := system.(systems.PrefabUser); ok {
+		user.SetPrefabs(w.Prefabs)
+	}
 }
 
 func (w *World) Update(deltaTime float64) {
-	// Convert entities to the interface type for systems
-	entitiesInterface := make([]systems.Entity, len(w.Entities))
-	for i, entity := range w.Entities {
-		entitiesInterface[i] = entity
-	}
+	entitiesInterface := w.EntityList()
 
 	for _, system := range w.Systems {
 		system.Update(deltaTime, entitiesInterface, w.EventDispatcher)
 	}
 }
+
+// StorePreviousTransforms records every transform's position as the start of
+// a simulation step. Call it before each fixed step so that rendering can
+// interpolate between the previous and current step.
+func (w *World) StorePreviousTransforms() {
+	for _, transform := range Query[*components.Transform](w) {
+		transform.StorePrevious()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/entities"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/random"
 	"lbbaspack/engine/systems"
 	"testing"
 )
@@ -181,6 +182,60 @@ func TestWorld_AddSystem(t *testing.T) {
 	}
 }
 
+// randomMockSystem records the RNG handed to it
+type randomMockSystem struct {
+	mockSystem
+	rng *random.RNG
+}
+
+func (rms *randomMockSystem) SetRNG(rng *random.RNG) {
+	rms.rng = rng
+}
+
+// TestWorld_SeededRNG tests that the world seeds its RNG and hands it to systems
+func TestWorld_SeededRNG(t *testing.T) {
+	world := NewWorldWithSeed(1234)
+	if world.RNG == nil || world.RNG.Seed() != 1234 {
+		t.Fatal("Expected world RNG to use the given seed")
+	}
+
+	system := &randomMockSystem{}
+	world.AddSystem(system)
+	if system.rng != world.RNG {
+		t.Error("Expected AddSystem to hand the world RNG to random users")
+	}
+
+	if NewWorld().RNG == nil {
+		t.Error("Expected unseeded world to have an RNG")
+	}
+}
+
+// TestWorld_StorePreviousTransforms tests that a step's start position is kept for interpolation
+func TestWorld_StorePreviousTransforms(t *testing.T) {
+	world := NewWorld()
+	entity := world.NewEntity()
+	transform := components.NewTransform(0, 100)
+	entity.AddComponent(transform)
+
+	world.StorePreviousTransforms()
+	transform.SetPosition(10, 80)
+
+	x, y := transform.GetInterpolatedPosition(0.5)
+	if x != 5 || y != 90 {
+		t.Errorf("Expected halfway position (5,90), got (%f,%f)", x, y)
+	}
+
+	world.StorePreviousTransforms()
+	x, y = transform.GetInterpolatedPosition(0)
+	if x != 10 || y != 80 {
+		t.Errorf("Expected previous position to advance to (10,80), got (%f,%f)", x, y)
+	}
+
+	if world.Clock == nil || world.Clock.Step() != DefaultStep {
+		t.Error("Expected world to own a clock with the default step")
+	}
+}
+
 // TestWorld_Update tests the Update method
 func TestWorld_Update(t *testing.T) {
 	world := NewWorld()
@@ -1,16 +1,37 @@
 package entities
 
 import (
-	"fmt"
 	"lbbaspack/engine/components"
+	"lbbaspack/engine/logging"
 	"sync"
 )
 
+var entityLog = logging.For("entity")
+
+// Storage is an external component store that can back an entity in place of
+// its own Components map. ecs.World installs one for every entity it owns so
+// that components live in the world's archetype tables.
+type Storage interface {
+	AddComponent(id uint64, component components.Component)
+	GetComponent(id uint64, componentType string) components.Component
+	HasComponent(id uint64, componentType string) bool
+	RemoveComponent(id uint64, componentType string)
+	ComponentNames(id uint64) []string
+}
+
+// ActivityTracker is implemented by storages that want to be told when an
+// entity is activated or deactivated
+type ActivityTracker interface {
+	ActiveChanged(id uint64, active bool)
+}
+
 // Entity represents a game object with components
 type Entity struct {
 	ID         uint64
 	Components map[string]components.Component
 	Active     bool
+	storage    Storage
+	handle     components.EntityHandle
 	mu         sync.RWMutex
 }
 
@@ -28,44 +49,138 @@ func (e *Entity) AddComponent(component components.Component) {
 	if component == nil {
 		return
 	}
+	// Storage calls happen outside the lock, since storages may notify
+	// observers that read the entity back
 	e.mu.Lock()
-	defer e.mu.Unlock()
-	e.Components[component.GetType()] = component
-	fmt.Printf("[Entity] Added component %s to entity %d\n", component.GetType(), e.ID)
+	storage := e.storage
+	if storage == nil {
+		e.Components[component.GetType()] = component
+	}
+	e.mu.Unlock()
+	if storage != nil {
+		storage.AddComponent(e.ID, component)
+	}
+	entityLog.Debug("Added component", "component", component.GetType(), "entity", e.ID)
 }
 
 // GetComponent retrieves a component by type
 func (e *Entity) GetComponent(componentType string) components.Component {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	if e.storage != nil {
+		return e.storage.GetComponent(e.ID, componentType)
+	}
 	return e.Components[componentType]
 }
 
 // GetComponentByName implements systems.Entity interface for backward compatibility
 func (e *Entity) GetComponentByName(typeName string) components.Component {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-	return e.Components[typeName]
+	return e.GetComponent(typeName)
 }
 
 // HasComponent checks if entity has a specific component
 func (e *Entity) HasComponent(componentType string) bool {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	if e.storage != nil {
+		return e.storage.HasComponent(e.ID, componentType)
+	}
 	_, exists := e.Components[componentType]
 	return exists
 }
 
 // RemoveComponent removes a component from the entity
 func (e *Entity) RemoveComponent(componentType string) {
+	e.mu.Lock()
+	storage := e.storage
+	if storage == nil {
+		delete(e.Components, componentType)
+	}
+	e.mu.Unlock()
+	if storage != nil {
+		storage.RemoveComponent(e.ID, componentType)
+	}
+}
+
+// AttachStorage moves the entity's components into the given storage and
+// routes all further component access through it.
+func (e *Entity) AttachStorage(storage Storage) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	delete(e.Components, componentType)
+	if e.storage != nil || storage == nil {
+		return
+	}
+	for _, component := range e.Components {
+		storage.AddComponent(e.ID, component)
+	}
+	clear(e.Components)
+	e.storage = storage
+}
+
+// DetachStorage copies the entity's components back from its storage into its
+// own map, so the entity stays usable after it has been removed from a world.
+func (e *Entity) DetachStorage() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.storage == nil {
+		return
+	}
+	for _, name := range e.storage.ComponentNames(e.ID) {
+		if component := e.storage.GetComponent(e.ID, name); component != nil {
+			e.Components[name] = component
+		}
+	}
+	e.storage = nil
+}
+
+// HasStorage reports whether the entity's components live in an external storage
+func (e *Entity) HasStorage() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.storage != nil
+}
+
+// Reset clears the entity for reuse under a new ID: it becomes active, loses
+// its components, storage and handle, and keeps its map's allocation
+func (e *Entity) Reset(id uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ID = id
+	clear(e.Components)
+	e.Active = true
+	e.storage = nil
+	e.handle = components.EntityHandle{}
+}
+
+// Handle returns the generational handle the entity's world issued for it,
+// or the zero handle if it was never added to a world
+func (e *Entity) Handle() components.EntityHandle {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.handle
+}
+
+// SetHandle records the handle issued for the entity. It is called by the
+// world that owns the entity.
+func (e *Entity) SetHandle(handle components.EntityHandle) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handle = handle
 }
 
 // SetActive sets the entity's active state
 func (e *Entity) SetActive(active bool) {
+	changed := e.Active != active
 	e.Active = active
+	if !changed {
+		return
+	}
+	e.mu.RLock()
+	tracker, ok := e.storage.(ActivityTracker)
+	e.mu.RUnlock()
+	if ok {
+		tracker.ActiveChanged(e.ID, active)
+	}
 }
 
 // IsActive returns the entity's active state
@@ -82,6 +197,9 @@ func (e *Entity) GetID() uint64 {
 func (e *Entity) GetComponentNames() []string {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	if e.storage != nil {
+		return e.storage.ComponentNames(e.ID)
+	}
 	names := make([]string, 0, len(e.Components))
 	for t := range e.Components {
 		names = append(names, t)
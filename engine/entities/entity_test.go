@@ -627,6 +627,82 @@ func TestEntity_Integration(t *testing.T) {
 	}
 }
 
+// mapStorage is a minimal Storage implementation for testing
+type mapStorage struct {
+	data map[uint64]map[string]components.Component
+}
+
+func newMapStorage() *mapStorage {
+	return &mapStorage{data: make(map[uint64]map[string]components.Component)}
+}
+
+func (ms *mapStorage) AddComponent(id uint64, component components.Component) {
+	if ms.data[id] == nil {
+		ms.data[id] = make(map[string]components.Component)
+	}
+	ms.data[id][component.GetType()] = component
+}
+
+func (ms *mapStorage) GetComponent(id uint64, componentType string) components.Component {
+	return ms.data[id][componentType]
+}
+
+func (ms *mapStorage) HasComponent(id uint64, componentType string) bool {
+	_, exists := ms.data[id][componentType]
+	return exists
+}
+
+func (ms *mapStorage) RemoveComponent(id uint64, componentType string) {
+	delete(ms.data[id], componentType)
+}
+
+func (ms *mapStorage) ComponentNames(id uint64) []string {
+	names := make([]string, 0, len(ms.data[id]))
+	for name := range ms.data[id] {
+		names = append(names, name)
+	}
+	return names
+}
+
+// TestEntity_AttachDetachStorage tests routing component access through an external storage
+func TestEntity_AttachDetachStorage(t *testing.T) {
+	entity := NewEntity(1)
+	first := &testMockComponent{componentType: "First"}
+	entity.AddComponent(first)
+
+	storage := newMapStorage()
+	entity.AttachStorage(storage)
+
+	if !entity.HasStorage() {
+		t.Fatal("Expected entity to report attached storage")
+	}
+	if len(entity.Components) != 0 {
+		t.Error("Expected components to move out of the entity map")
+	}
+	if storage.GetComponent(1, "First") != first {
+		t.Error("Expected existing component to move into storage")
+	}
+
+	second := &testMockComponent{componentType: "Second"}
+	entity.AddComponent(second)
+	if !storage.HasComponent(1, "Second") || entity.GetComponent("Second") != second {
+		t.Error("Expected new components to be written to storage")
+	}
+
+	entity.RemoveComponent("First")
+	if entity.HasComponent("First") {
+		t.Error("Expected removal to go through storage")
+	}
+
+	entity.DetachStorage()
+	if entity.HasStorage() {
+		t.Error("Expected storage to be detached")
+	}
+	if entity.Components["Second"] != second || len(entity.Components) != 1 {
+		t.Errorf("Expected components to be copied back on detach, got %v", entity.GetComponentNames())
+	}
+}
+
 // Benchmark tests for performance
 func BenchmarkNewEntity(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -665,3 +741,40 @@ func BenchmarkEntity_HasComponent(b *testing.B) {
 		entity.HasComponent("TestComponent")
 	}
 }
+
+// TestEntity_Handle tests that an entity keeps the handle issued for it
+func TestEntity_Handle(t *testing.T) {
+	entity := NewEntity(1)
+	if entity.Handle().IsValid() {
+		t.Errorf("Expected a new entity to have the zero handle, got %s", entity.Handle())
+	}
+
+	handle := components.EntityHandle{Index: 3, Generation: 2}
+	entity.SetHandle(handle)
+	if entity.Handle() != handle {
+		t.Errorf("Expected handle %s, got %s", handle, entity.Handle())
+	}
+}
+
+// TestEntity_Reset tests that a reset entity is ready for reuse under a new ID
+func TestEntity_Reset(t *testing.T) {
+	entity := NewEntity(1)
+	entity.AddComponent(&testMockComponent{componentType: "TestComponent"})
+	entity.SetHandle(components.EntityHandle{Index: 1, Generation: 1})
+	entity.SetActive(false)
+
+	entity.Reset(7)
+
+	if entity.ID != 7 {
+		t.Errorf("Expected ID 7, got %d", entity.ID)
+	}
+	if !entity.IsActive() {
+		t.Error("Expected a reset entity to be active")
+	}
+	if entity.HasComponent("TestComponent") || len(entity.Components) != 0 {
+		t.Errorf("Expected no components after reset, got %v", entity.GetComponentNames())
+	}
+	if entity.Handle().IsValid() {
+		t.Errorf("Expected the zero handle after reset, got %s", entity.Handle())
+	}
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens to an event published while an
+// asynchronous subscriber's channel is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the subscriber to make room, stalling the publisher
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered event to make room
+	OverflowDropOldest
+	// OverflowDropNewest discards the event being published
+	OverflowDropNewest
+)
+
+// AsyncSubscriber receives events on a buffered channel, so that code outside
+// the game loop can consume them at its own pace. The channel is closed when
+// the subscriber is unsubscribed.
+type AsyncSubscriber[T any] struct {
+	subscription *Subscription
+	events       chan T
+	done         chan struct{}
+	policy       OverflowPolicy
+	dropped      atomic.Uint64
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// SubscribeAsync delivers the payload of every event of type T published on
+// bus to a channel buffering up to buffer payloads
+func SubscribeAsync[T Payload](bus Subscriber, buffer int, policy OverflowPolicy) *AsyncSubscriber[T] {
+	async := newAsyncSubscriber[T](buffer, policy)
+	async.attach(Subscribe(bus, async.deliver))
+	return async
+}
+
+// SubscribeEvents delivers every event of eventType published on bus to a
+// channel buffering up to buffer events. Receivers must not modify the events.
+func SubscribeEvents(bus Subscriber, eventType EventType, buffer int, policy OverflowPolicy) *AsyncSubscriber[*Event] {
+	async := newAsyncSubscriber[*Event](buffer, policy)
+	async.attach(bus.SubscribeWithPriority(eventType, 0, async.deliver))
+	return async
+}
+
+// newAsyncSubscriber creates an asynchronous subscriber that is not
+// subscribed yet
+func newAsyncSubscriber[T any](buffer int, policy OverflowPolicy) *AsyncSubscriber[T] {
+	if buffer < 1 {
+		buffer = 1
+	}
+	return &AsyncSubscriber[T]{
+		events: make(chan T, buffer),
+		done:   make(chan struct{}),
+		policy: policy,
+	}
+}
+
+// attach ties the subscriber to its subscription, closing the channel once
+// the subscription is removed
+func (as *AsyncSubscriber[T]) attach(subscription *Subscription) {
+	as.subscription = subscription
+	subscription.OnUnsubscribe(as.close)
+}
+
+// C returns the channel events are delivered on
+func (as *AsyncSubscriber[T]) C() <-chan T {
+	return as.events
+}
+
+// Dropped returns the number of events discarded because the channel was full
+func (as *AsyncSubscriber[T]) Dropped() uint64 {
+	return as.dropped.Load()
+}
+
+// Unsubscribe stops delivery and closes the channel. Events already buffered
+// can still be received.
+func (as *AsyncSubscriber[T]) Unsubscribe() {
+	as.subscription.Unsubscribe()
+}
+
+// deliver sends value to the channel according to the overflow policy
+func (as *AsyncSubscriber[T]) deliver(value T) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	if as.closed {
+		return
+	}
+
+	switch as.policy {
+	case OverflowDropNewest:
+		select {
+		case as.events <- value:
+		default:
+			as.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case as.events <- value:
+				return
+			default:
+			}
+			select {
+			case <-as.events:
+				as.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		// Unsubscribing releases a publisher blocked on a full channel
+		select {
+		case as.events <- value:
+		case <-as.done:
+		}
+	}
+}
+
+// close stops delivery and closes the channel
+func (as *AsyncSubscriber[T]) close() {
+	close(as.done)
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.closed = true
+	close(as.events)
+}
@@ -0,0 +1,191 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSubscribeAsync_OverflowPolicies tests what each policy does with events published to a full channel
+func TestSubscribeAsync_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy   OverflowPolicy
+		expected []int
+	}{
+		{OverflowDropOldest, []int{3, 4}},
+		{OverflowDropNewest, []int{1, 2}},
+	}
+	for _, test := range tests {
+		bus := NewEventDispatcher()
+		async := SubscribeAsync[LevelUp](bus, 2, test.policy)
+		for level := 1; level <= 4; level++ {
+			Publish(bus, LevelUp{Level: level})
+		}
+		async.Unsubscribe()
+
+		var received []int
+		for levelUp := range async.C() {
+			received = append(received, levelUp.Level)
+		}
+		if len(received) != 2 || received[0] != test.expected[0] || received[1] != test.expected[1] {
+			t.Errorf("Policy %d: expected %v, got %v", test.policy, test.expected, received)
+		}
+		if async.Dropped() != 2 {
+			t.Errorf("Policy %d: expected 2 dropped events, got %d", test.policy, async.Dropped())
+		}
+	}
+}
+
+// TestSubscribeAsync_Block tests that a blocked publisher resumes once the consumer reads or unsubscribes
+func TestSubscribeAsync_Block(t *testing.T) {
+	bus := NewEventDispatcher()
+	async := SubscribeEvents(bus, EventPacketLost, 1, OverflowBlock)
+	Publish(bus, PacketLost{Score: 1})
+
+	published := make(chan struct{})
+	go func() {
+		Publish(bus, PacketLost{Score: 2})
+		Publish(bus, PacketLost{Score: 3})
+		close(published)
+	}()
+
+	first := <-async.C()
+	if first.Payload.(PacketLost).Score != 1 {
+		t.Errorf("Expected the first event first, got %+v", first.Payload)
+	}
+	// The second event fills the channel again and the third publish blocks
+	// until the subscriber goes away
+	for deadline := time.Now().Add(time.Second); len(async.events) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	async.Unsubscribe()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Unsubscribe to release the blocked publisher")
+	}
+	if second, ok := <-async.C(); !ok || second.Payload.(PacketLost).Score != 2 {
+		t.Errorf("Expected the buffered event to survive Unsubscribe, got %+v", second)
+	}
+	if _, ok := <-async.C(); ok {
+		t.Error("Expected the channel to be closed")
+	}
+}
+
+// TestSubscribeAsync_Scope tests that closing a scope closes its asynchronous subscribers
+func TestSubscribeAsync_Scope(t *testing.T) {
+	bus := NewEventDispatcher()
+	scope := bus.NewScope()
+	async := SubscribeAsync[GameOver](scope, 4, OverflowDropNewest)
+	scope.Close()
+
+	Publish(bus, GameOver{})
+	if _, ok := <-async.C(); ok {
+		t.Error("Expected the channel to be closed with the scope")
+	}
+}
+
+// TestEventDispatcher_Concurrent tests publishing and subscribing from several goroutines at once
+func TestEventDispatcher_Concurrent(t *testing.T) {
+	bus := NewEventDispatcher()
+	bus.SetMode(DispatchQueued)
+	var mu sync.Mutex
+	delivered := 0
+	Subscribe(bus, func(PacketCaught) {
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Publish(bus, PacketCaught{Score: i})
+				Subscribe(bus, func(PacketLost) {}).Unsubscribe()
+			}
+		}()
+	}
+	wg.Wait()
+	bus.Flush()
+
+	if delivered != 800 {
+		t.Errorf("Expected 800 deliveries, got %d", delivered)
+	}
+	if _, exists := bus.handlers[EventPacketLost]; exists {
+		t.Error("Expected every temporary handler to be removed")
+	}
+}
+
+// TestEventDispatcher_ConcurrentImmediate tests that immediate publishers on several goroutines do not share a nesting depth
+func TestEventDispatcher_ConcurrentImmediate(t *testing.T) {
+	bus := NewEventDispatcher()
+	bus.SetMaxDepth(2)
+	var delivered atomic.Int64
+	Subscribe(bus, func(caught PacketCaught) {
+		delivered.Add(1)
+		// Nest once, well within the limit, while other goroutines publish
+		if caught.Score >= 0 {
+			PublishImmediate(bus, PacketCaught{Score: -1})
+		}
+	})
+
+	// The same event built without a payload, which is decoded on publish,
+	// from every goroutine at once
+	shared := &Event{Type: EventPacketLost, Data: &EventData{}}
+	Subscribe(bus, func(PacketLost) {})
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				PublishImmediate(bus, PacketCaught{Score: i})
+				bus.PublishImmediate(shared)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := delivered.Load(); got != 8*200*2 {
+		t.Errorf("Expected %d deliveries, got %d", 8*200*2, got)
+	}
+	if depth := bus.current(); depth != 0 {
+		t.Errorf("Expected the depth back at 0, got %d", depth)
+	}
+	if inflight := bus.inflight.Load(); inflight != 0 {
+		t.Errorf("Expected no goroutine left inside a dispatch, got %d", inflight)
+	}
+}
+
+// TestEventDispatcher_PanickingHandler tests that a handler panic does not leave the goroutine nested
+func TestEventDispatcher_PanickingHandler(t *testing.T) {
+	bus := NewEventDispatcher()
+	bus.SetMaxDepth(0)
+	panics := true
+	delivered := 0
+	Subscribe(bus, func(PacketLost) {
+		delivered++
+		if panics {
+			panic("handler failed")
+		}
+	})
+
+	func() {
+		defer func() { recover() }()
+		PublishImmediate(bus, PacketLost{})
+	}()
+	if depth := bus.current(); depth != 0 {
+		t.Errorf("Expected the depth back at 0 after the panic, got %d", depth)
+	}
+
+	panics = false
+	PublishImmediate(bus, PacketLost{})
+	if delivered != 2 {
+		t.Errorf("Expected the next event delivered at the top level, got %d deliveries", delivered)
+	}
+}
@@ -1,6 +1,17 @@
 package events
 
-import "time"
+import (
+	"bytes"
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/logging"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var eventsLog = logging.For("events")
 
 // EventType represents different types of events
 type EventType string
@@ -19,12 +30,31 @@ const (
 	EventDDoSStart        EventType = "ddos_start"
 	EventDDoSEnd          EventType = "ddos_end"
 	EventPacketDelivered  EventType = "packet_delivered"
+	EventComboAchieved    EventType = "combo_achieved"
+
+	// Lifecycle events published by the world. Entity carries the entity's
+	// handle, EntityID its ID and, for component events, Component the
+	// component type. The handle of a destroyed entity still resolves while
+	// EventEntityDestroyed is being dispatched.
+	EventEntityCreated    EventType = "entity_created"
+	EventEntityDestroyed  EventType = "entity_destroyed"
+	EventComponentAdded   EventType = "component_added"
+	EventComponentRemoved EventType = "component_removed"
 )
 
-// EventData represents typed event data
+// AnyEvent is the event type to subscribe to for events of every type. Its
+// handlers run with the handlers of each event's own type, by priority.
+const AnyEvent EventType = "*"
+
+// EventData is the data of events published with NewEvent, every field
+// being optional.
+//
+// Deprecated: publish and subscribe to payload types with Publish and
+// Subscribe instead. EventData stays available to subscribers while they
+// migrate.
 type EventData struct {
 	Score       *int
-	Packet      interface{} // Entity type
+	Packet      components.EntityHandle
 	Powerup     *string
 	Level       *int
 	Time        *float64
@@ -41,25 +71,35 @@ type EventData struct {
 	SLA         *float64
 	Errors      *int
 	BackendID   *int
+	Entity      components.EntityHandle
+	EntityID    *uint64
+	Component   *string
 }
 
-// Event represents a game event
+// Event represents a game event. Events published with Publish carry both a
+// Payload and the matching Data; events created with NewEvent are given their
+// Payload when they are published.
 type Event struct {
 	Type      EventType
 	Timestamp time.Time
 	Data      *EventData
+	Payload   Payload
 }
 
-// NewEvent creates a new event
+// NewEvent creates a new event, with the payload of the built-in event types
+// decoded from data
 func NewEvent(eventType EventType, data *EventData) *Event {
 	return &Event{
 		Type:      eventType,
 		Timestamp: time.Now(),
 		Data:      data,
+		Payload:   decodeLegacy(eventType, data),
 	}
 }
 
 // NewEventWithMap creates a new event from a map (for backward compatibility)
+//
+// Deprecated: publish payload types with Publish instead.
 func NewEventWithMap(eventType EventType, data map[string]interface{}) *Event {
 	eventData := &EventData{}
 
@@ -67,7 +107,7 @@ func NewEventWithMap(eventType EventType, data map[string]interface{}) *Event {
 	if score, ok := data["score"].(int); ok {
 		eventData.Score = &score
 	}
-	if packet, ok := data["packet"]; ok {
+	if packet, ok := data["packet"].(components.EntityHandle); ok {
 		eventData.Packet = packet
 	}
 	if powerup, ok := data["powerup"].(string); ok {
@@ -115,6 +155,15 @@ func NewEventWithMap(eventType EventType, data map[string]interface{}) *Event {
 	if errors, ok := data["errors"].(int); ok {
 		eventData.Errors = &errors
 	}
+	if entity, ok := data["entity"].(components.EntityHandle); ok {
+		eventData.Entity = entity
+	}
+	if entityID, ok := data["entity_id"].(uint64); ok {
+		eventData.EntityID = &entityID
+	}
+	if component, ok := data["component"].(string); ok {
+		eventData.Component = &component
+	}
 
 	return NewEvent(eventType, eventData)
 }
@@ -122,31 +171,355 @@ func NewEventWithMap(eventType EventType, data map[string]interface{}) *Event {
 // EventHandler is a function that handles events
 type EventHandler func(*Event)
 
-// EventDispatcher manages event handling
+// DispatchMode controls when published events reach their handlers
+type DispatchMode int
+
+const (
+	// DispatchImmediate runs handlers inside Publish
+	DispatchImmediate DispatchMode = iota
+	// DispatchQueued holds published events until Flush
+	DispatchQueued
+)
+
+// DefaultMaxDepth is how deeply events published from handlers may nest
+// before they are dropped
+const DefaultMaxDepth = 16
+
+// queuedEvent is an event waiting for Flush, with how deeply it is nested
+// in the handlers of other events
+type queuedEvent struct {
+	event *Event
+	depth int
+}
+
+// EventDispatcher manages event handling. In queued mode, events are held
+// until Flush and delivered in the order they were published; events
+// published by handlers during a flush are delivered in the same flush,
+// after the events already queued.
+//
+// Publish and Subscribe are safe to call from any goroutine. Handlers run on
+// the goroutine that publishes or flushes, so listeners outside the game loop
+// should use queued mode or SubscribeAsync.
 type EventDispatcher struct {
-	handlers map[EventType][]EventHandler
+	handlersMu sync.RWMutex
+	handlers   map[EventType][]*Subscription
+	middleware []*middlewareEntry
+
+	mu       sync.Mutex
+	mode     DispatchMode
+	maxDepth int
+	queue    []queuedEvent
+	flushing bool
+
+	// depths holds, for each goroutine inside a dispatch, the depth of the
+	// events it publishes now: one more than the event being dispatched.
+	// inflight counts those dispatches, so that publishing outside any
+	// dispatch, as the game loop does, skips looking the depth up.
+	depths   sync.Map
+	inflight atomic.Int64
 }
 
-// NewEventDispatcher creates a new event dispatcher
+// NewEventDispatcher creates a new event dispatcher in immediate mode
 func NewEventDispatcher() *EventDispatcher {
 	return &EventDispatcher{
-		handlers: make(map[EventType][]EventHandler),
+		handlers: make(map[EventType][]*Subscription),
+		maxDepth: DefaultMaxDepth,
+	}
+}
+
+// SetMode sets when published events reach their handlers. Events already
+// queued stay queued until Flush.
+func (ed *EventDispatcher) SetMode(mode DispatchMode) {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	ed.mode = mode
+}
+
+// Mode returns the dispatch mode
+func (ed *EventDispatcher) Mode() DispatchMode {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	return ed.mode
+}
+
+// SetMaxDepth sets how deeply events published from handlers may nest.
+// Deeper events are dropped, which stops handlers that publish each other's
+// events from looping forever.
+func (ed *EventDispatcher) SetMaxDepth(depth int) {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	ed.maxDepth = depth
+}
+
+// Subscribe adds an event handler with priority 0
+func (ed *EventDispatcher) Subscribe(eventType EventType, handler EventHandler) *Subscription {
+	return ed.SubscribeWithPriority(eventType, 0, handler)
+}
+
+// SubscribeWithPriority adds an event handler. Handlers with a higher
+// priority run first; handlers with the same priority run in the order they
+// were subscribed.
+func (ed *EventDispatcher) SubscribeWithPriority(eventType EventType, priority int, handler EventHandler) *Subscription {
+	added := &Subscription{dispatcher: ed, eventType: eventType, priority: priority, handler: handler}
+
+	ed.handlersMu.Lock()
+	defer ed.handlersMu.Unlock()
+	subscriptions := ed.handlers[eventType]
+	index := len(subscriptions)
+	for i, existing := range subscriptions {
+		if existing.priority < priority {
+			index = i
+			break
+		}
 	}
+	subscriptions = append(subscriptions, nil)
+	copy(subscriptions[index+1:], subscriptions[index:])
+	subscriptions[index] = added
+	ed.handlers[eventType] = subscriptions
+	return added
 }
 
-// Subscribe adds an event handler
-func (ed *EventDispatcher) Subscribe(eventType EventType, handler EventHandler) {
-	ed.handlers[eventType] = append(ed.handlers[eventType], handler)
+// unsubscribe removes a handler, reporting false if it was already removed.
+// The handler list is replaced rather than changed in place, so a dispatch in
+// progress is not disturbed.
+func (ed *EventDispatcher) unsubscribe(removed *Subscription) bool {
+	ed.handlersMu.Lock()
+	defer ed.handlersMu.Unlock()
+	if removed.removed.Load() {
+		return false
+	}
+	removed.removed.Store(true)
+
+	subscriptions := ed.handlers[removed.eventType]
+	remaining := make([]*Subscription, 0, len(subscriptions))
+	for _, existing := range subscriptions {
+		if existing != removed {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(ed.handlers, removed.eventType)
+	} else {
+		ed.handlers[removed.eventType] = remaining
+	}
+	return true
 }
 
-// Publish sends an event to all subscribers
+// Publish sends an event to all subscribers, right away in immediate mode or
+// at the next Flush in queued mode
 func (ed *EventDispatcher) Publish(event *Event) {
+	ed.publish(event, false)
+}
+
+// PublishImmediate sends an event to all subscribers right away, whatever the
+// mode. It is meant for input that must take effect before the frame goes on,
+// such as menu selections.
+func (ed *EventDispatcher) PublishImmediate(event *Event) {
+	ed.publish(event, true)
+}
+
+// publish delivers or queues event. An event built without NewEvent is copied
+// before its payload is decoded, so the caller's event is never changed.
+func (ed *EventDispatcher) publish(event *Event, immediate bool) {
 	if event == nil {
 		return
 	}
-	if handlers, exists := ed.handlers[event.Type]; exists {
-		for _, handler := range handlers {
-			handler(event)
+	if event.Payload == nil {
+		if payload := decodeLegacy(event.Type, event.Data); payload != nil {
+			decoded := *event
+			decoded.Payload = payload
+			event = &decoded
 		}
 	}
+
+	depth := ed.current()
+	ed.mu.Lock()
+	if depth > ed.maxDepth {
+		ed.mu.Unlock()
+		eventsLog.Warn("Dropping nested event", "type", event.Type, "depth", depth)
+		return
+	}
+	if ed.mode == DispatchQueued && !immediate {
+		ed.queue = append(ed.queue, queuedEvent{event: event, depth: depth})
+		ed.mu.Unlock()
+		return
+	}
+	ed.mu.Unlock()
+
+	ed.dispatch(event, depth)
+}
+
+// dispatch runs the handlers of event, which is nested depth deep. The depth
+// is kept per goroutine, since handlers run on the goroutine that dispatches
+// and publishers on other goroutines are not nested in them.
+func (ed *EventDispatcher) dispatch(event *Event, depth int) {
+	id := goroutineID()
+	previous, nested := ed.depths.Load(id)
+	ed.depths.Store(id, depth+1)
+	ed.inflight.Add(1)
+	// Restored even if a handler panics, so the goroutine's later events
+	// are not taken for nested ones
+	defer func() {
+		if nested {
+			ed.depths.Store(id, previous)
+		} else {
+			ed.depths.Delete(id)
+		}
+		ed.inflight.Add(-1)
+	}()
+
+	ed.handlersMu.RLock()
+	middleware := ed.middleware
+	ed.handlersMu.RUnlock()
+	ed.runMiddleware(middleware, event)
+}
+
+// current returns the depth of events the calling goroutine publishes now
+func (ed *EventDispatcher) current() int {
+	if ed.inflight.Load() == 0 {
+		return 0
+	}
+	if depth, ok := ed.depths.Load(goroutineID()); ok {
+		return depth.(int)
+	}
+	return 0
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the header
+// of its stack trace
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if end := bytes.IndexByte(header, ' '); end >= 0 {
+		header = header[:end]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
+
+// deliver runs the handlers of event
+func (ed *EventDispatcher) deliver(event *Event) {
+	ed.handlersMu.RLock()
+	subscriptions := ed.handlers[event.Type]
+	if event.Type != AnyEvent {
+		subscriptions = mergeByPriority(subscriptions, ed.handlers[AnyEvent])
+	}
+	ed.handlersMu.RUnlock()
+
+	for _, subscription := range subscriptions {
+		// Skip handlers unsubscribed since the dispatch started
+		if !subscription.removed.Load() {
+			subscription.handler(event)
+		}
+	}
+}
+
+// mergeByPriority merges two handler lists sorted by priority, taking
+// handlers from typed first when priorities are equal
+func mergeByPriority(typed, wildcard []*Subscription) []*Subscription {
+	if len(wildcard) == 0 {
+		return typed
+	}
+	if len(typed) == 0 {
+		return wildcard
+	}
+	merged := make([]*Subscription, 0, len(typed)+len(wildcard))
+	for len(typed) > 0 && len(wildcard) > 0 {
+		if wildcard[0].priority > typed[0].priority {
+			merged = append(merged, wildcard[0])
+			wildcard = wildcard[1:]
+		} else {
+			merged = append(merged, typed[0])
+			typed = typed[1:]
+		}
+	}
+	merged = append(merged, typed...)
+	return append(merged, wildcard...)
+}
+
+// Flush delivers the queued events in the order they were published,
+// including events that their handlers publish. Calling Flush from a handler
+// during a flush does nothing, the outer flush delivers the rest.
+func (ed *EventDispatcher) Flush() {
+	ed.mu.Lock()
+	if ed.flushing {
+		ed.mu.Unlock()
+		return
+	}
+	ed.flushing = true
+	ed.mu.Unlock()
+
+	for {
+		ed.mu.Lock()
+		if len(ed.queue) == 0 {
+			ed.queue = nil
+			ed.flushing = false
+			ed.mu.Unlock()
+			return
+		}
+		next := ed.queue[0]
+		ed.queue[0] = queuedEvent{}
+		ed.queue = ed.queue[1:]
+		ed.mu.Unlock()
+
+		ed.dispatch(next.event, next.depth)
+	}
+}
+
+// Pending returns the number of events waiting for Flush
+func (ed *EventDispatcher) Pending() int {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	return len(ed.queue)
+}
+
+// HasSubscribers reports whether an event of eventType would reach anything:
+// a handler for the type or for AnyEvent, or middleware, which sees every
+// event. Publishers check it to skip building events nobody receives.
+func (ed *EventDispatcher) HasSubscribers(eventType EventType) bool {
+	ed.handlersMu.RLock()
+	defer ed.handlersMu.RUnlock()
+	return len(ed.handlers[eventType]) > 0 || len(ed.handlers[AnyEvent]) > 0 || len(ed.middleware) > 0
+}
+
+// Subscribe calls handler with the payload of every event of type T published
+// on bus, whether it was published with Publish or NewEvent
+func Subscribe[T Payload](bus Subscriber, handler func(T)) *Subscription {
+	return SubscribeWithPriority(bus, 0, handler)
+}
+
+// SubscribeWithPriority is Subscribe with the handler priority of
+// EventDispatcher.SubscribeWithPriority
+func SubscribeWithPriority[T Payload](bus Subscriber, priority int, handler func(T)) *Subscription {
+	var zero T
+	return bus.SubscribeWithPriority(zero.EventType(), priority, func(event *Event) {
+		if payload, ok := event.Payload.(T); ok {
+			handler(payload)
+		}
+	})
+}
+
+// Publish sends payload to the subscribers of its event type on bus.
+// Subscribers registered with EventDispatcher.Subscribe read the built-in
+// payloads through Event.Data.
+func Publish[T Payload](bus *EventDispatcher, payload T) {
+	bus.Publish(NewPayloadEvent(payload))
+}
+
+// PublishImmediate is Publish that delivers payload right away, like
+// EventDispatcher.PublishImmediate
+func PublishImmediate[T Payload](bus *EventDispatcher, payload T) {
+	bus.PublishImmediate(NewPayloadEvent(payload))
+}
+
+// NewPayloadEvent creates the event that carries payload, with the matching
+// Data for subscribers that have not moved to Subscribe yet
+func NewPayloadEvent(payload Payload) *Event {
+	event := NewEvent(payload.EventType(), &EventData{})
+	if legacy, ok := payload.(legacyPayload); ok {
+		event.Data = legacy.legacyData()
+	}
+	event.Payload = payload
+	return event
 }
@@ -1,6 +1,8 @@
 package events
 
 import (
+	"fmt"
+	"lbbaspack/engine/components"
 	"testing"
 	"time"
 )
@@ -18,6 +20,10 @@ func TestEventTypeConstants(t *testing.T) {
 		EventLevelUp,
 		EventDDoSStart,
 		EventDDoSEnd,
+		EventEntityCreated,
+		EventEntityDestroyed,
+		EventComponentAdded,
+		EventComponentRemoved,
 	}
 
 	for _, eventType := range expectedTypes {
@@ -79,7 +85,7 @@ func TestNewEventWithMap(t *testing.T) {
 	t.Run("Complete Data Map", func(t *testing.T) {
 		data := map[string]interface{}{
 			"score":        100,
-			"packet":       "test_packet",
+			"packet":       components.EntityHandle{Index: 7, Generation: 2},
 			"powerup":      "shield",
 			"level":        3,
 			"time":         45.5,
@@ -111,8 +117,8 @@ func TestNewEventWithMap(t *testing.T) {
 		if *event.Data.Score != 100 {
 			t.Errorf("Expected score 100, got %d", *event.Data.Score)
 		}
-		if event.Data.Packet != "test_packet" {
-			t.Errorf("Expected packet 'test_packet', got %v", event.Data.Packet)
+		if event.Data.Packet != (components.EntityHandle{Index: 7, Generation: 2}) {
+			t.Errorf("Expected packet handle 7:2, got %v", event.Data.Packet)
 		}
 		if *event.Data.Powerup != "shield" {
 			t.Errorf("Expected powerup 'shield', got %s", *event.Data.Powerup)
@@ -250,6 +256,26 @@ func TestNewEventWithMap(t *testing.T) {
 			t.Error("Expected time to be nil for invalid type")
 		}
 	})
+
+	t.Run("Lifecycle Data Map", func(t *testing.T) {
+		data := map[string]interface{}{
+			"entity":    components.EntityHandle{Index: 3, Generation: 1},
+			"entity_id": uint64(42),
+			"component": "Transform",
+		}
+
+		event := NewEventWithMap(EventComponentAdded, data)
+
+		if event.Data.Entity != (components.EntityHandle{Index: 3, Generation: 1}) {
+			t.Errorf("Expected entity handle 3:1, got %v", event.Data.Entity)
+		}
+		if event.Data.EntityID == nil || *event.Data.EntityID != 42 {
+			t.Errorf("Expected entity ID 42, got %v", event.Data.EntityID)
+		}
+		if event.Data.Component == nil || *event.Data.Component != "Transform" {
+			t.Errorf("Expected component 'Transform', got %v", event.Data.Component)
+		}
+	})
 }
 
 // TestNewEventDispatcher tests the NewEventDispatcher function
@@ -665,3 +691,288 @@ func BenchmarkEventDispatcher_Publish(b *testing.B) {
 		dispatcher.Publish(event)
 	}
 }
+
+// customEvent is a payload type defined outside the built-in events
+type customEvent struct {
+	Value int
+}
+
+func (customEvent) EventType() EventType { return "custom" }
+
+// TestPublishSubscribe tests typed publishing and subscribing
+func TestPublishSubscribe(t *testing.T) {
+	bus := NewEventDispatcher()
+	var caught []PacketCaught
+	Subscribe(bus, func(event PacketCaught) {
+		caught = append(caught, event)
+	})
+	lost := 0
+	Subscribe(bus, func(PacketLost) {
+		lost++
+	})
+
+	handle := components.EntityHandle{Index: 3, Generation: 2}
+	Publish(bus, PacketCaught{Packet: handle, Score: 40})
+	if len(caught) != 1 || caught[0].Packet != handle || caught[0].Score != 40 {
+		t.Errorf("Expected the published payload, got %+v", caught)
+	}
+	if lost != 0 {
+		t.Error("Expected subscribers of other event types not to be called")
+	}
+
+	var custom []int
+	Subscribe(bus, func(event customEvent) {
+		custom = append(custom, event.Value)
+	})
+	Publish(bus, customEvent{Value: 7})
+	if len(custom) != 1 || custom[0] != 7 {
+		t.Errorf("Expected custom payloads to be delivered, got %v", custom)
+	}
+}
+
+// TestPublish_LegacySubscribers tests that subscribers reading EventData receive typed events
+func TestPublish_LegacySubscribers(t *testing.T) {
+	bus := NewEventDispatcher()
+	var received *Event
+	bus.Subscribe(EventSLAUpdated, func(event *Event) {
+		received = event
+	})
+
+	Publish(bus, SLAUpdated{Current: 97.5, Caught: 39, Lost: 1, Remaining: 9, Budget: 10})
+	if received == nil || received.Data == nil {
+		t.Fatal("Expected the legacy subscriber to receive event data")
+	}
+	if *received.Data.Current != 97.5 || *received.Data.Caught != 39 || *received.Data.Remaining != 9 {
+		t.Errorf("Unexpected event data %+v", received.Data)
+	}
+	if received.Data.Target != nil {
+		t.Error("Expected an unknown target to be left out")
+	}
+	if _, ok := received.Payload.(SLAUpdated); !ok {
+		t.Errorf("Expected the typed payload on the event, got %T", received.Payload)
+	}
+
+	var custom *Event
+	bus.Subscribe("custom", func(event *Event) {
+		custom = event
+	})
+	Publish(bus, customEvent{Value: 1})
+	if custom == nil || custom.Data == nil {
+		t.Error("Expected custom payloads to carry empty event data")
+	}
+}
+
+// TestSubscribe_LegacyPublishers tests that typed subscribers receive events created with NewEvent
+func TestSubscribe_LegacyPublishers(t *testing.T) {
+	bus := NewEventDispatcher()
+	var levels []LevelUp
+	Subscribe(bus, func(event LevelUp) {
+		levels = append(levels, event)
+	})
+	var combos []ComboAchieved
+	Subscribe(bus, func(event ComboAchieved) {
+		combos = append(combos, event)
+	})
+
+	level, elapsed := 4, 12.5
+	bus.Publish(NewEvent(EventLevelUp, &EventData{Level: &level, Time: &elapsed}))
+	bus.Publish(NewEvent(EventLevelUp, nil))
+	bus.Publish(&Event{Type: EventComboAchieved})
+
+	if len(levels) != 2 || levels[0] != (LevelUp{Level: 4, Time: 12.5}) || levels[1] != (LevelUp{}) {
+		t.Errorf("Expected converted level ups, got %+v", levels)
+	}
+	if len(combos) != 1 {
+		t.Errorf("Expected events without data to be delivered, got %+v", combos)
+	}
+}
+
+// TestEventDispatcher_QueuedMode tests that queued events wait for Flush and keep their order
+func TestEventDispatcher_QueuedMode(t *testing.T) {
+	bus := NewEventDispatcher()
+	bus.SetMode(DispatchQueued)
+	var received []string
+	Subscribe(bus, func(event PacketLost) {
+		received = append(received, fmt.Sprintf("lost %d", event.Score))
+		// Events published by handlers are delivered after the ones already queued
+		Publish(bus, SLAUpdated{Lost: event.Score})
+	})
+	Subscribe(bus, func(event SLAUpdated) {
+		received = append(received, fmt.Sprintf("sla %d", event.Lost))
+	})
+	Subscribe(bus, func(GameOver) {
+		received = append(received, "game over")
+	})
+
+	Publish(bus, PacketLost{Score: 1})
+	Publish(bus, PacketLost{Score: 2})
+	Publish(bus, GameOver{})
+	if len(received) != 0 || bus.Pending() != 3 {
+		t.Fatalf("Expected 3 events to wait for Flush, got %v and %d pending", received, bus.Pending())
+	}
+
+	PublishImmediate(bus, GameOver{})
+	if len(received) != 1 {
+		t.Fatalf("Expected the immediate event to be delivered right away, got %v", received)
+	}
+
+	bus.Flush()
+	expected := []string{"game over", "lost 1", "lost 2", "game over", "sla 1", "sla 2"}
+	if fmt.Sprint(received) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, received)
+	}
+	if bus.Pending() != 0 {
+		t.Errorf("Expected an empty queue after Flush, got %d", bus.Pending())
+	}
+}
+
+// TestEventDispatcher_Priorities tests that handlers run by priority, then subscription order
+func TestEventDispatcher_Priorities(t *testing.T) {
+	bus := NewEventDispatcher()
+	var order []string
+	Subscribe(bus, func(Exit) { order = append(order, "first") })
+	SubscribeWithPriority(bus, 10, func(Exit) { order = append(order, "high") })
+	SubscribeWithPriority(bus, -1, func(Exit) { order = append(order, "low") })
+	Subscribe(bus, func(Exit) { order = append(order, "second") })
+	bus.SubscribeWithPriority(EventExit, 10, func(*Event) { order = append(order, "high legacy") })
+
+	Publish(bus, Exit{})
+	expected := []string{"high", "high legacy", "first", "second", "low"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+}
+
+// TestEventDispatcher_MaxDepth tests that handlers publishing each other's events stop at the depth limit
+func TestEventDispatcher_MaxDepth(t *testing.T) {
+	for _, mode := range []DispatchMode{DispatchImmediate, DispatchQueued} {
+		bus := NewEventDispatcher()
+		bus.SetMode(mode)
+		bus.SetMaxDepth(3)
+		deliveries := 0
+		Subscribe(bus, func(DDoSStart) {
+			deliveries++
+			Publish(bus, DDoSStart{})
+		})
+
+		Publish(bus, DDoSStart{})
+		bus.Flush()
+		if deliveries != 4 {
+			t.Errorf("Expected 4 deliveries in mode %d, got %d", mode, deliveries)
+		}
+
+		// The depth is counted from each event published outside a handler
+		Publish(bus, DDoSStart{})
+		bus.Flush()
+		if deliveries != 8 {
+			t.Errorf("Expected the depth to reset in mode %d, got %d deliveries", mode, deliveries)
+		}
+	}
+}
+
+// TestSubscription_Unsubscribe tests that unsubscribed handlers stop receiving events
+func TestSubscription_Unsubscribe(t *testing.T) {
+	bus := NewEventDispatcher()
+	var calls []string
+	var second *Subscription
+	first := Subscribe(bus, func(LevelUp) {
+		calls = append(calls, "first")
+		// Unsubscribing a later handler skips it for the event being dispatched
+		second.Unsubscribe()
+	})
+	second = Subscribe(bus, func(LevelUp) {
+		calls = append(calls, "second")
+	})
+
+	Publish(bus, LevelUp{})
+	if fmt.Sprint(calls) != "[first]" {
+		t.Errorf("Expected only the first handler, got %v", calls)
+	}
+	if second.Active() || !first.Active() {
+		t.Error("Expected only the second handler to be unsubscribed")
+	}
+
+	first.Unsubscribe()
+	first.Unsubscribe()
+	Publish(bus, LevelUp{})
+	if len(calls) != 1 {
+		t.Errorf("Expected no more calls, got %v", calls)
+	}
+	if _, exists := bus.handlers[EventLevelUp]; exists {
+		t.Error("Expected the event type to have no handlers left")
+	}
+}
+
+// TestScope_Close tests that closing a scope removes all of its handlers and nothing else
+func TestScope_Close(t *testing.T) {
+	bus := NewEventDispatcher()
+	scope := bus.NewScope()
+	scoped, unscoped := 0, 0
+	Subscribe(scope, func(GameOver) { scoped++ })
+	scope.Subscribe(EventGameStart, func(*Event) { scoped++ })
+	scope.Add(Subscribe(bus, func(ReturnToMenu) { scoped++ }))
+	Subscribe(bus, func(GameOver) { unscoped++ })
+	if scope.Len() != 3 {
+		t.Fatalf("Expected 3 handlers in the scope, got %d", scope.Len())
+	}
+
+	scope.Close()
+	Publish(bus, GameOver{})
+	Publish(bus, GameStart{})
+	Publish(bus, ReturnToMenu{})
+	if scoped != 0 || unscoped != 1 {
+		t.Errorf("Expected only the unscoped handler to run, got %d scoped and %d unscoped calls", scoped, unscoped)
+	}
+
+	// A closed scope can be used again
+	Subscribe(scope, func(GameOver) { scoped++ })
+	Publish(bus, GameOver{})
+	if scoped != 1 || scope.Len() != 1 {
+		t.Errorf("Expected the reused scope to work, got %d calls", scoped)
+	}
+}
+
+// TestEventDispatcher_HasSubscribers tests which event types are reported as received
+func TestEventDispatcher_HasSubscribers(t *testing.T) {
+	bus := NewEventDispatcher()
+	if bus.HasSubscribers(EventExit) {
+		t.Error("Expected no subscribers on a new dispatcher")
+	}
+
+	subscription := Subscribe(bus, func(Exit) {})
+	if !bus.HasSubscribers(EventExit) || bus.HasSubscribers(EventDDoSEnd) {
+		t.Error("Expected subscribers only for the subscribed type")
+	}
+	subscription.Unsubscribe()
+
+	wildcard := bus.Subscribe(AnyEvent, func(*Event) {})
+	if !bus.HasSubscribers(EventDDoSEnd) {
+		t.Error("Expected AnyEvent handlers to receive every type")
+	}
+	wildcard.Unsubscribe()
+
+	remove := bus.Use(Logger())
+	if !bus.HasSubscribers(EventDDoSEnd) {
+		t.Error("Expected middleware to receive every type")
+	}
+	remove()
+	if bus.HasSubscribers(EventExit) {
+		t.Error("Expected no subscribers once everything is removed")
+	}
+}
+
+// TestEventDispatcher_AnyEvent tests that wildcard handlers run with typed handlers by priority
+func TestEventDispatcher_AnyEvent(t *testing.T) {
+	bus := NewEventDispatcher()
+	var order []string
+	bus.Subscribe(AnyEvent, func(event *Event) { order = append(order, "any "+string(event.Type)) })
+	Subscribe(bus, func(Exit) { order = append(order, "exit") })
+	bus.SubscribeWithPriority(AnyEvent, 5, func(*Event) { order = append(order, "first") })
+
+	Publish(bus, Exit{})
+	Publish(bus, DDoSEnd{})
+	expected := []string{"first", "exit", "any exit", "first", "any ddos_end"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// FrameClock reports how far the simulation has advanced. Implemented by
+// ecs.Clock.
+type FrameClock interface {
+	// Steps returns the number of fixed steps run so far
+	Steps() uint64
+	// Step returns the length of a fixed step in seconds
+	Step() float64
+}
+
+// JournalEntry is one line of an event journal
+type JournalEntry struct {
+	Frame   uint64          `json:"frame"`
+	Time    float64         `json:"time"`
+	Type    EventType       `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Recorder writes every event delivered by a dispatcher to an event journal,
+// one JSON entry per line
+type Recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	clock   FrameClock
+	remove  func()
+	entries int
+	err     error
+}
+
+// NewRecorder creates a recorder writing to w. Entries are stamped with the
+// frame and simulation time of clock, which may be nil.
+func NewRecorder(w io.Writer, clock FrameClock) *Recorder {
+	return &Recorder{encoder: json.NewEncoder(w), clock: clock}
+}
+
+// Record starts recording the events delivered on bus. The recorder runs as
+// the first middleware, so it sees each event before other middleware can
+// swallow it and before any handler, and events published by handlers follow
+// the event that caused them.
+func (r *Recorder) Record(bus *EventDispatcher) {
+	remove := bus.UseFirst(func(event *Event, next EventHandler) {
+		r.write(event)
+		next(event)
+	})
+	r.mu.Lock()
+	r.remove = remove
+	r.mu.Unlock()
+}
+
+// write appends event to the journal
+func (r *Recorder) write(event *Event) {
+	entry := JournalEntry{Type: event.Type}
+	if r.clock != nil {
+		entry.Frame = r.clock.Steps()
+		entry.Time = float64(entry.Frame) * r.clock.Step()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if event.Payload != nil {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			r.err = fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+			return
+		}
+		entry.Payload = payload
+	}
+	if err := r.encoder.Encode(entry); err != nil {
+		r.err = fmt.Errorf("failed to write journal entry: %w", err)
+		return
+	}
+	r.entries++
+}
+
+// Entries returns the number of entries written
+func (r *Recorder) Entries() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.entries
+}
+
+// Close stops recording and returns the first error the recorder ran into.
+// Recording stops at the first error.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	remove := r.remove
+	r.remove = nil
+	r.mu.Unlock()
+
+	if remove != nil {
+		remove()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReadJournal reads the entries of an event journal
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	decoder := json.NewDecoder(r)
+	var entries []JournalEntry
+	for {
+		var entry JournalEntry
+		if err := decoder.Decode(&entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("failed to read journal entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// Event rebuilds the event recorded in entry. Entries of event types without
+// a registered payload are given no payload.
+func (entry JournalEntry) Event() (*Event, error) {
+	event := NewEvent(entry.Type, nil)
+	if len(entry.Payload) == 0 {
+		return event, nil
+	}
+	payload, exists, err := DecodePayload(entry.Type, entry.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s payload: %w", entry.Type, err)
+	}
+	if exists {
+		event.Payload = payload
+		if legacy, ok := payload.(legacyPayload); ok {
+			event.Data = legacy.legacyData()
+		}
+	}
+	return event, nil
+}
+
+// Player replays an event journal into a dispatcher
+type Player struct {
+	entries []JournalEntry
+	// Speed scales the pace of replay: 1 keeps the recorded timing, 2 replays
+	// twice as fast, and 0 or less replays without waiting
+	Speed float64
+	sleep func(time.Duration)
+}
+
+// NewPlayer creates a player for entries at the recorded speed
+func NewPlayer(entries []JournalEntry) *Player {
+	return &Player{entries: entries, Speed: 1, sleep: time.Sleep}
+}
+
+// Replay publishes the recorded events to bus in order, waiting between them
+// as long as the simulation time between them divided by Speed. Each event is
+// delivered immediately, whatever the mode of bus.
+func (p *Player) Replay(bus *EventDispatcher) error {
+	for i, entry := range p.entries {
+		if i > 0 && p.Speed > 0 {
+			if wait := (entry.Time - p.entries[i-1].Time) / p.Speed; wait > 0 {
+				p.sleep(time.Duration(wait * float64(time.Second)))
+			}
+		}
+		event, err := entry.Event()
+		if err != nil {
+			return fmt.Errorf("failed to replay entry %d: %w", i+1, err)
+		}
+		bus.PublishImmediate(event)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"bytes"
+	"lbbaspack/engine/components"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stepClock is a FrameClock whose step count is set by the test
+type stepClock struct {
+	steps uint64
+}
+
+func (sc *stepClock) Steps() uint64 { return sc.steps }
+func (sc *stepClock) Step() float64 { return 0.5 }
+
+// TestRecorder_Journal tests that recorded events are written as NDJSON and read back
+func TestRecorder_Journal(t *testing.T) {
+	bus := NewEventDispatcher()
+	clock := &stepClock{}
+	var journal bytes.Buffer
+	recorder := NewRecorder(&journal, clock)
+	recorder.Record(bus)
+
+	// Events published by handlers are recorded after the event that caused them
+	Subscribe(bus, func(lost PacketLost) {
+		Publish(bus, SLAUpdated{Current: 50, Lost: 1})
+	})
+
+	Publish(bus, PacketCaught{Packet: components.EntityHandle{Index: 3, Generation: 2}, Score: 10})
+	clock.steps = 4
+	Publish(bus, PacketLost{Score: 10})
+	bus.Publish(&Event{Type: "custom"})
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	Publish(bus, GameOver{})
+
+	lines := strings.Split(strings.TrimSpace(journal.String()), "\n")
+	if len(lines) != 4 || recorder.Entries() != 4 {
+		t.Fatalf("Expected 4 journal lines, got %d:\n%s", len(lines), journal.String())
+	}
+	expected := `{"frame":0,"time":0,"type":"packet_caught","payload":{"packet":{"index":3,"generation":2},"score":10}}`
+	if lines[0] != expected {
+		t.Errorf("Expected %s, got %s", expected, lines[0])
+	}
+
+	entries, err := ReadJournal(&journal)
+	if err != nil {
+		t.Fatalf("ReadJournal failed: %v", err)
+	}
+	types := []EventType{EventPacketCaught, EventPacketLost, EventSLAUpdated, "custom"}
+	for i, entry := range entries {
+		if entry.Type != types[i] {
+			t.Errorf("Entry %d: expected %s, got %s", i, types[i], entry.Type)
+		}
+	}
+	if entries[2].Frame != 4 || entries[2].Time != 2 {
+		t.Errorf("Expected frame 4 at 2s, got frame %d at %v", entries[2].Frame, entries[2].Time)
+	}
+}
+
+// TestRecorder_Swallowed tests that events swallowed by middleware added before recording are still recorded
+func TestRecorder_Swallowed(t *testing.T) {
+	bus := NewEventDispatcher()
+	bus.Use(Swallow(EventGameOver))
+	var journal bytes.Buffer
+	recorder := NewRecorder(&journal, nil)
+	recorder.Record(bus)
+
+	delivered := 0
+	Subscribe(bus, func(GameOver) { delivered++ })
+	Publish(bus, GameOver{})
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if delivered != 0 {
+		t.Errorf("Expected the game over swallowed, got %d deliveries", delivered)
+	}
+	if recorder.Entries() != 1 || !strings.Contains(journal.String(), `"type":"game_over"`) {
+		t.Errorf("Expected the swallowed game over recorded, got:\n%s", journal.String())
+	}
+}
+
+// TestPlayer_Replay tests that a journal replays into a fresh dispatcher with its timing
+func TestPlayer_Replay(t *testing.T) {
+	journal := strings.Join([]string{
+		`{"frame":0,"time":0,"type":"level_up","payload":{"level":2,"time":30}}`,
+		`{"frame":60,"time":1,"type":"sla_updated","payload":{"current":99.5,"caught":199,"lost":1,"remaining":9,"budget":10}}`,
+		`{"frame":180,"time":3,"type":"custom"}`,
+	}, "\n")
+	entries, err := ReadJournal(strings.NewReader(journal))
+	if err != nil {
+		t.Fatalf("ReadJournal failed: %v", err)
+	}
+
+	bus := NewEventDispatcher()
+	bus.SetMode(DispatchQueued)
+	var received []string
+	Subscribe(bus, func(levelUp LevelUp) {
+		received = append(received, "level")
+		if levelUp != (LevelUp{Level: 2, Time: 30}) {
+			t.Errorf("Unexpected level up %+v", levelUp)
+		}
+	})
+	bus.Subscribe(EventSLAUpdated, func(event *Event) {
+		received = append(received, "sla")
+		if event.Data == nil || *event.Data.Caught != 199 {
+			t.Errorf("Expected legacy data on the replayed event, got %+v", event.Data)
+		}
+	})
+	bus.Subscribe("custom", func(event *Event) {
+		received = append(received, "custom")
+	})
+
+	player := NewPlayer(entries)
+	player.Speed = 2
+	var waits []time.Duration
+	player.sleep = func(wait time.Duration) {
+		waits = append(waits, wait)
+	}
+	if err := player.Replay(bus); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if strings.Join(received, " ") != "level sla custom" {
+		t.Errorf("Expected every event in order, got %v", received)
+	}
+	if len(waits) != 2 || waits[0] != 500*time.Millisecond || waits[1] != time.Second {
+		t.Errorf("Expected waits halved by speed 2, got %v", waits)
+	}
+}
+
+// TestReadJournal_Invalid tests that a malformed line is reported
+func TestReadJournal_Invalid(t *testing.T) {
+	if _, err := ReadJournal(strings.NewReader("{\"type\":\"exit\"}\nnot json\n")); err == nil {
+		t.Error("Expected an error for a malformed entry")
+	}
+}
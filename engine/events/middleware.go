@@ -0,0 +1,159 @@
+package events
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Middleware sees every event before its handlers do. It passes the event on
+// by calling next, possibly with a changed or different event, or swallows it
+// by not calling next.
+type Middleware func(event *Event, next EventHandler)
+
+// middlewareEntry is a middleware added with Use
+type middlewareEntry struct {
+	middleware Middleware
+	removed    atomic.Bool
+}
+
+// Use adds middleware to the dispatcher. Middleware runs in the order it was
+// added, each one passing the event on to the next, when the event is
+// delivered: inside Publish in immediate mode, or during Flush in queued
+// mode. The returned function removes the middleware again.
+func (ed *EventDispatcher) Use(middleware Middleware) (remove func()) {
+	return ed.use(middleware, false)
+}
+
+// UseFirst adds middleware that runs before all middleware added so far, so
+// that it sees every event, including those later middleware swallows or
+// changes
+func (ed *EventDispatcher) UseFirst(middleware Middleware) (remove func()) {
+	return ed.use(middleware, true)
+}
+
+// use adds middleware at the start or the end of the list
+func (ed *EventDispatcher) use(middleware Middleware, first bool) (remove func()) {
+	entry := &middlewareEntry{middleware: middleware}
+
+	ed.handlersMu.Lock()
+	if first {
+		ed.middleware = append([]*middlewareEntry{entry}, ed.middleware...)
+	} else {
+		ed.middleware = append(ed.middleware, entry)
+	}
+	ed.handlersMu.Unlock()
+
+	return func() {
+		ed.handlersMu.Lock()
+		defer ed.handlersMu.Unlock()
+		if entry.removed.Swap(true) {
+			return
+		}
+		// Replace the list rather than change it, like handler lists
+		remaining := make([]*middlewareEntry, 0, len(ed.middleware))
+		for _, existing := range ed.middleware {
+			if existing != entry {
+				remaining = append(remaining, existing)
+			}
+		}
+		ed.middleware = remaining
+	}
+}
+
+// runMiddleware passes event through middleware and then to its handlers
+func (ed *EventDispatcher) runMiddleware(middleware []*middlewareEntry, event *Event) {
+	for len(middleware) > 0 && middleware[0].removed.Load() {
+		middleware = middleware[1:]
+	}
+	if len(middleware) == 0 {
+		ed.deliver(event)
+		return
+	}
+	middleware[0].middleware(event, func(next *Event) {
+		if next != nil {
+			ed.runMiddleware(middleware[1:], next)
+		}
+	})
+}
+
+// Logger is middleware that logs every event with its payload at info level
+func Logger() Middleware {
+	return func(event *Event, next EventHandler) {
+		if event.Payload != nil {
+			eventsLog.Info("Event", "type", event.Type, "payload", event.Payload)
+		} else {
+			eventsLog.Info("Event", "type", event.Type)
+		}
+		next(event)
+	}
+}
+
+// Filter is middleware that swallows the events for which drop returns true
+func Filter(drop func(event *Event) bool) Middleware {
+	return func(event *Event, next EventHandler) {
+		if !drop(event) {
+			next(event)
+		}
+	}
+}
+
+// Swallow is middleware that swallows every event of the given types
+func Swallow(eventTypes ...EventType) Middleware {
+	swallowed := make(map[EventType]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		swallowed[eventType] = true
+	}
+	return Filter(func(event *Event) bool {
+		return swallowed[event.Type]
+	})
+}
+
+// Counter counts the events that pass through it by type
+type Counter struct {
+	mu     sync.Mutex
+	counts map[EventType]uint64
+}
+
+// NewCounter creates an event counter
+func NewCounter() *Counter {
+	return &Counter{counts: make(map[EventType]uint64)}
+}
+
+// Middleware returns the middleware that counts events. Events swallowed by
+// middleware added before it are not counted.
+func (c *Counter) Middleware() Middleware {
+	return func(event *Event, next EventHandler) {
+		c.mu.Lock()
+		c.counts[event.Type]++
+		c.mu.Unlock()
+		next(event)
+	}
+}
+
+// Count returns the number of events of eventType counted
+func (c *Counter) Count(eventType EventType) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[eventType]
+}
+
+// Counts returns the counted event types and their counts, in type order
+func (c *Counter) Counts() []EventCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make([]EventCount, 0, len(c.counts))
+	for eventType, count := range c.counts {
+		counts = append(counts, EventCount{Type: eventType, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Type < counts[j].Type
+	})
+	return counts
+}
+
+// EventCount is the number of events of one type counted by a Counter
+type EventCount struct {
+	Type  EventType
+	Count uint64
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestEventDispatcher_Use tests that middleware runs in order and can change or swallow events
+func TestEventDispatcher_Use(t *testing.T) {
+	bus := NewEventDispatcher()
+	var order []string
+	bus.Use(func(event *Event, next EventHandler) {
+		order = append(order, "outer")
+		next(event)
+		order = append(order, "outer done")
+	})
+	removeSwallow := bus.Use(Swallow(EventGameOver))
+	// Double the score of caught packets
+	bus.Use(func(event *Event, next EventHandler) {
+		if caught, ok := event.Payload.(PacketCaught); ok {
+			caught.Score *= 2
+			event = NewPayloadEvent(caught)
+		}
+		next(event)
+	})
+
+	var scores []int
+	Subscribe(bus, func(caught PacketCaught) {
+		order = append(order, "handler")
+		scores = append(scores, caught.Score)
+	})
+	gameOvers := 0
+	Subscribe(bus, func(GameOver) { gameOvers++ })
+
+	Publish(bus, PacketCaught{Score: 5})
+	Publish(bus, GameOver{})
+	if fmt.Sprint(order) != "[outer handler outer done outer outer done]" {
+		t.Errorf("Unexpected middleware order %v", order)
+	}
+	if len(scores) != 1 || scores[0] != 10 {
+		t.Errorf("Expected the changed score, got %v", scores)
+	}
+	if gameOvers != 0 {
+		t.Error("Expected game over to be swallowed")
+	}
+
+	removeSwallow()
+	removeSwallow()
+	Publish(bus, GameOver{})
+	if gameOvers != 1 {
+		t.Error("Expected game over to pass once the filter is removed")
+	}
+}
+
+// TestCounter tests that the counter counts events by type in queued mode
+func TestCounter(t *testing.T) {
+	bus := NewEventDispatcher()
+	bus.SetMode(DispatchQueued)
+	counter := NewCounter()
+	bus.Use(Swallow(EventExit))
+	bus.Use(counter.Middleware())
+
+	Publish(bus, PacketLost{})
+	Publish(bus, PacketLost{})
+	Publish(bus, LevelUp{})
+	Publish(bus, Exit{})
+	if counter.Count(EventPacketLost) != 0 {
+		t.Error("Expected queued events to be counted when they are delivered")
+	}
+
+	bus.Flush()
+	counts := counter.Counts()
+	expected := []EventCount{{EventLevelUp, 1}, {EventPacketLost, 2}}
+	if fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}
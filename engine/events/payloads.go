@@ -0,0 +1,373 @@
+package events
+
+import (
+	"encoding/json"
+	"lbbaspack/engine/components"
+	"sync"
+)
+
+// Payload is the data of one type of event. Every event type has its own
+// payload struct, which names the event type it is published as.
+type Payload interface {
+	EventType() EventType
+}
+
+// legacyPayload is implemented by the built-in payloads, which convert to the
+// EventData read by subscribers that have not moved to Subscribe yet
+type legacyPayload interface {
+	Payload
+	legacyData() *EventData
+}
+
+// PacketCaught is published when the load balancer catches a packet
+type PacketCaught struct {
+	Packet components.EntityHandle `json:"packet"`
+	Score  int                     `json:"score"`
+}
+
+// PacketLost is published when a packet falls off the screen
+type PacketLost struct {
+	Score int `json:"score"`
+}
+
+// PowerUpCollected is published when the load balancer catches a power-up
+type PowerUpCollected struct {
+	PowerUp components.EntityHandle `json:"powerup"`
+	Name    string                  `json:"name"`
+}
+
+// PowerUpActivated is published when a collected power-up takes effect
+type PowerUpActivated struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration"`
+}
+
+// GameStart is published when a game starts from the menu. A zero SLA or
+// Errors keeps the current target SLA or error budget.
+type GameStart struct {
+	Mode   int     `json:"mode"`
+	SLA    float64 `json:"sla"`
+	Errors int     `json:"errors"`
+}
+
+// GameOver is published when the error budget runs out
+type GameOver struct {
+	Caught int `json:"caught"`
+	Lost   int `json:"lost"`
+}
+
+// ReturnToMenu is published when the player leaves a game for the menu
+type ReturnToMenu struct{}
+
+// Exit is published when the player quits the game
+type Exit struct{}
+
+// SLAUpdated is published whenever the SLA changes. Target is zero when the
+// publisher does not track the target SLA.
+type SLAUpdated struct {
+	Current   float64 `json:"current"`
+	Target    float64 `json:"target"`
+	Caught    int     `json:"caught"`
+	Lost      int     `json:"lost"`
+	Remaining int     `json:"remaining"`
+	Budget    int     `json:"budget"`
+}
+
+// LevelUp is published when the player reaches a new level
+type LevelUp struct {
+	Level int     `json:"level"`
+	Time  float64 `json:"time"`
+}
+
+// DDoSStart is published when a DDoS attack starts
+type DDoSStart struct {
+	Duration float64 `json:"duration"`
+	Level    int     `json:"level"`
+}
+
+// DDoSEnd is published when a DDoS attack ends
+type DDoSEnd struct{}
+
+// PacketDelivered is published when a routed packet reaches its backend
+type PacketDelivered struct {
+	BackendID int `json:"backend_id"`
+}
+
+// ComboAchieved is published for every packet caught during a combo
+type ComboAchieved struct {
+	Count       int `json:"count"`
+	BonusPoints int `json:"bonus_points"`
+}
+
+// EntityCreated is published when an entity joins a world
+type EntityCreated struct {
+	Entity   components.EntityHandle `json:"entity"`
+	EntityID uint64                  `json:"entity_id"`
+}
+
+// EntityDestroyed is published when an entity leaves a world. Its handle
+// still resolves while the event is being dispatched.
+type EntityDestroyed struct {
+	Entity   components.EntityHandle `json:"entity"`
+	EntityID uint64                  `json:"entity_id"`
+}
+
+// ComponentAdded is published when a component is added to an entity in a world
+type ComponentAdded struct {
+	Entity    components.EntityHandle `json:"entity"`
+	EntityID  uint64                  `json:"entity_id"`
+	Component string                  `json:"component"`
+}
+
+// ComponentRemoved is published when a component is removed from an entity in a world
+type ComponentRemoved struct {
+	Entity    components.EntityHandle `json:"entity"`
+	EntityID  uint64                  `json:"entity_id"`
+	Component string                  `json:"component"`
+}
+
+func (PacketCaught) EventType() EventType     { return EventPacketCaught }
+func (PacketLost) EventType() EventType       { return EventPacketLost }
+func (PowerUpCollected) EventType() EventType { return EventPowerUpCollected }
+func (PowerUpActivated) EventType() EventType { return EventPowerUpActivated }
+func (GameStart) EventType() EventType        { return EventGameStart }
+func (GameOver) EventType() EventType         { return EventGameOver }
+func (ReturnToMenu) EventType() EventType     { return EventReturnToMenu }
+func (Exit) EventType() EventType             { return EventExit }
+func (SLAUpdated) EventType() EventType       { return EventSLAUpdated }
+func (LevelUp) EventType() EventType          { return EventLevelUp }
+func (DDoSStart) EventType() EventType        { return EventDDoSStart }
+func (DDoSEnd) EventType() EventType          { return EventDDoSEnd }
+func (PacketDelivered) EventType() EventType  { return EventPacketDelivered }
+func (ComboAchieved) EventType() EventType    { return EventComboAchieved }
+func (EntityCreated) EventType() EventType    { return EventEntityCreated }
+func (EntityDestroyed) EventType() EventType  { return EventEntityDestroyed }
+func (ComponentAdded) EventType() EventType   { return EventComponentAdded }
+func (ComponentRemoved) EventType() EventType { return EventComponentRemoved }
+
+func (p PacketCaught) legacyData() *EventData {
+	return &EventData{Packet: p.Packet, Score: &p.Score}
+}
+
+func (p PacketLost) legacyData() *EventData {
+	return &EventData{Score: &p.Score}
+}
+
+func (p PowerUpCollected) legacyData() *EventData {
+	return &EventData{Packet: p.PowerUp, Powerup: &p.Name}
+}
+
+func (p PowerUpActivated) legacyData() *EventData {
+	return &EventData{Powerup: &p.Name, Duration: &p.Duration}
+}
+
+func (p GameStart) legacyData() *EventData {
+	data := &EventData{Mode: &p.Mode}
+	if p.SLA != 0 {
+		data.SLA = &p.SLA
+	}
+	if p.Errors != 0 {
+		data.Errors = &p.Errors
+	}
+	return data
+}
+
+func (p GameOver) legacyData() *EventData {
+	return &EventData{Score: &p.Caught, Lost: &p.Lost}
+}
+
+func (ReturnToMenu) legacyData() *EventData { return &EventData{} }
+func (Exit) legacyData() *EventData         { return &EventData{} }
+func (DDoSEnd) legacyData() *EventData      { return &EventData{} }
+
+func (p SLAUpdated) legacyData() *EventData {
+	data := &EventData{
+		Current:   &p.Current,
+		Caught:    &p.Caught,
+		Lost:      &p.Lost,
+		Remaining: &p.Remaining,
+		Budget:    &p.Budget,
+	}
+	if p.Target != 0 {
+		data.Target = &p.Target
+	}
+	return data
+}
+
+func (p LevelUp) legacyData() *EventData {
+	return &EventData{Level: &p.Level, Time: &p.Time}
+}
+
+func (p DDoSStart) legacyData() *EventData {
+	return &EventData{Duration: &p.Duration, Level: &p.Level}
+}
+
+func (p PacketDelivered) legacyData() *EventData {
+	return &EventData{BackendID: &p.BackendID}
+}
+
+func (p ComboAchieved) legacyData() *EventData {
+	return &EventData{ComboCount: &p.Count, BonusPoints: &p.BonusPoints}
+}
+
+func (p EntityCreated) legacyData() *EventData {
+	return &EventData{Entity: p.Entity, EntityID: &p.EntityID}
+}
+
+func (p EntityDestroyed) legacyData() *EventData {
+	return &EventData{Entity: p.Entity, EntityID: &p.EntityID}
+}
+
+func (p ComponentAdded) legacyData() *EventData {
+	return &EventData{Entity: p.Entity, EntityID: &p.EntityID, Component: &p.Component}
+}
+
+func (p ComponentRemoved) legacyData() *EventData {
+	return &EventData{Entity: p.Entity, EntityID: &p.EntityID, Component: &p.Component}
+}
+
+// legacyDecoders build the payload of events published with NewEvent, so
+// that Subscribe handlers receive them too. Fields missing from the
+// EventData are left at their zero value.
+var legacyDecoders = map[EventType]func(data *EventData) Payload{
+	EventPacketCaught: func(data *EventData) Payload {
+		return PacketCaught{Packet: data.Packet, Score: value(data.Score)}
+	},
+	EventPacketLost: func(data *EventData) Payload {
+		return PacketLost{Score: value(data.Score)}
+	},
+	EventPowerUpCollected: func(data *EventData) Payload {
+		return PowerUpCollected{PowerUp: data.Packet, Name: value(data.Powerup)}
+	},
+	EventPowerUpActivated: func(data *EventData) Payload {
+		return PowerUpActivated{Name: value(data.Powerup), Duration: value(data.Duration)}
+	},
+	EventGameStart: func(data *EventData) Payload {
+		return GameStart{Mode: value(data.Mode), SLA: value(data.SLA), Errors: value(data.Errors)}
+	},
+	EventGameOver: func(data *EventData) Payload {
+		return GameOver{Caught: value(data.Score), Lost: value(data.Lost)}
+	},
+	EventReturnToMenu: func(data *EventData) Payload {
+		return ReturnToMenu{}
+	},
+	EventExit: func(data *EventData) Payload {
+		return Exit{}
+	},
+	EventSLAUpdated: func(data *EventData) Payload {
+		return SLAUpdated{
+			Current:   value(data.Current),
+			Target:    value(data.Target),
+			Caught:    value(data.Caught),
+			Lost:      value(data.Lost),
+			Remaining: value(data.Remaining),
+			Budget:    value(data.Budget),
+		}
+	},
+	EventLevelUp: func(data *EventData) Payload {
+		return LevelUp{Level: value(data.Level), Time: value(data.Time)}
+	},
+	EventDDoSStart: func(data *EventData) Payload {
+		return DDoSStart{Duration: value(data.Duration), Level: value(data.Level)}
+	},
+	EventDDoSEnd: func(data *EventData) Payload {
+		return DDoSEnd{}
+	},
+	EventPacketDelivered: func(data *EventData) Payload {
+		return PacketDelivered{BackendID: value(data.BackendID)}
+	},
+	EventComboAchieved: func(data *EventData) Payload {
+		return ComboAchieved{Count: value(data.ComboCount), BonusPoints: value(data.BonusPoints)}
+	},
+	EventEntityCreated: func(data *EventData) Payload {
+		return EntityCreated{Entity: data.Entity, EntityID: value(data.EntityID)}
+	},
+	EventEntityDestroyed: func(data *EventData) Payload {
+		return EntityDestroyed{Entity: data.Entity, EntityID: value(data.EntityID)}
+	},
+	EventComponentAdded: func(data *EventData) Payload {
+		return ComponentAdded{Entity: data.Entity, EntityID: value(data.EntityID), Component: value(data.Component)}
+	},
+	EventComponentRemoved: func(data *EventData) Payload {
+		return ComponentRemoved{Entity: data.Entity, EntityID: value(data.EntityID), Component: value(data.Component)}
+	},
+}
+
+// decodeLegacy returns the payload for an event published with NewEvent, or
+// nil if its type has no built-in payload
+func decodeLegacy(eventType EventType, data *EventData) Payload {
+	decode, exists := legacyDecoders[eventType]
+	if !exists {
+		return nil
+	}
+	if data == nil {
+		data = &EventData{}
+	}
+	return decode(data)
+}
+
+// value dereferences an optional EventData field
+func value[T any](field *T) T {
+	var zero T
+	if field == nil {
+		return zero
+	}
+	return *field
+}
+
+// PayloadDecoder decodes the JSON encoding of a payload
+type PayloadDecoder func(data []byte) (Payload, error)
+
+var (
+	payloadTypesMu sync.RWMutex
+	payloadTypes   = map[EventType]PayloadDecoder{}
+)
+
+func init() {
+	RegisterPayload[PacketCaught]()
+	RegisterPayload[PacketLost]()
+	RegisterPayload[PowerUpCollected]()
+	RegisterPayload[PowerUpActivated]()
+	RegisterPayload[GameStart]()
+	RegisterPayload[GameOver]()
+	RegisterPayload[ReturnToMenu]()
+	RegisterPayload[Exit]()
+	RegisterPayload[SLAUpdated]()
+	RegisterPayload[LevelUp]()
+	RegisterPayload[DDoSStart]()
+	RegisterPayload[DDoSEnd]()
+	RegisterPayload[PacketDelivered]()
+	RegisterPayload[ComboAchieved]()
+	RegisterPayload[EntityCreated]()
+	RegisterPayload[EntityDestroyed]()
+	RegisterPayload[ComponentAdded]()
+	RegisterPayload[ComponentRemoved]()
+}
+
+// RegisterPayload lets payloads of type T be decoded from JSON, such as when
+// replaying a journal. Registering an event type again replaces its decoder.
+func RegisterPayload[T Payload]() {
+	var zero T
+	payloadTypesMu.Lock()
+	defer payloadTypesMu.Unlock()
+	payloadTypes[zero.EventType()] = func(data []byte) (Payload, error) {
+		var payload T
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, err
+		}
+		return payload, nil
+	}
+}
+
+// DecodePayload decodes the JSON encoding of a payload of eventType,
+// reporting false if no payload type is registered for it
+func DecodePayload(eventType EventType, data []byte) (Payload, bool, error) {
+	payloadTypesMu.RLock()
+	decode, exists := payloadTypes[eventType]
+	payloadTypesMu.RUnlock()
+	if !exists {
+		return nil, false, nil
+	}
+	payload, err := decode(data)
+	return payload, true, err
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Subscriber is anything event handlers can be subscribed to: a dispatcher,
+// or a scope that removes its handlers together
+type Subscriber interface {
+	SubscribeWithPriority(eventType EventType, priority int, handler EventHandler) *Subscription
+}
+
+// Subscription is a handler subscribed to a dispatcher
+type Subscription struct {
+	dispatcher *EventDispatcher
+	eventType  EventType
+	priority   int
+	handler    EventHandler
+	removed    atomic.Bool
+
+	mu            sync.Mutex
+	onUnsubscribe []func()
+}
+
+// Unsubscribe removes the handler from its dispatcher. The handler is not
+// called again, even for an event being dispatched. Calling Unsubscribe more
+// than once does nothing.
+func (s *Subscription) Unsubscribe() {
+	if s == nil || !s.dispatcher.unsubscribe(s) {
+		return
+	}
+	s.mu.Lock()
+	hooks := s.onUnsubscribe
+	s.onUnsubscribe = nil
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// OnUnsubscribe registers fn to run once the handler is unsubscribed, whether
+// directly or by closing its scope. fn runs right away if it already is.
+func (s *Subscription) OnUnsubscribe(fn func()) {
+	s.mu.Lock()
+	if s.removed.Load() {
+		s.mu.Unlock()
+		fn()
+		return
+	}
+	s.onUnsubscribe = append(s.onUnsubscribe, fn)
+	s.mu.Unlock()
+}
+
+// Active reports whether the handler is still subscribed
+func (s *Subscription) Active() bool {
+	return s != nil && !s.removed.Load()
+}
+
+// Scope groups handlers that are removed together, such as those of a game
+// session or a scene. A closed scope can be subscribed to again.
+type Scope struct {
+	dispatcher    *EventDispatcher
+	mu            sync.Mutex
+	subscriptions []*Subscription
+}
+
+// NewScope creates a scope for handlers subscribed to ed
+func (ed *EventDispatcher) NewScope() *Scope {
+	return &Scope{dispatcher: ed}
+}
+
+// Subscribe adds an event handler with priority 0 to the scope
+func (sc *Scope) Subscribe(eventType EventType, handler EventHandler) *Subscription {
+	return sc.SubscribeWithPriority(eventType, 0, handler)
+}
+
+// SubscribeWithPriority adds an event handler to the scope, like
+// EventDispatcher.SubscribeWithPriority
+func (sc *Scope) SubscribeWithPriority(eventType EventType, priority int, handler EventHandler) *Subscription {
+	return sc.Add(sc.dispatcher.SubscribeWithPriority(eventType, priority, handler))
+}
+
+// Add makes subscription part of the scope
+func (sc *Scope) Add(subscription *Subscription) *Subscription {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.subscriptions = append(sc.subscriptions, subscription)
+	return subscription
+}
+
+// Len returns the number of handlers in the scope
+func (sc *Scope) Len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return len(sc.subscriptions)
+}
+
+// Close removes every handler in the scope
+func (sc *Scope) Close() {
+	sc.mu.Lock()
+	subscriptions := sc.subscriptions
+	sc.subscriptions = nil
+	sc.mu.Unlock()
+
+	for _, subscription := range subscriptions {
+		subscription.Unsubscribe()
+	}
+}
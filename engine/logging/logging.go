@@ -0,0 +1,196 @@
+// Package logging gives each part of the game its own structured logger on
+// top of log/slog. Levels are set per subsystem, e.g. "spawn=debug,sla=info",
+// and records are written as text or JSON.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Environment variables read by Init when no flag is given
+const (
+	EnvLevels = "LBBASPACK_LOG"
+	EnvFormat = "LBBASPACK_LOG_FORMAT"
+)
+
+// Format is how records are written
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat returns the format called name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (want text or json)", name)
+	}
+}
+
+// Levels are the lowest levels logged, by subsystem
+type Levels struct {
+	Default    slog.Level
+	Subsystems map[string]slog.Level
+}
+
+// ParseLevels reads levels from a comma separated list of subsystem=level
+// pairs. A level without a subsystem sets the default, which is info unless
+// given, e.g. "warn,spawn=debug".
+func ParseLevels(spec string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo, Subsystems: make(map[string]slog.Level)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		subsystem, name, found := strings.Cut(entry, "=")
+		if !found {
+			name, subsystem = subsystem, ""
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return Levels{}, fmt.Errorf("invalid log level in %q: %w", entry, err)
+		}
+		if subsystem = strings.TrimSpace(subsystem); subsystem == "" {
+			levels.Default = level
+		} else {
+			levels.Subsystems[subsystem] = level
+		}
+	}
+	return levels, nil
+}
+
+// Level returns the lowest level logged for subsystem
+func (l Levels) Level(subsystem string) slog.Level {
+	if level, ok := l.Subsystems[subsystem]; ok {
+		return level
+	}
+	return l.Default
+}
+
+// lowest returns the lowest level logged for any subsystem
+func (l Levels) lowest() slog.Level {
+	lowest := l.Default
+	for _, level := range l.Subsystems {
+		lowest = min(lowest, level)
+	}
+	return lowest
+}
+
+// config is where and what the loggers log
+type config struct {
+	levels  Levels
+	handler slog.Handler
+}
+
+var current atomic.Pointer[config]
+
+func init() {
+	Configure(os.Stderr, FormatText, Levels{Default: slog.LevelInfo})
+}
+
+// Configure makes every logger, including those created earlier, write to w
+// in format at levels
+func Configure(w io.Writer, format Format, levels Levels) {
+	options := &slog.HandlerOptions{Level: levels.lowest()}
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	current.Store(&config{levels: levels, handler: handler})
+}
+
+// Init configures logging to standard error from a levels spec and a format
+// name, falling back to $LBBASPACK_LOG and $LBBASPACK_LOG_FORMAT for those
+// left empty
+func Init(spec, format string) error {
+	if spec == "" {
+		spec = os.Getenv(EnvLevels)
+	}
+	if format == "" {
+		format = os.Getenv(EnvFormat)
+	}
+	levels, err := ParseLevels(spec)
+	if err != nil {
+		return err
+	}
+	parsed := FormatText
+	if format != "" {
+		if parsed, err = ParseFormat(format); err != nil {
+			return err
+		}
+	}
+	Configure(os.Stderr, parsed, levels)
+	return nil
+}
+
+// For returns the logger of subsystem. Its records carry the subsystem name
+// and are logged at the level configured for it.
+func For(subsystem string) *slog.Logger {
+	h := &handler{subsystem: subsystem}
+	h.target(current.Load())
+	return slog.New(h)
+}
+
+// handler passes records of a subsystem on to the configured handler
+type handler struct {
+	subsystem string
+	// WithAttrs and WithGroup calls, applied to the configured handler
+	wrap []func(slog.Handler) slog.Handler
+	// The configured handler with the subsystem and wraps applied, bound
+	// again only when Configure is called
+	bound atomic.Pointer[boundHandler]
+}
+
+// boundHandler is a handler bound to the configuration it was built from
+type boundHandler struct {
+	config  *config
+	handler slog.Handler
+}
+
+// target returns the handler records are passed on to under cfg
+func (h *handler) target(cfg *config) slog.Handler {
+	if bound := h.bound.Load(); bound != nil && bound.config == cfg {
+		return bound.handler
+	}
+	target := cfg.handler.WithAttrs([]slog.Attr{slog.String("subsystem", h.subsystem)})
+	for _, wrap := range h.wrap {
+		target = wrap(target)
+	}
+	h.bound.Store(&boundHandler{config: cfg, handler: target})
+	return target
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= current.Load().levels.Level(h.subsystem)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	return h.target(current.Load()).Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(target slog.Handler) slog.Handler { return target.WithAttrs(attrs) })
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return h.with(func(target slog.Handler) slog.Handler { return target.WithGroup(name) })
+}
+
+func (h *handler) with(wrap func(slog.Handler) slog.Handler) *handler {
+	derived := &handler{subsystem: h.subsystem, wrap: append(h.wrap[:len(h.wrap):len(h.wrap)], wrap)}
+	derived.target(current.Load())
+	return derived
+}
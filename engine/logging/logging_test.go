@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture sends every logger's records to a buffer as JSON until the test ends
+func capture(t *testing.T, levels Levels) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	Configure(&buf, FormatJSON, levels)
+	t.Cleanup(func() {
+		Configure(os.Stderr, FormatText, Levels{Default: slog.LevelInfo})
+	})
+	return &buf
+}
+
+// records decodes the JSON records written to buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var decoded []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode %q: %v", line, err)
+		}
+		decoded = append(decoded, record)
+	}
+	return decoded
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn, spawn=debug,sla=INFO")
+	if err != nil {
+		t.Fatalf("Failed to parse levels: %v", err)
+	}
+	tests := map[string]slog.Level{
+		"spawn":  slog.LevelDebug,
+		"sla":    slog.LevelInfo,
+		"render": slog.LevelWarn,
+	}
+	for subsystem, want := range tests {
+		if got := levels.Level(subsystem); got != want {
+			t.Errorf("Expected %s at %v, got %v", subsystem, want, got)
+		}
+	}
+
+	if levels, err := ParseLevels(""); err != nil || levels.Level("spawn") != slog.LevelInfo {
+		t.Errorf("Expected info by default, got %v (%v)", levels.Level("spawn"), err)
+	}
+	if _, err := ParseLevels("spawn=loud"); err == nil {
+		t.Error("Expected an unknown level to be refused")
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an unknown format to be refused")
+	}
+}
+
+// TestFor tests that loggers carry their subsystem and follow its level, even when created before Configure
+func TestFor(t *testing.T) {
+	spawn := For("spawn").With("system", "spawn")
+	sla := For("sla")
+	buf := capture(t, Levels{Default: slog.LevelWarn, Subsystems: map[string]slog.Level{"spawn": slog.LevelDebug}})
+
+	spawn.Debug("Spawning packet", "x", 10)
+	sla.Info("Packet lost")
+	sla.Warn("Error budget exceeded")
+
+	got := records(t, buf)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %s", len(got), buf)
+	}
+	if got[0]["subsystem"] != "spawn" || got[0]["msg"] != "Spawning packet" || got[0]["system"] != "spawn" || got[0]["x"] != 10.0 {
+		t.Errorf("Unexpected spawn record %v", got[0])
+	}
+	if got[1]["subsystem"] != "sla" || got[1]["level"] != "WARN" {
+		t.Errorf("Unexpected sla record %v", got[1])
+	}
+}
+
+// TestFor_BindsOnce tests that a logger binds its subsystem to the configured handler once per configuration
+func TestFor_BindsOnce(t *testing.T) {
+	logger := For("spawn").With("system", "spawn")
+	h := logger.Handler().(*handler)
+	capture(t, Levels{Default: slog.LevelInfo})
+
+	logger.Info("Spawning packet")
+	bound := h.bound.Load()
+	logger.Info("Spawning packet")
+	if h.bound.Load() != bound {
+		t.Error("Expected records of one configuration to share the bound handler")
+	}
+
+	buf := capture(t, Levels{Default: slog.LevelInfo})
+	logger.Info("Spawning power-up")
+	if h.bound.Load() == bound {
+		t.Error("Expected the handler bound again after Configure")
+	}
+	if got := records(t, buf); len(got) != 1 || got[0]["subsystem"] != "spawn" || got[0]["system"] != "spawn" {
+		t.Errorf("Expected the record written to the new configuration, got %v", got)
+	}
+}
+
+// TestEvery tests that repeated messages are dropped within the interval and counted
+func TestEvery(t *testing.T) {
+	buf := capture(t, Levels{Default: slog.LevelDebug})
+	handler := Every(For("movement"), time.Second).Handler()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := func(offset time.Duration, message string) {
+		if err := handler.Handle(context.Background(), slog.NewRecord(start.Add(offset), slog.LevelDebug, message, 0)); err != nil {
+			t.Fatalf("Failed to handle record: %v", err)
+		}
+	}
+	for frame := range 90 {
+		log(time.Duration(frame)*time.Second/60, "Packet moved")
+	}
+	log(10*time.Millisecond, "Processing packets")
+
+	got := records(t, buf)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 records, got %d: %s", len(got), buf)
+	}
+	if got[0]["msg"] != "Packet moved" || got[0]["suppressed"] != nil {
+		t.Errorf("Expected the first record let through as is, got %v", got[0])
+	}
+	if got[1]["msg"] != "Packet moved" || got[1]["suppressed"] != 59.0 {
+		t.Errorf("Expected the record a second later to count 59 dropped, got %v", got[1])
+	}
+	if got[2]["msg"] != "Processing packets" {
+		t.Errorf("Expected other messages limited on their own, got %v", got[2])
+	}
+}
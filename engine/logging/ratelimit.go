@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Every returns a logger that passes each message of logger on at most once
+// per interval, for logging from code that runs every frame. The first
+// record let through after others were dropped carries their number as
+// "suppressed".
+func Every(logger *slog.Logger, interval time.Duration) *slog.Logger {
+	return slog.New(&limitHandler{
+		Handler: logger.Handler(),
+		limiter: &limiter{interval: interval, messages: make(map[string]*messageLimit)},
+	})
+}
+
+// limiter tracks when each message was last let through
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	messages map[string]*messageLimit
+}
+
+type messageLimit struct {
+	last       time.Time
+	suppressed int
+}
+
+// allow reports whether a record of message logged at t is let through, and
+// how many were dropped since the last one that was
+func (l *limiter) allow(message string, t time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.messages[message]
+	if !ok {
+		l.messages[message] = &messageLimit{last: t}
+		return true, 0
+	}
+	if t.Sub(limit.last) < l.interval {
+		limit.suppressed++
+		return false, 0
+	}
+	suppressed := limit.suppressed
+	limit.last, limit.suppressed = t, 0
+	return true, suppressed
+}
+
+// limitHandler drops records the limiter does not let through. Loggers
+// derived with With share the limiter.
+type limitHandler struct {
+	slog.Handler
+	limiter *limiter
+}
+
+func (h *limitHandler) Handle(ctx context.Context, record slog.Record) error {
+	ok, suppressed := h.limiter.allow(record.Message, record.Time)
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		record = record.Clone()
+		record.AddAttrs(slog.Int("suppressed", suppressed))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *limitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &limitHandler{Handler: h.Handler.WithAttrs(attrs), limiter: h.limiter}
+}
+
+func (h *limitHandler) WithGroup(name string) slog.Handler {
+	return &limitHandler{Handler: h.Handler.WithGroup(name), limiter: h.limiter}
+}
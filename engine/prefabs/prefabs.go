@@ -0,0 +1,360 @@
+// Package prefabs defines entity templates, lists of components with field
+// values, in JSON files. The built-in templates are embedded in the binary
+// and can be overridden from a user directory.
+package prefabs
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"lbbaspack/engine/components"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed data/*.json
+var defaultFiles embed.FS
+
+// Overrides replaces prefab field values when instantiating. It is keyed by
+// component type name and then by field name, e.g.
+// Overrides{"Transform": {"X": 100.0}}.
+type Overrides map[string]map[string]any
+
+// ComponentSource hands out reset components for reuse. ecs.World implements
+// it with its component pool.
+type ComponentSource interface {
+	AcquireComponent(componentType string) (components.Component, bool)
+}
+
+// Target is anything components can be added to
+type Target interface {
+	AddComponent(component components.Component)
+}
+
+// Definition is a prefab as written in a prefab file. Components listed in
+// the definition are added after those of the prefab it extends, and fields
+// given for a component the parent also has are layered over the parent's.
+type Definition struct {
+	Extends    string              `json:"extends,omitempty"`
+	Components []ComponentTemplate `json:"components"`
+}
+
+// ComponentTemplate is one component of a prefab: its GetType name and the
+// field values to set on a freshly constructed instance
+type ComponentTemplate struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// prefab is a definition resolved against its parents, holding one decoded
+// prototype per component
+type prefab struct {
+	prototypes []components.Component
+}
+
+// Library holds prefab definitions by name. It is safe for concurrent use.
+type Library struct {
+	mu          sync.RWMutex
+	definitions map[string]Definition
+	prefabs     map[string]*prefab
+}
+
+// New creates an empty library
+func New() *Library {
+	return &Library{
+		definitions: make(map[string]Definition),
+		prefabs:     make(map[string]*prefab),
+	}
+}
+
+// Default creates a library holding the built-in prefabs. It panics if the
+// embedded prefab files are invalid, which the package tests rule out.
+func Default() *Library {
+	library := New()
+	files, err := fs.Sub(defaultFiles, "data")
+	if err == nil {
+		err = library.Load(files)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("prefabs: invalid built-in prefabs: %v", err))
+	}
+	return library
+}
+
+// Load reads every .json file at the root of fsys, in name order. Each file
+// maps prefab names to definitions; definitions replace any already loaded
+// under the same name. The library is left untouched if any file or prefab
+// fails to load.
+func (l *Library) Load(fsys fs.FS) error {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	definitions := make(map[string]Definition, len(l.definitions))
+	for name, definition := range l.definitions {
+		definitions[name] = definition
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read prefab file %s: %w", name, err)
+		}
+		var file map[string]Definition
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to decode prefab file %s: %w", name, err)
+		}
+		for prefabName, definition := range file {
+			definitions[prefabName] = definition
+		}
+	}
+
+	// Resolve every prefab now, so that broken files are reported on load
+	// rather than on first spawn
+	prefabs := make(map[string]*prefab, len(definitions))
+	for name := range definitions {
+		if _, err := resolve(definitions, prefabs, name, nil); err != nil {
+			return err
+		}
+	}
+	l.definitions = definitions
+	l.prefabs = prefabs
+	return nil
+}
+
+// LoadDir loads the prefab files in dir like Load. A missing directory is not
+// an error, so that user overrides are optional.
+func (l *Library) LoadDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return l.Load(os.DirFS(dir))
+}
+
+// Has reports whether the library defines a prefab
+func (l *Library) Has(name string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, exists := l.prefabs[name]
+	return exists
+}
+
+// Names returns the names of all prefabs in sorted order
+func (l *Library) Names() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	names := make([]string, 0, len(l.prefabs))
+	for name := range l.prefabs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve decodes a prefab and the prefabs it extends, caching the results in
+// prefabs. chain holds the names being resolved, to detect cycles.
+func resolve(definitions map[string]Definition, prefabs map[string]*prefab, name string, chain []string) (*prefab, error) {
+	if resolved, exists := prefabs[name]; exists {
+		return resolved, nil
+	}
+	for _, pending := range chain {
+		if pending == name {
+			return nil, fmt.Errorf("prefab %q extends itself through %s", name, strings.Join(append(chain, name), " -> "))
+		}
+	}
+	definition, exists := definitions[name]
+	if !exists {
+		if len(chain) > 0 {
+			return nil, fmt.Errorf("prefab %q extends unknown prefab %q", chain[len(chain)-1], name)
+		}
+		return nil, fmt.Errorf("unknown prefab %q", name)
+	}
+
+	resolved := &prefab{prototypes: make([]components.Component, 0, len(definition.Components))}
+	if definition.Extends != "" {
+		parent, err := resolve(definitions, prefabs, definition.Extends, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, prototype := range parent.prototypes {
+			resolved.prototypes = append(resolved.prototypes, clone(prototype))
+		}
+	}
+
+	for _, template := range definition.Components {
+		var prototype components.Component
+		for _, existing := range resolved.prototypes {
+			if existing.GetType() == template.Type {
+				prototype = existing
+				break
+			}
+		}
+		if prototype == nil {
+			component, ok := newComponent(template.Type)
+			if !ok {
+				return nil, fmt.Errorf("prefab %q: component %q has no registered type", name, template.Type)
+			}
+			if reflect.TypeOf(component).Kind() != reflect.Pointer {
+				return nil, fmt.Errorf("prefab %q: component %q is not a pointer", name, template.Type)
+			}
+			prototype = component
+			resolved.prototypes = append(resolved.prototypes, prototype)
+		}
+		if len(template.Data) > 0 {
+			if err := json.Unmarshal(template.Data, prototype); err != nil {
+				return nil, fmt.Errorf("prefab %q: failed to decode component %q: %w", name, template.Type, err)
+			}
+		}
+	}
+
+	prefabs[name] = resolved
+	return resolved, nil
+}
+
+// Instantiate returns new components for a prefab with overrides applied.
+// Components are drawn from source when it is not nil. Transforms start
+// without interpolation history at their final position.
+func (l *Library) Instantiate(name string, overrides Overrides, source ComponentSource) ([]components.Component, error) {
+	l.mu.RLock()
+	resolved, exists := l.prefabs[name]
+	l.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown prefab %q", name)
+	}
+
+	for componentType := range overrides {
+		if !resolved.has(componentType) {
+			return nil, fmt.Errorf("prefab %q has no component %q to override", name, componentType)
+		}
+	}
+
+	built := make([]components.Component, 0, len(resolved.prototypes))
+	for _, prototype := range resolved.prototypes {
+		componentType := prototype.GetType()
+		component, ok := acquire(source, componentType)
+		if !ok || reflect.TypeOf(component) != reflect.TypeOf(prototype) {
+			component = clone(prototype)
+		} else {
+			reflect.ValueOf(component).Elem().Set(reflect.ValueOf(prototype).Elem())
+		}
+		if fields, exists := overrides[componentType]; exists {
+			if err := override(component, fields); err != nil {
+				return nil, fmt.Errorf("prefab %q: %w", name, err)
+			}
+		}
+		if transform, ok := component.(interface{ StorePrevious() }); ok {
+			transform.StorePrevious()
+		}
+		built = append(built, component)
+	}
+	return built, nil
+}
+
+// Build instantiates a prefab and adds its components to target in the order
+// the prefab lists them. Nothing is added if instantiation fails.
+func (l *Library) Build(target Target, name string, overrides Overrides, source ComponentSource) error {
+	built, err := l.Instantiate(name, overrides, source)
+	if err != nil {
+		return err
+	}
+	for _, component := range built {
+		target.AddComponent(component)
+	}
+	return nil
+}
+
+// Name joins a prefab family and a variant display name into a prefab name,
+// e.g. Name("powerup", "Speed Boost") is "powerup.speed-boost"
+func Name(family, variant string) string {
+	return family + "." + strings.ReplaceAll(strings.ToLower(variant), " ", "-")
+}
+
+// has reports whether the prefab has a component of the given type
+func (p *prefab) has(componentType string) bool {
+	for _, prototype := range p.prototypes {
+		if prototype.GetType() == componentType {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire takes a component from source, falling back to a new one
+func acquire(source ComponentSource, componentType string) (components.Component, bool) {
+	if source != nil {
+		if component, ok := source.AcquireComponent(componentType); ok {
+			return component, true
+		}
+	}
+	return newComponent(componentType)
+}
+
+// newComponent creates a registered component in the state its Reset method
+// gives it, so that fields a prefab leaves out keep their constructor defaults
+func newComponent(componentType string) (components.Component, bool) {
+	component, ok := components.New(componentType)
+	if !ok {
+		return nil, false
+	}
+	if resettable, ok := component.(components.Resettable); ok {
+		resettable.Reset()
+	}
+	return component, true
+}
+
+// clone returns a shallow copy of a pointer component
+func clone(component components.Component) components.Component {
+	value := reflect.ValueOf(component).Elem()
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(value)
+	return copied.Interface().(components.Component)
+}
+
+// override sets the named fields of a pointer-to-struct component. Numbers
+// are converted to the field's numeric type.
+func override(component components.Component, fields map[string]any) error {
+	value := reflect.ValueOf(component).Elem()
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("component %q has no fields to override", component.GetType())
+	}
+	for name, raw := range fields {
+		field := value.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			return fmt.Errorf("component %q has no field %q", component.GetType(), name)
+		}
+		given := reflect.ValueOf(raw)
+		switch {
+		case !given.IsValid():
+			return fmt.Errorf("component %q: field %q cannot be set to nil", component.GetType(), name)
+		case given.Type().AssignableTo(field.Type()):
+			field.Set(given)
+		case isNumber(given.Kind()) && isNumber(field.Kind()):
+			field.Set(given.Convert(field.Type()))
+		default:
+			return fmt.Errorf("component %q: field %q is %s, not %s", component.GetType(), name, field.Type(), given.Type())
+		}
+	}
+	return nil
+}
+
+// isNumber reports whether a kind is an integer or floating point number
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
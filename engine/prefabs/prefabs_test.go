@@ -0,0 +1,274 @@
+package prefabs
+
+import (
+	"image/color"
+	"lbbaspack/engine/components"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// countingSource is a ComponentSource that records acquisitions
+type countingSource struct {
+	acquired []string
+}
+
+func (s *countingSource) AcquireComponent(componentType string) (components.Component, bool) {
+	s.acquired = append(s.acquired, componentType)
+	return newComponent(componentType)
+}
+
+// componentMap indexes built components by type name
+func componentMap(built []components.Component) map[string]components.Component {
+	byType := make(map[string]components.Component, len(built))
+	for _, component := range built {
+		byType[component.GetType()] = component
+	}
+	return byType
+}
+
+// TestDefault_BuiltInPrefabs tests that the embedded prefabs load and match the game's entities
+func TestDefault_BuiltInPrefabs(t *testing.T) {
+	library := Default()
+	for _, name := range []string{"loadbalancer", "backend", "packet.http", "packet.https", "packet.tcp", "packet.udp", "packet.websocket"} {
+		if !library.Has(name) {
+			t.Errorf("Expected built-in prefab %q", name)
+		}
+	}
+
+	built, err := library.Instantiate("packet.https", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to instantiate packet: %v", err)
+	}
+	names := make([]string, 0, len(built))
+	for _, component := range built {
+		names = append(names, component.GetType())
+	}
+	if strings.Join(names, ",") != "Transform,Sprite,Collider,Physics,PacketType" {
+		t.Errorf("Expected packet components in prefab order, got %v", names)
+	}
+	byType := componentMap(built)
+	if sprite := byType["Sprite"].(*components.Sprite); sprite.Color != (color.RGBA{0, 255, 0, 255}) || !sprite.Visible || sprite.Width != 15 {
+		t.Errorf("Expected a visible green 15px sprite, got %+v", sprite)
+	}
+	if packetType := byType["PacketType"].(*components.PacketType); packetType.Name != "HTTPS" || packetType.Value != 10 {
+		t.Errorf("Expected an HTTPS packet worth 10, got %+v", packetType)
+	}
+	if transform := byType["Transform"].(*components.Transform); transform.Y != -15 || transform.ScaleX != 1 {
+		t.Errorf("Expected constructor defaults under prefab values, got %+v", transform)
+	}
+}
+
+// TestDefault_PowerUpColors tests that every power-up prefab keeps its color
+func TestDefault_PowerUpColors(t *testing.T) {
+	library := Default()
+	expected := map[string]color.RGBA{
+		"Speed Boost":   {255, 255, 0, 255},
+		"Wide Catch":    {0, 255, 255, 255},
+		"Multi-Catch":   {255, 0, 255, 255},
+		"Time Slow":     {0, 0, 255, 255},
+		"Shield":        {0, 255, 0, 255},
+		"Auto-Balancer": {255, 165, 0, 255},
+	}
+	for name, want := range expected {
+		built, err := library.Instantiate(Name("powerup", name), nil, nil)
+		if err != nil {
+			t.Errorf("Failed to instantiate power-up %q: %v", name, err)
+			continue
+		}
+		byType := componentMap(built)
+		if got := byType["Sprite"].(*components.Sprite).Color; got != want {
+			t.Errorf("Expected %q to be %v, got %v", name, want, got)
+		}
+		if got := byType["PowerUpType"].(*components.PowerUpType); got.Name != name || got.Duration != 10 {
+			t.Errorf("Expected power-up %q lasting 10s, got %+v", name, got)
+		}
+	}
+}
+
+// TestDefault_Layers tests that the layer numbers in the prefab files match the components layer constants
+func TestDefault_Layers(t *testing.T) {
+	library := Default()
+	layers := map[string]int{
+		"backend":      components.LayerBackends,
+		"loadbalancer": components.LayerLoadBalancer,
+		"packet":       components.LayerPackets,
+		"powerup":      components.LayerPowerUps,
+	}
+	for _, name := range library.Names() {
+		built, err := library.Instantiate(name, nil, nil)
+		if err != nil {
+			t.Errorf("Failed to instantiate %q: %v", name, err)
+			continue
+		}
+		sprite, ok := componentMap(built)["Sprite"].(*components.Sprite)
+		if !ok {
+			continue
+		}
+		family, _, _ := strings.Cut(name, ".")
+		want, known := layers[family]
+		if !known {
+			t.Errorf("Prefab %q has a sprite but no expected layer", name)
+			continue
+		}
+		if sprite.Layer != want {
+			t.Errorf("Expected %q on layer %d, got %d", name, want, sprite.Layer)
+		}
+	}
+}
+
+// TestLibrary_InstantiateOverrides tests that overrides apply over prefab values
+func TestLibrary_InstantiateOverrides(t *testing.T) {
+	library := Default()
+	source := &countingSource{}
+
+	built, err := library.Instantiate("backend", Overrides{
+		"Transform":         {"X": 64},
+		"BackendAssignment": {"BackendID": 2},
+		"Sprite":            {"Color": color.RGBA{1, 2, 3, 255}},
+	}, source)
+	if err != nil {
+		t.Fatalf("Failed to instantiate backend: %v", err)
+	}
+	if len(source.acquired) != len(built) {
+		t.Errorf("Expected every component to be drawn from the source, got %v", source.acquired)
+	}
+	byType := componentMap(built)
+	transform := byType["Transform"].(*components.Transform)
+	if transform.X != 64 || transform.Y != 550 || transform.PrevX != 64 {
+		t.Errorf("Expected the overridden position without interpolation history, got %+v", transform)
+	}
+	if id := byType["BackendAssignment"].(*components.BackendAssignment).BackendID; id != 2 {
+		t.Errorf("Expected backend ID 2, got %d", id)
+	}
+	if got := byType["Sprite"].(*components.Sprite).Color; got != (color.RGBA{1, 2, 3, 255}) {
+		t.Errorf("Expected the overridden color, got %v", got)
+	}
+
+	// Overrides never leak into the prefab itself
+	again, _ := library.Instantiate("backend", nil, nil)
+	if componentMap(again)["Transform"].(*components.Transform).X != 0 {
+		t.Error("Expected overrides not to change the prefab")
+	}
+}
+
+// TestLibrary_InstantiateErrors tests that bad names and overrides are rejected
+func TestLibrary_InstantiateErrors(t *testing.T) {
+	library := Default()
+	cases := map[string]Overrides{
+		"unknown prefab":    nil,
+		"missing component": {"Routing": {"IsRouted": true}},
+		"unknown field":     {"Transform": {"Z": 1.0}},
+		"wrong type":        {"PacketType": {"Name": 5}},
+		"nil value":         {"PacketType": {"Name": nil}},
+	}
+	for name, overrides := range cases {
+		prefab := "packet.http"
+		if name == "unknown prefab" {
+			prefab = "packet.ftp"
+		}
+		if _, err := library.Instantiate(prefab, overrides, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestLibrary_Load tests that later files replace prefabs and layer over parents
+func TestLibrary_Load(t *testing.T) {
+	library := Default()
+	err := library.Load(fstest.MapFS{
+		"mods.json": {Data: []byte(`{
+			"packet": {"components": [
+				{"type": "Transform", "data": {"Y": -30}},
+				{"type": "Sprite", "data": {"Width": 20, "Height": 20}},
+				{"type": "Collider", "data": {"Width": 20, "Height": 20, "Tag": "packet"}},
+				{"type": "Physics"},
+				{"type": "PacketType", "data": {"Value": 20}}
+			]},
+			"packet.grpc": {"extends": "packet", "components": [
+				{"type": "PacketType", "data": {"Name": "gRPC"}},
+				{"type": "Routing"}
+			]}
+		}`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load prefabs: %v", err)
+	}
+
+	built, err := library.Instantiate("packet.http", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to instantiate packet: %v", err)
+	}
+	byType := componentMap(built)
+	if sprite := byType["Sprite"].(*components.Sprite); sprite.Width != 20 || sprite.Color != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected the replaced base under the built-in variant, got %+v", sprite)
+	}
+
+	built, err = library.Instantiate("packet.grpc", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to instantiate new prefab: %v", err)
+	}
+	byType = componentMap(built)
+	if packetType := byType["PacketType"].(*components.PacketType); packetType.Name != "gRPC" || packetType.Value != 20 {
+		t.Errorf("Expected a gRPC packet worth 20, got %+v", packetType)
+	}
+	if routing, ok := byType["Routing"].(*components.Routing); !ok || !routing.IsRouted {
+		t.Errorf("Expected an added Routing with constructor defaults, got %+v", byType["Routing"])
+	}
+}
+
+// TestLibrary_LoadErrors tests that a failed load leaves the library untouched
+func TestLibrary_LoadErrors(t *testing.T) {
+	cases := map[string]string{
+		"invalid json":     `{"packet": [`,
+		"unknown parent":   `{"packet.ftp": {"extends": "packet.file", "components": []}}`,
+		"cycle":            `{"a": {"extends": "b"}, "b": {"extends": "a"}}`,
+		"unknown type":     `{"packet.ftp": {"components": [{"type": "Teleporter"}]}}`,
+		"bad field values": `{"packet.ftp": {"components": [{"type": "Transform", "data": {"X": "left"}}]}}`,
+	}
+	for name, data := range cases {
+		library := Default()
+		before := library.Names()
+		err := library.Load(fstest.MapFS{"bad.json": {Data: []byte(data)}})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if strings.Join(library.Names(), ",") != strings.Join(before, ",") {
+			t.Errorf("%s: expected the library to be unchanged", name)
+		}
+	}
+}
+
+// TestLibrary_LoadDir tests loading user overrides from a directory
+func TestLibrary_LoadDir(t *testing.T) {
+	library := Default()
+	if err := library.LoadDir(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("Expected a missing directory to be ignored, got %v", err)
+	}
+
+	dir := t.TempDir()
+	data := `{"loadbalancer": {"components": [{"type": "Transform", "data": {"X": 10}}]}}`
+	if err := os.WriteFile(filepath.Join(dir, "lb.json"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := library.LoadDir(dir); err != nil {
+		t.Fatalf("Failed to load directory: %v", err)
+	}
+	built, _ := library.Instantiate("loadbalancer", nil, nil)
+	if len(built) != 1 || built[0].(*components.Transform).X != 10 {
+		t.Errorf("Expected the user prefab to replace the built-in one, got %v", built)
+	}
+}
+
+// TestName tests prefab name construction from display names
+func TestName(t *testing.T) {
+	if got := Name("powerup", "Speed Boost"); got != "powerup.speed-boost" {
+		t.Errorf("Expected powerup.speed-boost, got %s", got)
+	}
+	if got := Name("packet", "WebSocket"); got != "packet.websocket" {
+		t.Errorf("Expected packet.websocket, got %s", got)
+	}
+}
@@ -0,0 +1,170 @@
+// Package random provides seeded random number streams so that game runs can
+// be reproduced from a single seed.
+package random
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Stream names used by the built-in systems
+const (
+	StreamSpawn     = "spawn"
+	StreamDDoS      = "ddos"
+	StreamPowerUps  = "powerups"
+	StreamParticles = "particles"
+)
+
+// RNG hands out named random streams derived from one master seed. Each
+// stream is seeded independently, so drawing from one stream never changes
+// the sequence produced by another.
+type RNG struct {
+	mu      sync.Mutex
+	seed    int64
+	streams map[string]*rand.Rand
+	sources map[string]*pcgSource
+}
+
+// pcgSource adapts a PCG generator, whose whole state can be copied and
+// saved, to the source of a math/rand stream
+type pcgSource struct {
+	pcg *randv2.PCG
+}
+
+// newPCGSource returns the source of the named stream at its start
+func newPCGSource(seed int64, name string) *pcgSource {
+	return &pcgSource{pcg: randv2.NewPCG(uint64(seed), uint64(streamSeed(seed, name)))}
+}
+
+func (s *pcgSource) Int63() int64 {
+	return int64(s.pcg.Uint64() &^ (1 << 63))
+}
+
+func (s *pcgSource) Uint64() uint64 {
+	return s.pcg.Uint64()
+}
+
+// Seed implements rand.Source. The RNG never reseeds its streams; it
+// replaces their state instead.
+func (s *pcgSource) Seed(seed int64) {
+	s.pcg.Seed(uint64(seed), uint64(seed))
+}
+
+// New creates an RNG for the given master seed
+func New(seed int64) *RNG {
+	return &RNG{
+		seed:    seed,
+		streams: make(map[string]*rand.Rand),
+		sources: make(map[string]*pcgSource),
+	}
+}
+
+// NewSeed returns a seed based on the current time, for unseeded runs
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Seed returns the master seed
+func (r *RNG) Seed() int64 {
+	return r.seed
+}
+
+// Stream returns the stream with the given name, creating it on first use.
+// Streams are not safe for concurrent use; each should be owned by one system.
+func (r *RNG) Stream(name string) *rand.Rand {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stream, exists := r.streams[name]; exists {
+		return stream
+	}
+	source := newPCGSource(r.seed, name)
+	stream := rand.New(source)
+	r.streams[name] = stream
+	r.sources[name] = source
+	return stream
+}
+
+// Clone returns an RNG with the same seed whose streams continue from the
+// positions this RNG's streams have reached. Drawing from either RNG leaves
+// the other untouched. Clone must not run while streams are being drawn from.
+func (r *RNG) Clone() *RNG {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clone := New(r.seed)
+	for name, original := range r.sources {
+		pcg := *original.pcg
+		source := &pcgSource{pcg: &pcg}
+		clone.streams[name] = rand.New(source)
+		clone.sources[name] = source
+	}
+	return clone
+}
+
+// State is the master seed of an RNG and the state of each of its streams,
+// for saving a run and resuming it later. The streams are saved in the
+// binary form of math/rand/v2's PCG.
+type State struct {
+	Seed    int64             `json:"seed"`
+	Streams map[string][]byte `json:"streams,omitempty"`
+}
+
+// State returns the seed and the states the streams have reached
+func (r *RNG) State() State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := State{Seed: r.seed, Streams: make(map[string][]byte, len(r.sources))}
+	for name, source := range r.sources {
+		// Marshaling a PCG cannot fail
+		state.Streams[name], _ = source.pcg.MarshalBinary()
+	}
+	return state
+}
+
+// Restore brings the RNG to state. Streams handed out earlier are changed in
+// place, so their holders continue from the restored states; those missing
+// from state start over. Nothing is changed if a stream's state is invalid.
+func (r *RNG) Restore(state State) error {
+	restored := make(map[string]*randv2.PCG, len(state.Streams))
+	for name, data := range state.Streams {
+		pcg := &randv2.PCG{}
+		if err := pcg.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("random stream %q: %w", name, err)
+		}
+		restored[name] = pcg
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seed = state.Seed
+	for name := range restored {
+		if _, exists := r.sources[name]; !exists {
+			source := &pcgSource{pcg: &randv2.PCG{}}
+			r.streams[name] = rand.New(source)
+			r.sources[name] = source
+		}
+	}
+	for name, source := range r.sources {
+		if pcg, ok := restored[name]; ok {
+			*source.pcg = *pcg
+		} else {
+			*source.pcg = *newPCGSource(r.seed, name).pcg
+		}
+	}
+	return nil
+}
+
+// streamSeed mixes the master seed with the stream name using splitmix64 so
+// that similar seeds and names still produce unrelated streams
+func streamSeed(seed int64, name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	z := uint64(seed) ^ hash.Sum64()
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
@@ -0,0 +1,127 @@
+package random
+
+import "testing"
+
+// draw returns the first n values of a stream
+func draw(r *RNG, name string, n int) []int64 {
+	values := make([]int64, n)
+	stream := r.Stream(name)
+	for i := range values {
+		values[i] = stream.Int63()
+	}
+	return values
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestRNG_SameSeedSameSequence tests that a seed reproduces every stream
+func TestRNG_SameSeedSameSequence(t *testing.T) {
+	first, second := New(42), New(42)
+	for _, name := range []string{StreamSpawn, StreamDDoS, StreamPowerUps, StreamParticles} {
+		if !equal(draw(first, name, 10), draw(second, name, 10)) {
+			t.Errorf("Expected stream %q to be reproducible", name)
+		}
+	}
+	if first.Seed() != 42 {
+		t.Errorf("Expected seed 42, got %d", first.Seed())
+	}
+}
+
+// TestRNG_DifferentSeeds tests that different seeds produce different sequences
+func TestRNG_DifferentSeeds(t *testing.T) {
+	if equal(draw(New(1), StreamSpawn, 10), draw(New(2), StreamSpawn, 10)) {
+		t.Error("Expected different seeds to produce different sequences")
+	}
+}
+
+// TestRNG_StreamsAreIndependent tests that drawing from one stream does not affect another
+func TestRNG_StreamsAreIndependent(t *testing.T) {
+	untouched := draw(New(7), StreamDDoS, 10)
+
+	busy := New(7)
+	draw(busy, StreamSpawn, 1000)
+	if !equal(draw(busy, StreamDDoS, 10), untouched) {
+		t.Error("Expected ddos stream to be unaffected by spawn draws")
+	}
+
+	if equal(draw(New(7), StreamSpawn, 10), untouched) {
+		t.Error("Expected named streams to produce different sequences")
+	}
+}
+
+// TestRNG_StreamIsCached tests that a stream continues where it left off
+func TestRNG_StreamIsCached(t *testing.T) {
+	r := New(3)
+	if r.Stream(StreamSpawn) != r.Stream(StreamSpawn) {
+		t.Error("Expected the same stream instance for a name")
+	}
+}
+
+// TestRNG_Clone tests that a clone continues every stream from the same position independently
+func TestRNG_Clone(t *testing.T) {
+	original := New(11)
+	draw(original, StreamSpawn, 5)
+	original.Stream(StreamParticles).Float64()
+
+	clone := original.Clone()
+	if clone.Seed() != original.Seed() {
+		t.Errorf("Expected the clone to keep seed %d, got %d", original.Seed(), clone.Seed())
+	}
+	want := draw(original, StreamSpawn, 10)
+	if got := draw(clone, StreamSpawn, 10); !equal(got, want) {
+		t.Errorf("Expected the clone to continue the spawn stream, got %v want %v", got, want)
+	}
+	if clone.Stream(StreamParticles).Float64() != original.Stream(StreamParticles).Float64() {
+		t.Error("Expected the clone to continue the particles stream")
+	}
+
+	// Streams first used after cloning start from the beginning in both
+	if !equal(draw(clone, StreamDDoS, 3), draw(original, StreamDDoS, 3)) {
+		t.Error("Expected new streams to match")
+	}
+}
+
+// TestRNG_Restore tests that restoring a state continues every stream, including those already handed out
+func TestRNG_Restore(t *testing.T) {
+	original := New(7)
+	draw(original, StreamSpawn, 5)
+	state := original.State()
+	want := draw(original, StreamSpawn, 10)
+
+	resumed := New(99)
+	held := resumed.Stream(StreamSpawn)
+	held.Int63()
+	draw(resumed, StreamParticles, 3)
+	if err := resumed.Restore(state); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+
+	if resumed.Seed() != 7 {
+		t.Errorf("Expected the seed restored to 7, got %d", resumed.Seed())
+	}
+	got := make([]int64, len(want))
+	for i := range got {
+		got[i] = held.Int63()
+	}
+	if !equal(got, want) {
+		t.Errorf("Expected the held stream to continue from the saved position, got %v want %v", got, want)
+	}
+	if !equal(draw(resumed, StreamParticles, 3), draw(New(7), StreamParticles, 3)) {
+		t.Error("Expected streams missing from the state to start over")
+	}
+
+	state.Streams[StreamSpawn] = []byte("garbage")
+	if err := resumed.Restore(state); err == nil {
+		t.Error("Expected an invalid stream state to be rejected")
+	}
+}
@@ -0,0 +1,210 @@
+// Package scenes provides a stack of game screens, such as the menu, a running
+// level and a pause overlay, each with its own systems and lifecycle hooks.
+package scenes
+
+import (
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/systems"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Scene is one screen of the game. Only the scene on top of a Stack handles
+// input and advances; scenes beneath it stay paused.
+type Scene interface {
+	// Enter is called when the scene is pushed onto the stack or replaces its top
+	Enter(stack *Stack)
+	// Exit is called when the scene is popped, replaced or cleared
+	Exit()
+	// Pause is called when another scene is pushed on top of it
+	Pause()
+	// Resume is called when the scene above it is popped
+	Resume()
+	// HandleInput runs once per frame, before any simulation steps
+	HandleInput()
+	// Step advances the scene by one fixed simulation step
+	Step(deltaTime float64)
+	// Draw renders the scene
+	Draw(screen *ebiten.Image)
+	// IsOverlay reports whether the scene beneath stays visible under this one
+	IsOverlay() bool
+}
+
+// BaseScene provides the default scene behavior: lifecycle hooks do nothing,
+// and each step updates the scene's own systems in order. Scenes embed it and
+// override what they need.
+type BaseScene struct {
+	Systems    []systems.System
+	Entities   func() []systems.Entity
+	Dispatcher *events.EventDispatcher
+	Overlay    bool
+	stack      *Stack
+
+	subscriptions *events.Scope
+}
+
+// Enter implements Scene
+func (bs *BaseScene) Enter(stack *Stack) {
+	bs.stack = stack
+}
+
+// Exit implements Scene, removing the handlers subscribed through Subscriptions
+func (bs *BaseScene) Exit() {
+	if bs.subscriptions != nil {
+		bs.subscriptions.Close()
+	}
+}
+
+// Subscriptions returns the scope for event handlers that should only run
+// while the scene is on the stack. They are removed when the scene exits.
+func (bs *BaseScene) Subscriptions() *events.Scope {
+	if bs.subscriptions == nil {
+		bs.subscriptions = bs.Dispatcher.NewScope()
+	}
+	return bs.subscriptions
+}
+
+// Pause implements Scene
+func (bs *BaseScene) Pause() {}
+
+// Resume implements Scene
+func (bs *BaseScene) Resume() {}
+
+// HandleInput implements Scene
+func (bs *BaseScene) HandleInput() {}
+
+// Step implements Scene by updating the scene's systems
+func (bs *BaseScene) Step(deltaTime float64) {
+	if len(bs.Systems) == 0 {
+		return
+	}
+	var entities []systems.Entity
+	if bs.Entities != nil {
+		entities = bs.Entities()
+	}
+	for _, system := range bs.Systems {
+		system.Update(deltaTime, entities, bs.Dispatcher)
+	}
+}
+
+// Draw implements Scene by drawing the scene's drawable systems
+func (bs *BaseScene) Draw(screen *ebiten.Image) {
+	var entities []systems.Entity
+	if bs.Entities != nil {
+		entities = bs.Entities()
+	}
+	for _, system := range bs.Systems {
+		if drawable, ok := system.(systems.DrawableSystem); ok {
+			drawable.Draw(screen, entities)
+		}
+	}
+}
+
+// IsOverlay implements Scene
+func (bs *BaseScene) IsOverlay() bool {
+	return bs.Overlay
+}
+
+// Stack returns the stack the scene was last entered on
+func (bs *BaseScene) Stack() *Stack {
+	return bs.stack
+}
+
+// Stack holds the active scenes, the top one being in control. The zero value
+// is an empty stack ready to use.
+type Stack struct {
+	scenes []Scene
+}
+
+// Top returns the scene in control, or nil if the stack is empty
+func (s *Stack) Top() Scene {
+	if len(s.scenes) == 0 {
+		return nil
+	}
+	return s.scenes[len(s.scenes)-1]
+}
+
+// Len returns the number of scenes on the stack
+func (s *Stack) Len() int {
+	return len(s.scenes)
+}
+
+// Push pauses the current top scene and enters scene on top of it
+func (s *Stack) Push(scene Scene) {
+	if top := s.Top(); top != nil {
+		top.Pause()
+	}
+	s.scenes = append(s.scenes, scene)
+	scene.Enter(s)
+}
+
+// Pop exits the top scene and resumes the one beneath it. It returns the
+// popped scene, or nil if the stack was empty.
+func (s *Stack) Pop() Scene {
+	top := s.Top()
+	if top == nil {
+		return nil
+	}
+	s.scenes[len(s.scenes)-1] = nil
+	s.scenes = s.scenes[:len(s.scenes)-1]
+	top.Exit()
+	if below := s.Top(); below != nil {
+		below.Resume()
+	}
+	return top
+}
+
+// Replace exits the top scene and enters scene in its place, without pausing
+// or resuming the scenes beneath
+func (s *Stack) Replace(scene Scene) {
+	if top := s.Top(); top != nil {
+		s.scenes = s.scenes[:len(s.scenes)-1]
+		top.Exit()
+	}
+	s.scenes = append(s.scenes, scene)
+	scene.Enter(s)
+}
+
+// Reset exits every scene, top first, and enters scene on the empty stack
+func (s *Stack) Reset(scene Scene) {
+	s.Clear()
+	s.scenes = append(s.scenes, scene)
+	scene.Enter(s)
+}
+
+// Clear exits every scene, top first
+func (s *Stack) Clear() {
+	for len(s.scenes) > 0 {
+		top := s.scenes[len(s.scenes)-1]
+		s.scenes[len(s.scenes)-1] = nil
+		s.scenes = s.scenes[:len(s.scenes)-1]
+		top.Exit()
+	}
+}
+
+// Update lets the top scene handle input and then advances it by steps fixed
+// steps. Stepping stops early once a transition puts another scene on top.
+func (s *Stack) Update(steps int, deltaTime float64) {
+	top := s.Top()
+	if top == nil {
+		return
+	}
+	top.HandleInput()
+	for range steps {
+		if s.Top() != top {
+			return
+		}
+		top.Step(deltaTime)
+	}
+}
+
+// Draw renders the top scene over every scene it overlays, bottom first
+func (s *Stack) Draw(screen *ebiten.Image) {
+	first := len(s.scenes) - 1
+	for first > 0 && s.scenes[first].IsOverlay() {
+		first--
+	}
+	for i := max(first, 0); i < len(s.scenes); i++ {
+		s.scenes[i].Draw(screen)
+	}
+}
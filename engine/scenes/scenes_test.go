@@ -0,0 +1,189 @@
+package scenes
+
+import (
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/systems"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// recordingScene logs its lifecycle calls to a shared log
+type recordingScene struct {
+	BaseScene
+	name    string
+	log     *[]string
+	onInput func()
+}
+
+func newRecordingScene(name string, log *[]string) *recordingScene {
+	return &recordingScene{name: name, log: log}
+}
+
+func (rs *recordingScene) record(call string) {
+	*rs.log = append(*rs.log, rs.name+"."+call)
+}
+
+func (rs *recordingScene) Enter(stack *Stack) {
+	rs.BaseScene.Enter(stack)
+	rs.record("enter")
+}
+
+func (rs *recordingScene) Exit()   { rs.record("exit") }
+func (rs *recordingScene) Pause()  { rs.record("pause") }
+func (rs *recordingScene) Resume() { rs.record("resume") }
+
+func (rs *recordingScene) HandleInput() {
+	rs.record("input")
+	if rs.onInput != nil {
+		rs.onInput()
+	}
+}
+
+func (rs *recordingScene) Step(deltaTime float64) { rs.record("step") }
+
+func (rs *recordingScene) Draw(screen *ebiten.Image) { rs.record("draw") }
+
+// countingSystem counts its updates
+type countingSystem struct {
+	updates int
+}
+
+func (cs *countingSystem) Update(deltaTime float64, entities []systems.Entity, eventDispatcher *events.EventDispatcher) {
+	cs.updates++
+}
+
+func (cs *countingSystem) GetRequiredComponents() []string {
+	return nil
+}
+
+// TestStack_Transitions tests the hooks called by push, pop, replace and reset
+func TestStack_Transitions(t *testing.T) {
+	var log []string
+	stack := &Stack{}
+	menu := newRecordingScene("menu", &log)
+	game := newRecordingScene("game", &log)
+	pause := newRecordingScene("pause", &log)
+
+	stack.Push(menu)
+	stack.Replace(game)
+	stack.Push(pause)
+	if stack.Len() != 2 || stack.Top() != pause {
+		t.Fatalf("Expected pause on top of game, got %d scenes", stack.Len())
+	}
+	if game.Stack() != stack {
+		t.Error("Expected entered scenes to know their stack")
+	}
+	if popped := stack.Pop(); popped != pause {
+		t.Errorf("Expected pause to be popped, got %v", popped)
+	}
+	stack.Push(pause)
+	stack.Reset(menu)
+
+	expected := []string{
+		"menu.enter",
+		"menu.exit", "game.enter",
+		"game.pause", "pause.enter",
+		"pause.exit", "game.resume",
+		"game.pause", "pause.enter",
+		"pause.exit", "game.exit", "menu.enter",
+	}
+	if strings.Join(log, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, log)
+	}
+
+	stack.Clear()
+	if stack.Top() != nil || stack.Pop() != nil {
+		t.Error("Expected a cleared stack to be empty")
+	}
+}
+
+// TestStack_UpdateStopsAfterTransition tests that a scene stops stepping once it is no longer on top
+func TestStack_UpdateStopsAfterTransition(t *testing.T) {
+	var log []string
+	stack := &Stack{}
+	game := newRecordingScene("game", &log)
+	pause := newRecordingScene("pause", &log)
+	stack.Push(game)
+	log = nil
+
+	stack.Update(2, 0.1)
+	game.onInput = func() { stack.Push(pause) }
+	stack.Update(2, 0.1)
+
+	expected := []string{"game.input", "game.step", "game.step", "game.input", "game.pause", "pause.enter"}
+	if strings.Join(log, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, log)
+	}
+
+	// An empty stack does nothing
+	(&Stack{}).Update(1, 0.1)
+}
+
+// TestStack_DrawOverlays tests that overlays are drawn over the scenes beneath them
+func TestStack_DrawOverlays(t *testing.T) {
+	var log []string
+	stack := &Stack{}
+	menu := newRecordingScene("menu", &log)
+	game := newRecordingScene("game", &log)
+	pause := newRecordingScene("pause", &log)
+	pause.Overlay = true
+	stack.Push(menu)
+	stack.Push(game)
+	stack.Push(pause)
+	log = nil
+
+	screen := ebiten.NewImage(1, 1)
+	stack.Draw(screen)
+	stack.Pop()
+	stack.Draw(screen)
+
+	expected := []string{"game.draw", "pause.draw", "pause.exit", "game.resume", "game.draw"}
+	if strings.Join(log, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, log)
+	}
+}
+
+// TestBaseScene_Step tests that a scene updates its own systems each step
+func TestBaseScene_Step(t *testing.T) {
+	first, second := &countingSystem{}, &countingSystem{}
+	entitiesCalls := 0
+	scene := &BaseScene{
+		Systems: []systems.System{first, second},
+		Entities: func() []systems.Entity {
+			entitiesCalls++
+			return nil
+		},
+	}
+	stack := &Stack{}
+	stack.Push(scene)
+	stack.Update(3, 1.0/60)
+
+	if first.updates != 3 || second.updates != 3 {
+		t.Errorf("Expected every system to update each step, got %d and %d", first.updates, second.updates)
+	}
+	if entitiesCalls != 3 {
+		t.Errorf("Expected entities to be listed once per step, got %d", entitiesCalls)
+	}
+}
+
+// TestBaseScene_Subscriptions tests that scene handlers are removed when the scene exits
+func TestBaseScene_Subscriptions(t *testing.T) {
+	bus := events.NewEventDispatcher()
+	scene := &BaseScene{Dispatcher: bus}
+	levels := 0
+	stack := &Stack{}
+	stack.Push(scene)
+	events.Subscribe(scene.Subscriptions(), func(events.LevelUp) {
+		levels++
+	})
+
+	events.Publish(bus, events.LevelUp{Level: 2})
+	stack.Pop()
+	events.Publish(bus, events.LevelUp{Level: 3})
+
+	if levels != 1 {
+		t.Errorf("Expected the handler to stop with the scene, got %d calls", levels)
+	}
+}
@@ -1,18 +1,27 @@
 package systems
 
 import (
-	"fmt"
+	"encoding/json"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 )
 
 const SystemTypeBackend SystemType = "backend"
 
+var backendLog = logging.For(string(SystemTypeBackend))
+
 type BackendSystem struct {
 	BaseSystem
 	backendCounters map[int]int // backend ID -> packet count
 	totalPackets    int
 }
 
+func init() {
+	Register(SystemTypeBackend, func(ctx *SystemContext) SystemInfoer {
+		return NewBackendSystem()
+	})
+}
+
 func NewBackendSystem() *BackendSystem {
 	return &BackendSystem{
 		BaseSystem: BaseSystem{
@@ -30,19 +39,22 @@ func (bs *BackendSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeBackend,
 		System:       bs,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"backend_assignment", "load_balancing"},
-		Requires:     []string{},
+		Requires:     []string{"packet_catching"},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhasePostUpdate,
+		Reads:        []string{"BackendAssignment", EventResource(events.EventPacketCaught), EventResource(events.EventGameStart)},
+		Writes:       []string{"BackendAssignment"},
 	}
 }
 
 func (bs *BackendSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
 	// First, read current entity counters to sync our internal state
 	// But only if we haven't already assigned packets (to avoid overwriting our internal state)
-	for _, entity := range bs.FilterEntities(entities) {
+	for _, entity := range bs.RequiredEntities(entities) {
 		backendComp := entity.GetBackendAssignment()
 		if backendComp == nil {
 			continue
@@ -60,7 +72,7 @@ func (bs *BackendSystem) Update(deltaTime float64, entities []Entity, eventDispa
 	}
 
 	// Then, update entity counters to match our internal tracking
-	for _, entity := range bs.FilterEntities(entities) {
+	for _, entity := range bs.RequiredEntities(entities) {
 		backendComp := entity.GetBackendAssignment()
 		if backendComp == nil {
 			continue
@@ -80,15 +92,17 @@ func (bs *BackendSystem) Update(deltaTime float64, entities []Entity, eventDispa
 }
 
 func (bs *BackendSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := bs.subscriptionScope(eventDispatcher)
+
 	// Listen for packet caught events to assign to backends
-	eventDispatcher.Subscribe(events.EventPacketCaught, func(event *events.Event) {
+	events.Subscribe(scope, func(events.PacketCaught) {
 		// We need entities to update, but we don't have them in the event
 		// So we'll just update internal counters and let Update method sync them
 		bs.assignPacketToBackend()
 	})
 
 	// Listen for game start events to reset backend counters
-	eventDispatcher.Subscribe(events.EventGameStart, func(event *events.Event) {
+	events.Subscribe(scope, func(events.GameStart) {
 		bs.resetBackendCounters()
 	})
 }
@@ -99,7 +113,7 @@ func (bs *BackendSystem) InitializeBackendCounters(entities []Entity) {
 		if backendComp := entity.GetBackendAssignment(); backendComp != nil {
 			backendID := backendComp.GetBackendID()
 			bs.backendCounters[backendID] = 0
-			fmt.Printf("[BackendSystem] Initialized counter for backend %d\n", backendID)
+			backendLog.Debug("Initialized counter", "backend", backendID)
 		}
 	}
 }
@@ -126,8 +140,36 @@ func (bs *BackendSystem) assignPacketToBackend() {
 	bs.backendCounters[selectedBackend]++
 	bs.totalPackets++
 
-	fmt.Printf("Packet assigned to backend %d (total: %d, backend count: %d)\n",
-		selectedBackend, bs.totalPackets, bs.backendCounters[selectedBackend])
+	backendLog.Debug("Packet assigned", "backend", selectedBackend,
+		"total", bs.totalPackets, "backendCount", bs.backendCounters[selectedBackend])
+}
+
+// backendState is the serialized form of the backend system's counters
+type backendState struct {
+	BackendCounters map[int]int `json:"backendCounters"`
+	TotalPackets    int         `json:"totalPackets"`
+}
+
+// SaveState implements StatefulSystem
+func (bs *BackendSystem) SaveState() ([]byte, error) {
+	return json.Marshal(backendState{
+		BackendCounters: bs.backendCounters,
+		TotalPackets:    bs.totalPackets,
+	})
+}
+
+// LoadState implements StatefulSystem
+func (bs *BackendSystem) LoadState(data []byte) error {
+	var state backendState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.BackendCounters == nil {
+		state.BackendCounters = make(map[int]int)
+	}
+	bs.backendCounters = state.BackendCounters
+	bs.totalPackets = state.TotalPackets
+	return nil
 }
 
 func (bs *BackendSystem) GetBackendStats() map[int]int {
@@ -142,5 +184,5 @@ func (bs *BackendSystem) GetTotalPackets() int {
 func (bs *BackendSystem) resetBackendCounters() {
 	bs.backendCounters = make(map[int]int)
 	bs.totalPackets = 0
-	fmt.Println("[BackendSystem] Reset backend counters")
+	backendLog.Debug("Reset backend counters")
 }
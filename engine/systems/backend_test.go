@@ -396,3 +396,26 @@ type mockComponent struct {
 func (mc *mockComponent) GetType() string {
 	return mc.componentType
 }
+
+// TestBackendSystem_SaveLoadState tests that per-backend counters survive a roundtrip
+func TestBackendSystem_SaveLoadState(t *testing.T) {
+	bs := NewBackendSystem()
+	bs.backendCounters[0] = 4
+	bs.backendCounters[2] = 7
+	bs.totalPackets = 11
+
+	data, err := bs.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewBackendSystem()
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	stats := restored.GetBackendStats()
+	if stats[0] != 4 || stats[2] != 7 || restored.GetTotalPackets() != 11 {
+		t.Errorf("Expected counters {0:4 2:7} total 11, got %v total %d", stats, restored.GetTotalPackets())
+	}
+}
@@ -1,17 +1,28 @@
 package systems
 
 import (
-	"fmt"
+	"context"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"log/slog"
 )
 
 const SystemTypeCleanup SystemType = "cleanup"
 
-// CleanupSystem removes inactive entities from the world
+var cleanupLog = logging.For(string(SystemTypeCleanup))
+
+// CleanupSystem reports the entities deactivated during the frame. It runs in
+// the cleanup phase; the manager removes the entities at the end of it.
 type CleanupSystem struct {
 	BaseSystem
 }
 
+func init() {
+	Register(SystemTypeCleanup, func(ctx *SystemContext) SystemInfoer {
+		return NewCleanupSystem()
+	})
+}
+
 func NewCleanupSystem() *CleanupSystem {
 	return &CleanupSystem{
 		BaseSystem: BaseSystem{
@@ -25,12 +36,15 @@ func (cs *CleanupSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeCleanup,
 		System:       cs,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"entity_cleanup", "memory_management"},
-		Requires:     []string{},
+		Requires:     []string{"collision_detection"},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhaseCleanup,
+		Reads:        []string{EventResource(events.EventGameStart)},
+		Writes:       []string{},
 	}
 }
 
@@ -44,25 +58,29 @@ func (cs *CleanupSystem) Update(deltaTime float64, entities []Entity, eventDispa
 	}
 
 	if inactiveCount > 0 {
-		fmt.Printf("[CleanupSystem] Found %d inactive entities to remove\n", inactiveCount)
+		cleanupLog.Debug("Found inactive entities to remove", "count", inactiveCount)
 
 		// Log inactive entities for debugging
-		for _, entity := range entities {
-			if !entity.IsActive() {
-				if entityInterface, ok := entity.(interface{ GetComponentNames() []string }); ok {
-					fmt.Printf("[CleanupSystem] Found inactive entity with components: %v\n", entityInterface.GetComponentNames())
+		if cleanupLog.Enabled(context.Background(), slog.LevelDebug) {
+			for _, entity := range entities {
+				if !entity.IsActive() {
+					if entityInterface, ok := entity.(interface{ GetComponentNames() []string }); ok {
+						cleanupLog.Debug("Found inactive entity", "components", entityInterface.GetComponentNames())
+					}
 				}
 			}
 		}
 
-		// Note: Actual removal is handled by the main game loop calling World.RemoveInactiveEntities()
-		// This system just identifies and logs inactive entities
+		// The entities are removed from the world at the end of the phase,
+		// when the manager calls RemoveInactiveEntities
 	}
 }
 
 func (cs *CleanupSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := cs.subscriptionScope(eventDispatcher)
+
 	// Subscribe to game state change events to trigger cleanup
-	eventDispatcher.Subscribe(events.EventGameStart, func(event *events.Event) {
-		fmt.Println("[CleanupSystem] Game start event received, cleanup will be handled by main game loop")
+	events.Subscribe(scope, func(events.GameStart) {
+		cleanupLog.Debug("Game start event received, inactive entities are removed at the end of each phase")
 	})
 }
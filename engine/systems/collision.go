@@ -1,16 +1,34 @@
 package systems
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"log/slog"
+	"time"
 )
 
 const SystemTypeCollision SystemType = "collision"
 
+var (
+	collisionLog      = logging.For(string(SystemTypeCollision))
+	collisionFrameLog = logging.Every(collisionLog, time.Second)
+)
+
 type CollisionSystem struct {
 	BaseSystem
 	score int
+	// packets and powerUps are reused from frame to frame
+	packets  []collisionBody
+	powerUps []collisionBody
+}
+
+func init() {
+	Register(SystemTypeCollision, func(ctx *SystemContext) SystemInfoer {
+		return NewCollisionSystem()
+	})
 }
 
 func NewCollisionSystem() *CollisionSystem {
@@ -36,68 +54,53 @@ func (cs *CollisionSystem) GetSystemInfo() *SystemInfo {
 		Requires:     []string{},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhaseUpdate,
+		Reads:        []string{"Transform", "Collider", "PacketType", "PowerUpType", "Physics"},
+		Writes:       []string{"Routing", "Physics", "BackendAssignment", EventResource(events.EventPacketCaught), EventResource(events.EventPacketLost), EventResource(events.EventPowerUpCollected)},
 	}
 }
 
+// collisionBody is an entity taking part in collisions, with the components
+// checked against
+type collisionBody struct {
+	entity    Entity
+	transform *components.Transform
+	collider  *components.Collider
+}
+
 func (cs *CollisionSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
 	// Get load balancer
-	var loadBalancer Entity
-	var packets []Entity
-	var powerUps []Entity
+	var loadBalancer collisionBody
+	packets := cs.packets[:0]
+	powerUps := cs.powerUps[:0]
 
 	// Separate entities by type
-	for _, entity := range cs.FilterEntities(entities) {
-		colliderComp := entity.GetCollider()
-		if colliderComp == nil {
-			continue
-		}
-		collider := colliderComp
+	for entity, row := range Query2[*components.Transform, *components.Collider](cs.tables, entities) {
+		cs.processed++
+		body := collisionBody{entity: entity, transform: row.First, collider: row.Second}
 
-		if entityInterface, ok := entity.(interface{ GetComponentNames() []string }); ok {
-			fmt.Printf("[CollisionSystem] Checking entity with components: %v, collider tag: %s\n", entityInterface.GetComponentNames(), collider.GetTag())
-		}
-
-		// Check if entity is active and visible
-		if !entity.IsActive() {
-			continue
+		if entityInterface, ok := entity.(interface{ GetComponentNames() []string }); ok && collisionFrameLog.Enabled(context.Background(), slog.LevelDebug) {
+			collisionFrameLog.Debug("Checking entity", "components", entityInterface.GetComponentNames(), "tag", body.collider.GetTag())
 		}
 
 		// Categorize entities
-		if collider.GetTag() == "loadbalancer" {
-			loadBalancer = entity
+		if body.collider.GetTag() == "loadbalancer" {
+			loadBalancer = body
 		} else if entity.HasComponent("PacketType") && !entity.HasComponent("Routing") {
 			// Only process packets that are not already being routed
-			packets = append(packets, entity)
+			packets = append(packets, body)
 		} else if entity.HasComponent("PowerUpType") {
-			powerUps = append(powerUps, entity)
+			powerUps = append(powerUps, body)
 		}
 	}
 
 	// Check for packet collisions with load balancer
-	if loadBalancer != nil {
-		lbTransformComp := loadBalancer.GetTransform()
-		lbColliderComp := loadBalancer.GetCollider()
-		if lbTransformComp == nil || lbColliderComp == nil {
-			return
-		}
-
-		lbTransform := lbTransformComp
-		lbCollider := lbColliderComp
-
+	if loadBalancer.entity != nil {
 		for _, packet := range packets {
-			packetTransformComp := packet.GetTransform()
-			packetColliderComp := packet.GetCollider()
-			if packetTransformComp == nil || packetColliderComp == nil {
-				continue
-			}
-
-			packetTransform := packetTransformComp
-			packetCollider := packetColliderComp
-
 			// Check collision
-			if cs.checkCollision(lbTransform, lbCollider, packetTransform, packetCollider) {
+			if cs.checkCollision(loadBalancer.transform, loadBalancer.collider, packet.transform, packet.collider) {
 				// Packet caught by load balancer - route it instead of destroying
-				cs.routePacket(packet, loadBalancer, entities, eventDispatcher)
+				cs.routePacket(packet.entity, loadBalancer.entity, entities, eventDispatcher)
 				// Update score immediately for backward compatibility with tests
 				cs.score += 10
 			}
@@ -105,31 +108,22 @@ func (cs *CollisionSystem) Update(deltaTime float64, entities []Entity, eventDis
 
 		// Check for power-up collisions
 		for _, powerUp := range powerUps {
-			powerUpTransformComp := powerUp.GetTransform()
-			powerUpColliderComp := powerUp.GetCollider()
-			if powerUpTransformComp == nil || powerUpColliderComp == nil {
-				continue
-			}
-
-			powerUpTransform := powerUpTransformComp
-			powerUpCollider := powerUpColliderComp
-
-			if cs.checkCollision(lbTransform, lbCollider, powerUpTransform, powerUpCollider) {
+			if cs.checkCollision(loadBalancer.transform, loadBalancer.collider, powerUp.transform, powerUp.collider) {
 				// Power-up collected!
-				powerUpTypeComp := powerUp.GetPowerUpType()
+				powerUpTypeComp := powerUp.entity.GetPowerUpType()
 				if powerUpTypeComp != nil {
 					powerUpType := powerUpTypeComp
-					fmt.Printf("Power-up collected: %s\n", powerUpType.GetName())
+					collisionLog.Info("Power-up collected", "powerUp", powerUpType.GetName())
 
 					// Deactivate power-up
-					powerUp.(interface{ SetActive(bool) }).SetActive(false)
+					cs.destroyEntity(powerUp.entity)
 
 					// Publish power-up collected event
 					powerupName := powerUpType.GetName()
-					eventDispatcher.Publish(events.NewEvent(events.EventPowerUpCollected, &events.EventData{
-						Powerup: &powerupName,
-						Packet:  powerUp,
-					}))
+					events.Publish(eventDispatcher, events.PowerUpCollected{
+						PowerUp: HandleOf(powerUp.entity),
+						Name:    powerupName,
+					})
 				}
 			}
 		}
@@ -137,23 +131,42 @@ func (cs *CollisionSystem) Update(deltaTime float64, entities []Entity, eventDis
 
 	// Check for packets that fell off screen
 	for _, packet := range packets {
-		transformComp := packet.GetTransform()
-		if transformComp == nil {
-			continue
-		}
-		transform := transformComp
-
-		if transform.GetY() > 600 {
+		if packet.transform.GetY() > 600 {
 			// Packet missed
-			packet.(interface{ SetActive(bool) }).SetActive(false)
-			fmt.Printf("Packet missed! Score: %d\n", cs.score)
+			cs.destroyEntity(packet.entity)
+			collisionLog.Info("Packet missed", "score", cs.score)
 
 			// Publish packet lost event
-			eventDispatcher.Publish(events.NewEvent(events.EventPacketLost, &events.EventData{
-				Score: &cs.score,
-			}))
+			events.Publish(eventDispatcher, events.PacketLost{
+				Score: cs.score,
+			})
 		}
 	}
+
+	// The bodies are dropped so that the buffers do not keep entities alive
+	clear(packets)
+	clear(powerUps)
+	cs.packets, cs.powerUps = packets[:0], powerUps[:0]
+}
+
+// collisionState is the saved state of the collision system
+type collisionState struct {
+	Score int `json:"score"`
+}
+
+// SaveState implements StatefulSystem
+func (cs *CollisionSystem) SaveState() ([]byte, error) {
+	return json.Marshal(collisionState{Score: cs.score})
+}
+
+// LoadState implements StatefulSystem
+func (cs *CollisionSystem) LoadState(data []byte) error {
+	var state collisionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	cs.score = state.Score
+	return nil
 }
 
 func (cs *CollisionSystem) checkCollision(transform1 components.TransformComponent, collider1 components.ColliderComponent,
@@ -191,7 +204,7 @@ func (cs *CollisionSystem) routePacket(packet Entity, loadBalancer Entity, entit
 
 	if len(backends) == 0 {
 		// No backends available, destroy packet
-		packet.(interface{ SetActive(bool) }).SetActive(false)
+		cs.destroyEntity(packet)
 		return
 	}
 
@@ -224,18 +237,18 @@ func (cs *CollisionSystem) routePacket(packet Entity, loadBalancer Entity, entit
 	}
 
 	// Add routing component to packet with original speed
-	packet.AddComponent(components.NewRouting(backendIndex, originalSpeed))
+	cs.addComponent(packet, components.NewRouting(HandleOf(selectedBackend), originalSpeed))
 
 	// Update packet to route to backend
 	cs.updatePacketForRouting(packet, selectedBackend)
 
-	fmt.Printf("Packet routed to backend %d! Score: %d\n", backendIndex, cs.score)
+	collisionLog.Debug("Packet routed", "backend", backendIndex, "score", cs.score)
 
 	// Publish packet caught event (for routing visualization)
-	eventDispatcher.Publish(events.NewEvent(events.EventPacketCaught, &events.EventData{
-		Score:  &cs.score,
-		Packet: packet,
-	}))
+	events.Publish(eventDispatcher, events.PacketCaught{
+		Packet: HandleOf(packet),
+		Score:  cs.score,
+	})
 }
 
 // updatePacketForRouting updates the packet to move toward the backend
@@ -407,3 +407,47 @@ func createPowerUpEntity(id uint64, x, y float64, name string) Entity {
 	entity.AddComponent(powerUpType)
 	return entity
 }
+
+// TestCollisionSystem_Update_WithCommandBuffer tests that misses and routing are deferred to the buffer
+func TestCollisionSystem_Update_WithCommandBuffer(t *testing.T) {
+	cs := NewCollisionSystem()
+	commands := &stubCommands{}
+	cs.SetCommands(commands)
+	eventDispatcher := events.NewEventDispatcher()
+
+	loadBalancer := createLoadBalancerEntity(1, 100, 100)
+	missed := createPacketEntity(2, 100, 650)
+
+	cs.Update(0.016, []Entity{loadBalancer, missed}, eventDispatcher)
+
+	if !missed.IsActive() {
+		t.Error("Expected missed packet to stay active until the flush")
+	}
+	if commands.Len() != 1 {
+		t.Errorf("Expected 1 recorded command, got %d", commands.Len())
+	}
+
+	commands.Flush()
+	if missed.IsActive() {
+		t.Error("Expected missed packet to be deactivated by the flush")
+	}
+}
+
+// TestCollisionSystem_SaveLoadState tests that the score survives a roundtrip
+func TestCollisionSystem_SaveLoadState(t *testing.T) {
+	cs := NewCollisionSystem()
+	cs.score = 40
+
+	data, err := cs.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewCollisionSystem()
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if restored.score != 40 {
+		t.Errorf("Expected score 40, got %d", restored.score)
+	}
+}
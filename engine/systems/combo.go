@@ -1,13 +1,16 @@
 package systems
 
 import (
-	"fmt"
+	"encoding/json"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 )
 
 const SystemTypeCombo SystemType = "combo"
 
+var comboLog = logging.For(string(SystemTypeCombo))
+
 type ComboSystem struct {
 	BaseSystem
 	currentCombo  int
@@ -16,6 +19,12 @@ type ComboSystem struct {
 	lastComboTime float64
 }
 
+func init() {
+	Register(SystemTypeCombo, func(ctx *SystemContext) SystemInfoer {
+		return NewComboSystem()
+	})
+}
+
 func NewComboSystem() *ComboSystem {
 	return &ComboSystem{
 		BaseSystem: BaseSystem{
@@ -35,12 +44,15 @@ func (cs *ComboSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeCombo,
 		System:       cs,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"combo_tracking", "score_multiplier"},
-		Requires:     []string{},
+		Requires:     []string{"packet_catching"},
 		Drawable:     false,
 		Optional:     true,
+		Phase:        PhasePostUpdate,
+		Reads:        []string{"Combo", EventResource(events.EventPacketCaught)},
+		Writes:       []string{"Combo", EventResource(events.EventComboAchieved)},
 	}
 }
 
@@ -50,13 +62,13 @@ func (cs *ComboSystem) Update(deltaTime float64, entities []Entity, eventDispatc
 	// Check if combo has expired
 	if cs.comboTimer-cs.lastComboTime > cs.comboTimeout {
 		if cs.currentCombo > 1 {
-			fmt.Printf("Combo expired! Final combo: %d\n", cs.currentCombo)
+			comboLog.Debug("Combo expired", "combo", cs.currentCombo)
 		}
 		cs.currentCombo = 0
 	}
 
 	// Update combo components for any entities that have them
-	for _, entity := range cs.FilterEntities(entities) {
+	for _, entity := range cs.RequiredEntities(entities) {
 		comboComp := entity.GetCombo()
 		if comboComp == nil {
 			continue
@@ -70,8 +82,10 @@ func (cs *ComboSystem) Update(deltaTime float64, entities []Entity, eventDispatc
 }
 
 func (cs *ComboSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := cs.subscriptionScope(eventDispatcher)
+
 	// Listen for packet caught events to update combo
-	eventDispatcher.Subscribe(events.EventPacketCaught, func(event *events.Event) {
+	events.Subscribe(scope, func(events.PacketCaught) {
 		cs.currentCombo++
 		cs.lastComboTime = cs.comboTimer
 
@@ -79,15 +93,14 @@ func (cs *ComboSystem) Initialize(eventDispatcher *events.EventDispatcher) {
 		bonusPoints := cs.calculateComboBonus()
 
 		if cs.currentCombo > 1 {
-			fmt.Printf("Combo! x%d (+%d bonus points)\n", cs.currentCombo, bonusPoints)
+			comboLog.Debug("Combo", "combo", cs.currentCombo, "bonus", bonusPoints)
 		}
 
 		// Publish combo event with bonus points
-		comboEvent := events.NewEvent(events.EventType("combo_achieved"), &events.EventData{
-			ComboCount:  &cs.currentCombo,
-			BonusPoints: &bonusPoints,
+		events.Publish(eventDispatcher, events.ComboAchieved{
+			Count:       cs.currentCombo,
+			BonusPoints: bonusPoints,
 		})
-		eventDispatcher.Publish(comboEvent)
 	})
 }
 
@@ -107,6 +120,37 @@ func (cs *ComboSystem) calculateComboBonus() int {
 	}
 }
 
+// comboState is the serialized form of the combo system's timers
+type comboState struct {
+	CurrentCombo  int     `json:"currentCombo"`
+	ComboTimer    float64 `json:"comboTimer"`
+	ComboTimeout  float64 `json:"comboTimeout"`
+	LastComboTime float64 `json:"lastComboTime"`
+}
+
+// SaveState implements StatefulSystem
+func (cs *ComboSystem) SaveState() ([]byte, error) {
+	return json.Marshal(comboState{
+		CurrentCombo:  cs.currentCombo,
+		ComboTimer:    cs.comboTimer,
+		ComboTimeout:  cs.comboTimeout,
+		LastComboTime: cs.lastComboTime,
+	})
+}
+
+// LoadState implements StatefulSystem
+func (cs *ComboSystem) LoadState(data []byte) error {
+	var state comboState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	cs.currentCombo = state.CurrentCombo
+	cs.comboTimer = state.ComboTimer
+	cs.comboTimeout = state.ComboTimeout
+	cs.lastComboTime = state.LastComboTime
+	return nil
+}
+
 func (cs *ComboSystem) GetCurrentCombo() int {
 	return cs.currentCombo
 }
@@ -464,3 +464,28 @@ func createComboEntity(id uint64) Entity {
 	entity.AddComponent(combo)
 	return entity
 }
+
+// TestComboSystem_SaveLoadState tests that the combo and its timer survive a roundtrip
+func TestComboSystem_SaveLoadState(t *testing.T) {
+	cs := NewComboSystem()
+	cs.currentCombo = 6
+	cs.comboTimer = 1.25
+	cs.lastComboTime = 42
+
+	data, err := cs.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewComboSystem()
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if restored.GetCurrentCombo() != 6 || restored.comboTimer != 1.25 || restored.lastComboTime != 42 {
+		t.Errorf("Expected combo 6 with timer 1.25, got %d with timer %f", restored.GetCurrentCombo(), restored.comboTimer)
+	}
+	if restored.comboTimeout != cs.comboTimeout {
+		t.Errorf("Expected combo timeout %f, got %f", cs.comboTimeout, restored.comboTimeout)
+	}
+}
@@ -9,6 +9,7 @@ import (
 type SystemFactory struct {
 	entityFactory   func() Entity
 	eventDispatcher *events.EventDispatcher
+	quiet           bool
 }
 
 // NewSystemFactory creates a new system factory
@@ -19,68 +20,81 @@ func NewSystemFactory(entityFactory func() Entity, eventDispatcher *events.Event
 	}
 }
 
-// CreateSystemManager creates and configures all systems with proper dependencies
+// SetQuiet stops the managers the factory creates from logging how they
+// resolve the execution order
+func (sf *SystemFactory) SetQuiet(quiet bool) {
+	sf.quiet = quiet
+}
+
+// CreateSystemManager creates a manager with every registered system enabled
 func (sf *SystemFactory) CreateSystemManager() (*SystemManager, error) {
+	return sf.CreateConfiguredSystemManager(SystemConfig{})
+}
+
+// CreateConfiguredSystemManager creates every registered system and enables
+// the ones config chooses
+func (sf *SystemFactory) CreateConfiguredSystemManager(config SystemConfig) (*SystemManager, error) {
+	manager, err := sf.ResolveSystemManager(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe the enabled systems to events
+	manager.AttachEvents(sf.eventDispatcher)
+
+	if !sf.quiet {
+		manager.LogExecutionOrder()
+	}
+
+	return manager, nil
+}
+
+// ResolveSystemManager creates every registered system and builds the
+// execution order of the ones config enables, without subscribing them to
+// events. It is meant for inspecting the order rather than running it.
+func (sf *SystemFactory) ResolveSystemManager(config SystemConfig) (*SystemManager, error) {
 	manager := NewSystemManager()
+	if sf.quiet {
+		manager.SetVerbose(false)
+	}
 
-	// Create all systems
-	spawnSys := NewSpawnSystem(sf.entityFactory)
-	inputSys := NewInputSystem()
-	movementSys := NewMovementSystem()
-	collisionSys := NewCollisionSystem()
-	powerUpSys := NewPowerUpSystem()
-	backendSys := NewBackendSystem()
-	slaSys := NewSLASystem(spawnSys)
-	comboSys := NewComboSystem()
-	gameStateSys := NewGameStateSystem()
-	particleSys := NewParticleSystem()
-	routingSys := NewRoutingSystem()
-	packetRoutingSys := NewPacketRoutingSystem()
-	cleanupSys := NewCleanupSystem()
-
-	// Register all ECS systems using SystemInfoer
-	systems := []SystemInfoer{
-		spawnSys,
-		inputSys,
-		movementSys,
-		collisionSys,
-		powerUpSys,
-		backendSys,
-		slaSys,
-		comboSys,
-		gameStateSys,
-		particleSys,
-		routingSys,
-		packetRoutingSys,
-		cleanupSys,
+	registered := RegisteredSystemTypes()
+	disabled, err := config.disabledTypes(registered)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, sys := range systems {
-		systemInfo := sys.GetSystemInfo()
+	// Create and register all systems
+	ctx := newSystemContext(sf.entityFactory, sf.eventDispatcher)
+	for _, systemType := range registered {
+		system, _ := ctx.System(systemType)
+		systemInfo := system.GetSystemInfo()
 		if err := manager.RegisterSystem(systemInfo); err != nil {
 			return nil, fmt.Errorf("failed to register system %s: %w", systemInfo.Type, err)
 		}
 	}
 
+	// Disable the systems left out, those depended on last
+	for len(disabled) > 0 {
+		remaining := disabled[:0]
+		var lastErr error
+		for _, systemType := range disabled {
+			if err := manager.Disable(systemType); err != nil {
+				remaining = append(remaining, systemType)
+				lastErr = err
+			}
+		}
+		if len(remaining) == len(disabled) {
+			return nil, fmt.Errorf("failed to disable systems: %w", lastErr)
+		}
+		disabled = remaining
+	}
+
 	// Build execution order
 	if err := manager.BuildExecutionOrder(); err != nil {
 		return nil, fmt.Errorf("failed to build execution order: %w", err)
 	}
 
-	// Initialize all systems
-	spawnSys.Initialize(sf.eventDispatcher)
-	backendSys.Initialize(sf.eventDispatcher)
-	slaSys.Initialize(sf.eventDispatcher)
-	comboSys.Initialize(sf.eventDispatcher)
-	gameStateSys.Initialize(sf.eventDispatcher)
-	particleSys.Initialize(sf.eventDispatcher)
-	routingSys.Initialize(sf.eventDispatcher)
-	packetRoutingSys.Initialize(sf.eventDispatcher)
-	cleanupSys.Initialize(sf.eventDispatcher)
-
-	// Print execution order for debugging
-	manager.PrintExecutionOrder()
-
 	return manager, nil
 }
 
@@ -1,13 +1,16 @@
 package systems
 
 import (
-	"fmt"
+	"encoding/json"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 )
 
 const SystemTypeGameState SystemType = "gamestate"
 
+var gameStateLog = logging.For(string(SystemTypeGameState))
+
 type GameStateSystem struct {
 	BaseSystem
 	currentState    components.StateType
@@ -17,6 +20,12 @@ type GameStateSystem struct {
 	lastLevelUpTime float64 // Track when we last leveled up to prevent multiple level-ups
 }
 
+func init() {
+	Register(SystemTypeGameState, func(ctx *SystemContext) SystemInfoer {
+		return NewGameStateSystem()
+	})
+}
+
 func NewGameStateSystem() *GameStateSystem {
 	return &GameStateSystem{
 		BaseSystem: BaseSystem{
@@ -43,6 +52,9 @@ func (gss *GameStateSystem) GetSystemInfo() *SystemInfo {
 		Requires:     []string{},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhasePostUpdate,
+		Reads:        []string{"State", EventResource(events.EventGameStart), EventResource(events.EventGameOver), EventResource(events.EventPacketCaught)},
+		Writes:       []string{"State", EventResource(events.EventLevelUp)},
 	}
 }
 
@@ -50,7 +62,7 @@ func (gss *GameStateSystem) Update(deltaTime float64, entities []Entity, eventDi
 	gss.gameTime += deltaTime
 
 	// Update all entities with state components
-	for _, entity := range gss.FilterEntities(entities) {
+	for _, entity := range gss.RequiredEntities(entities) {
 		stateComp := entity.GetState()
 		if stateComp == nil {
 			continue
@@ -76,22 +88,26 @@ func (gss *GameStateSystem) getStateString() string {
 		return "playing"
 	case components.StateGameOver:
 		return "gameover"
+	case components.StatePaused:
+		return "paused"
 	default:
 		return "unknown"
 	}
 }
 
 func (gss *GameStateSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := gss.subscriptionScope(eventDispatcher)
+
 	// Handle state transitions based on events
-	eventDispatcher.Subscribe(events.EventGameStart, func(event *events.Event) {
+	events.Subscribe(scope, func(events.GameStart) {
 		gss.transitionToPlaying()
 	})
 
-	eventDispatcher.Subscribe(events.EventGameOver, func(event *events.Event) {
+	events.Subscribe(scope, func(events.GameOver) {
 		gss.transitionToGameOver()
 	})
 
-	eventDispatcher.Subscribe(events.EventPacketCaught, func(event *events.Event) {
+	events.Subscribe(scope, func(events.PacketCaught) {
 		if gss.currentState == components.StatePlaying {
 			gss.score += 10
 			gss.checkLevelUp(eventDispatcher)
@@ -105,15 +121,14 @@ func (gss *GameStateSystem) transitionToPlaying() {
 		gss.gameTime = 0.0
 		gss.score = 0
 		gss.level = 1
-		fmt.Println("Game started! Good luck!")
+		gameStateLog.Info("Game started")
 	}
 }
 
 func (gss *GameStateSystem) transitionToGameOver() {
 	if gss.currentState == components.StatePlaying {
 		gss.currentState = components.StateGameOver
-		fmt.Printf("Game Over! Final Score: %d, Level: %d, Time: %.1fs\n",
-			gss.score, gss.level, gss.gameTime)
+		gameStateLog.Info("Game over", "score", gss.score, "level", gss.level, "time", gss.gameTime)
 	}
 }
 
@@ -140,13 +155,46 @@ func (gss *GameStateSystem) checkLevelUp(eventDispatcher *events.EventDispatcher
 func (gss *GameStateSystem) levelUp(eventDispatcher *events.EventDispatcher) {
 	gss.level++
 	gss.lastLevelUpTime = gss.gameTime
-	fmt.Printf("Level up! Now level %d (Score: %d, Time: %.1fs)\n", gss.level, gss.score, gss.gameTime)
+	gameStateLog.Info("Level up", "level", gss.level, "score", gss.score, "time", gss.gameTime)
 
-	levelEvent := events.NewEvent(events.EventLevelUp, &events.EventData{
-		Level: &gss.level,
-		Time:  &gss.gameTime,
+	events.Publish(eventDispatcher, events.LevelUp{
+		Level: gss.level,
+		Time:  gss.gameTime,
 	})
-	eventDispatcher.Publish(levelEvent)
+}
+
+// gameStateState is the serialized form of the game state system's progress
+type gameStateState struct {
+	CurrentState    components.StateType `json:"currentState"`
+	GameTime        float64              `json:"gameTime"`
+	Score           int                  `json:"score"`
+	Level           int                  `json:"level"`
+	LastLevelUpTime float64              `json:"lastLevelUpTime"`
+}
+
+// SaveState implements StatefulSystem
+func (gss *GameStateSystem) SaveState() ([]byte, error) {
+	return json.Marshal(gameStateState{
+		CurrentState:    gss.currentState,
+		GameTime:        gss.gameTime,
+		Score:           gss.score,
+		Level:           gss.level,
+		LastLevelUpTime: gss.lastLevelUpTime,
+	})
+}
+
+// LoadState implements StatefulSystem
+func (gss *GameStateSystem) LoadState(data []byte) error {
+	var state gameStateState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	gss.currentState = state.CurrentState
+	gss.gameTime = state.GameTime
+	gss.score = state.Score
+	gss.level = state.Level
+	gss.lastLevelUpTime = state.LastLevelUpTime
+	return nil
 }
 
 func (gss *GameStateSystem) GetCurrentState() components.StateType {
@@ -685,3 +685,29 @@ func createStateEntity(id uint64, initialState components.StateType) Entity {
 	entity.AddComponent(state)
 	return entity
 }
+
+// TestGameStateSystem_SaveLoadState tests that score, level and time survive a roundtrip
+func TestGameStateSystem_SaveLoadState(t *testing.T) {
+	gss := NewGameStateSystem()
+	gss.currentState = components.StatePlaying
+	gss.score = 1500
+	gss.level = 3
+	gss.gameTime = 95.5
+	gss.lastLevelUpTime = 90
+
+	data, err := gss.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewGameStateSystem()
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if restored.GetCurrentState() != components.StatePlaying || restored.GetScore() != 1500 ||
+		restored.GetLevel() != 3 || restored.GetGameTime() != 95.5 || restored.lastLevelUpTime != 90 {
+		t.Errorf("Expected restored progress, got state=%v score=%d level=%d time=%f", restored.GetCurrentState(),
+			restored.GetScore(), restored.GetLevel(), restored.GetGameTime())
+	}
+}
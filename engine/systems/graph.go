@@ -0,0 +1,177 @@
+package systems
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// GraphFormat selects how WriteGraph renders the system graph
+type GraphFormat string
+
+const (
+	GraphDOT     GraphFormat = "dot"
+	GraphMermaid GraphFormat = "mermaid"
+)
+
+// ParseGraphFormat returns the graph format called name
+func ParseGraphFormat(name string) (GraphFormat, error) {
+	switch format := GraphFormat(strings.ToLower(name)); format {
+	case GraphDOT, GraphMermaid:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (want dot or mermaid)", name)
+	}
+}
+
+// GraphNode is a system in the system graph
+type GraphNode struct {
+	Type    SystemType
+	Enabled bool
+	Phase   Phase
+	Update  int // Position in the update order, from 1; 0 when disabled
+	Draw    int // Position in the draw order, from 1; 0 when not drawn
+	Stage   int // Stage within its phase, from 1; 0 when disabled
+}
+
+// GraphEdge points from a system to one that runs after it because it
+// depends on it, or requires a capability it provides
+type GraphEdge struct {
+	From       SystemType
+	To         SystemType
+	Capability string // Empty for a declared dependency
+}
+
+// SystemGraph is the resolved execution order with the edges that caused it
+type SystemGraph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// Graph returns the system graph as of the last BuildExecutionOrder. Nodes are
+// in update order followed by the disabled systems.
+func (sm *SystemManager) Graph() SystemGraph {
+	stageOf := make(map[SystemType]int)
+	for _, ps := range sm.phases {
+		for systemType, stage := range stageIndex(ps.stages) {
+			stageOf[systemType] = stage
+		}
+	}
+	graph := SystemGraph{}
+	for i, systemType := range sm.updateOrder {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Type:    systemType,
+			Enabled: true,
+			Phase:   sm.systems[systemType].Phase,
+			Update:  i + 1,
+			Draw:    slices.Index(sm.drawOrder, systemType) + 1,
+			Stage:   stageOf[systemType] + 1,
+		})
+	}
+	for _, systemType := range sortedTypes(sm.disabled) {
+		graph.Nodes = append(graph.Nodes, GraphNode{Type: systemType, Phase: sm.disabled[systemType].Phase})
+	}
+
+	for _, systemType := range sm.updateOrder {
+		info := sm.systems[systemType]
+		for _, dep := range info.Dependencies {
+			graph.Edges = append(graph.Edges, GraphEdge{From: dep, To: systemType})
+		}
+		for _, capability := range info.Requires {
+			if providers := sm.resolver.capabilityMap[capability]; len(providers) == 1 {
+				graph.Edges = append(graph.Edges, GraphEdge{From: providers[0], To: systemType, Capability: capability})
+			}
+		}
+	}
+	return graph
+}
+
+// stageIndex maps every system to the index of the stage it runs in
+func stageIndex(stages [][]SystemType) map[SystemType]int {
+	index := make(map[SystemType]int)
+	for i, stage := range stages {
+		for _, systemType := range stage {
+			index[systemType] = i
+		}
+	}
+	return index
+}
+
+// label describes a node by its position in the update and draw orders
+func (n GraphNode) label() string {
+	if !n.Enabled {
+		return fmt.Sprintf("%s (disabled)", n.Type)
+	}
+	label := fmt.Sprintf("%d. %s (%v stage %d)", n.Update, n.Type, n.Phase, n.Stage)
+	if n.Draw > 0 {
+		label += fmt.Sprintf(", draw %d", n.Draw)
+	}
+	return label
+}
+
+// WriteGraph renders the system graph in format. Solid edges are declared
+// dependencies and dashed edges, labelled with the capability, are
+// capability requirements.
+func (sm *SystemManager) WriteGraph(w io.Writer, format GraphFormat) error {
+	graph := sm.Graph()
+	switch format {
+	case GraphDOT:
+		return graph.writeDOT(w)
+	case GraphMermaid:
+		return graph.writeMermaid(w)
+	default:
+		return fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+// writeDOT renders the graph for Graphviz
+func (g SystemGraph) writeDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph systems {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, node := range g.Nodes {
+		attrs := fmt.Sprintf("label=%q", node.label())
+		switch {
+		case !node.Enabled:
+			attrs += ", style=dashed, color=gray"
+		case node.Draw > 0:
+			attrs += ", style=bold"
+		}
+		fmt.Fprintf(&b, "\t%q [%s];\n", node.Type, attrs)
+	}
+	for _, edge := range g.Edges {
+		if edge.Capability == "" {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", edge.From, edge.To)
+		} else {
+			fmt.Fprintf(&b, "\t%q -> %q [label=%q, style=dashed];\n", edge.From, edge.To, edge.Capability)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMermaid renders the graph as a Mermaid flowchart
+func (g SystemGraph) writeMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", node.Type, node.label())
+	}
+	for _, edge := range g.Edges {
+		if edge.Capability == "" {
+			fmt.Fprintf(&b, "\t%s --> %s\n", edge.From, edge.To)
+		} else {
+			fmt.Fprintf(&b, "\t%s -. %s .-> %s\n", edge.From, edge.Capability, edge.To)
+		}
+	}
+	for _, node := range g.Nodes {
+		if !node.Enabled {
+			fmt.Fprintf(&b, "\tstyle %s stroke-dasharray: 5 5\n", node.Type)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
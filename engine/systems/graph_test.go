@@ -0,0 +1,100 @@
+package systems
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestSystemManager_Graph tests the nodes and edges of the game's system graph
+func TestSystemManager_Graph(t *testing.T) {
+	manager, _, err := newTestFactoryManager(t, SystemConfig{Disabled: []SystemType{SystemTypeRouting}})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	graph := manager.Graph()
+	if len(graph.Nodes) != len(RegisteredSystemTypes()) {
+		t.Fatalf("Expected a node per registered system, got %d", len(graph.Nodes))
+	}
+	if first := graph.Nodes[0]; first.Type != SystemTypeInput || first.Phase != PhasePreUpdate || first.Update != 1 {
+		t.Errorf("Expected input to run first, got %+v", first)
+	}
+	if collision := graph.Nodes[1]; collision.Type != SystemTypeCollision || collision.Phase != PhaseUpdate || collision.Stage != 1 {
+		t.Errorf("Expected collision to open the update phase, got %+v", collision)
+	}
+	if last := graph.Nodes[len(graph.Nodes)-1]; last.Type != SystemTypeRouting || last.Enabled || last.Update != 0 {
+		t.Errorf("Expected routing last and disabled, got %+v", last)
+	}
+	particle := graph.Nodes[slices.IndexFunc(graph.Nodes, func(n GraphNode) bool { return n.Type == SystemTypeParticle })]
+	if particle.Draw != 1 {
+		t.Errorf("Expected particle to be drawn first, got %+v", particle)
+	}
+
+	if !slices.Contains(graph.Edges, GraphEdge{From: SystemTypeCollision, To: SystemTypeCombo, Capability: "packet_catching"}) {
+		t.Errorf("Expected a capability edge from collision to combo, got %v", graph.Edges)
+	}
+	if !slices.Contains(graph.Edges, GraphEdge{From: SystemTypeSpawn, To: SystemTypeMovement, Capability: "entity_spawning"}) {
+		t.Errorf("Expected a capability edge from spawn to movement, got %v", graph.Edges)
+	}
+	for _, edge := range graph.Edges {
+		if edge.From == SystemTypeRouting || edge.To == SystemTypeRouting {
+			t.Errorf("Expected no edges of a disabled system, got %v", edge)
+		}
+	}
+}
+
+// TestSystemManager_WriteGraph tests the DOT and Mermaid renderings
+func TestSystemManager_WriteGraph(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	producer := &SystemInfo{Type: "producer", System: &scoreboardSystem{}, Provides: []string{"data"}, Optional: true}
+	consumer := &SystemInfo{Type: "consumer", System: &scoreboardSystem{}, Requires: []string{"data"}, Drawable: true, Optional: true}
+	logger := &SystemInfo{Type: "logger", System: &scoreboardSystem{}, Dependencies: []SystemType{"consumer"}, Optional: true}
+	for _, info := range []*SystemInfo{producer, consumer, logger} {
+		if err := manager.RegisterSystem(info); err != nil {
+			t.Fatalf("Failed to register %s: %v", info.Type, err)
+		}
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+
+	var dot bytes.Buffer
+	if err := manager.WriteGraph(&dot, GraphDOT); err != nil {
+		t.Fatalf("WriteGraph failed: %v", err)
+	}
+	for _, want := range []string{
+		`"producer" [label="1. producer (update stage 1)"];`,
+		`"consumer" [label="2. consumer (update stage 2), draw 1", style=bold];`,
+		`"producer" -> "consumer" [label="data", style=dashed];`,
+		`"consumer" -> "logger";`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("Expected DOT output to contain %s, got:\n%s", want, dot.String())
+		}
+	}
+
+	var mermaid bytes.Buffer
+	if err := manager.WriteGraph(&mermaid, GraphMermaid); err != nil {
+		t.Fatalf("WriteGraph failed: %v", err)
+	}
+	for _, want := range []string{
+		"flowchart LR",
+		`logger["3. logger (update stage 3)"]`,
+		"producer -. data .-> consumer",
+		"consumer --> logger",
+	} {
+		if !strings.Contains(mermaid.String(), want) {
+			t.Errorf("Expected Mermaid output to contain %s, got:\n%s", want, mermaid.String())
+		}
+	}
+
+	if _, err := ParseGraphFormat("png"); err == nil {
+		t.Error("Expected an unknown format to be refused")
+	}
+	if format, err := ParseGraphFormat("DOT"); err != nil || format != GraphDOT {
+		t.Errorf("Expected DOT, got %q, %v", format, err)
+	}
+}
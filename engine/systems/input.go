@@ -17,6 +17,12 @@ type InputSystem struct {
 	keyboardLastUsed  bool   // Track if keyboard was used in the last frame
 }
 
+func init() {
+	Register(SystemTypeInput, func(ctx *SystemContext) SystemInfoer {
+		return NewInputSystem()
+	})
+}
+
 func NewInputSystem() *InputSystem {
 	return &InputSystem{
 		BaseSystem: BaseSystem{
@@ -43,6 +49,9 @@ func (is *InputSystem) GetSystemInfo() *SystemInfo {
 		Requires:     []string{},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhasePreUpdate,
+		Reads:        []string{"State"},
+		Writes:       []string{"Transform", EventResource(events.EventExit)},
 	}
 }
 
@@ -51,7 +60,7 @@ func (is *InputSystem) Update(deltaTime float64, entities []Entity, eventDispatc
 	is.handleKeyboardInput(eventDispatcher)
 
 	// Handle input for load balancer movement
-	filteredEntities := is.FilterEntities(entities)
+	filteredEntities := is.RequiredEntities(entities)
 
 	for _, entity := range filteredEntities {
 		transformComp := entity.GetTransform()
@@ -73,7 +82,7 @@ func (is *InputSystem) Update(deltaTime float64, entities []Entity, eventDispatc
 func (is *InputSystem) handleKeyboardInput(eventDispatcher *events.EventDispatcher) {
 	// Handle Ctrl+X to exit game
 	if ebiten.IsKeyPressed(ebiten.KeyX) && (ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyControlLeft) || ebiten.IsKeyPressed(ebiten.KeyControlRight)) {
-		eventDispatcher.Publish(events.NewEvent(events.EventExit, nil))
+		events.PublishImmediate(eventDispatcher, events.Exit{})
 	}
 }
 
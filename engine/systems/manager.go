@@ -1,12 +1,19 @@
 package systems
 
 import (
+	"errors"
 	"fmt"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"lbbaspack/engine/prefabs"
+	"lbbaspack/engine/random"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+var managerLog = logging.For("systems")
+
 // SystemType represents different types of systems
 type SystemType string
 
@@ -20,6 +27,40 @@ type SystemInfo struct {
 	Requires     []string     // Capabilities this system requires
 	Drawable     bool         // Whether this system has a Draw method
 	Optional     bool         // Whether this system is optional
+	Phase        Phase        // Part of the frame the system runs in
+	// Reads and Writes name the component types and resources (see
+	// EventResource and SystemResource) the system and its event handlers
+	// access. Systems that declare neither run in a stage of their own.
+	Reads  []string
+	Writes []string
+}
+
+// MissingCapabilityError reports a required capability that no enabled
+// system provides
+type MissingCapabilityError struct {
+	System     SystemType
+	Capability string
+	Disabled   []SystemType // Disabled systems that provide the capability
+}
+
+func (e *MissingCapabilityError) Error() string {
+	msg := fmt.Sprintf("system %s requires capability '%s' but no system provides it", e.System, e.Capability)
+	if len(e.Disabled) > 0 {
+		msg += fmt.Sprintf(" (provided by disabled %v)", e.Disabled)
+	}
+	return msg
+}
+
+// AmbiguousCapabilityError reports a required capability that more than one
+// enabled system provides, so the provider to order against is unclear
+type AmbiguousCapabilityError struct {
+	System     SystemType
+	Capability string
+	Providers  []SystemType
+}
+
+func (e *AmbiguousCapabilityError) Error() string {
+	return fmt.Sprintf("system %s requires capability '%s' which is provided by several systems: %v", e.System, e.Capability, e.Providers)
 }
 
 // DependencyResolver implements robust dependency resolution based on libsolv practices
@@ -34,13 +75,34 @@ type DependencyResolver struct {
 	verbose      bool // Enable verbose logging
 }
 
-// SystemManager manages system dependencies and execution order
+// SystemManager manages system dependencies and execution order. Systems
+// can be added, removed, enabled and disabled while the game runs; each
+// change rebuilds the execution order.
 type SystemManager struct {
 	systems     map[SystemType]*SystemInfo
+	disabled    map[SystemType]*SystemInfo
+	built       bool
+	commands    CommandBuffer
 	updateOrder []SystemType
 	drawOrder   []SystemType
+	stages      [][]SystemType
+	phases      []phaseStages
+	mode        ExecutionMode
+	workers     int
 	resolver    *DependencyResolver
 	verbose     bool // Enable verbose logging
+	profiler    *Profiler
+	store       EntityStore
+
+	// What the Attach methods attached, for systems added or enabled later
+	eventDispatcher *events.EventDispatcher
+	views           ViewProvider
+	tables          ComponentTables
+	entityResolver  EntityResolver
+	pool            ComponentPool
+	prefabs         *prefabs.Library
+	rng             *random.RNG
+	renderer        *Renderer
 }
 
 // NewDependencyResolver creates a new dependency resolver
@@ -58,14 +120,18 @@ func NewDependencyResolver() *DependencyResolver {
 func NewSystemManager() *SystemManager {
 	return &SystemManager{
 		systems:     make(map[SystemType]*SystemInfo),
+		disabled:    make(map[SystemType]*SystemInfo),
 		updateOrder: make([]SystemType, 0),
 		drawOrder:   make([]SystemType, 0),
+		stages:      make([][]SystemType, 0),
+		workers:     defaultWorkers(),
 		resolver:    NewDependencyResolver(),
 		verbose:     true, // Enable verbose logging by default
 	}
 }
 
-// SetVerbose enables or disables verbose logging
+// SetVerbose enables or disables debug logging of registration and
+// dependency resolution
 func (sm *SystemManager) SetVerbose(verbose bool) {
 	sm.verbose = verbose
 	sm.resolver.verbose = verbose
@@ -74,16 +140,17 @@ func (sm *SystemManager) SetVerbose(verbose bool) {
 // RegisterSystem registers a system with its metadata
 func (sm *SystemManager) RegisterSystem(info *SystemInfo) error {
 	// Check for duplicate registration
-	if _, exists := sm.systems[info.Type]; exists {
+	if sm.isRegistered(info.Type) {
 		return fmt.Errorf("system %s is already registered", info.Type)
 	}
+	if info.Phase.rank() < 0 {
+		return fmt.Errorf("system %s has unknown phase %v", info.Type, info.Phase)
+	}
 
 	if sm.verbose {
-		fmt.Printf("[SystemManager] Registering system: %s\n", info.Type)
-		fmt.Printf("  - Dependencies: %v\n", info.Dependencies)
-		fmt.Printf("  - Provides: %v\n", info.Provides)
-		fmt.Printf("  - Requires: %v\n", info.Requires)
-		fmt.Printf("  - Drawable: %v, Optional: %v\n", info.Drawable, info.Optional)
+		managerLog.Debug("Registering system", "system", info.Type,
+			"dependencies", info.Dependencies, "provides", info.Provides, "requires", info.Requires,
+			"drawable", info.Drawable, "optional", info.Optional, "phase", info.Phase)
 	}
 
 	sm.systems[info.Type] = info
@@ -93,20 +160,196 @@ func (sm *SystemManager) RegisterSystem(info *SystemInfo) error {
 // BuildExecutionOrder builds the correct execution order using robust dependency resolution
 func (sm *SystemManager) BuildExecutionOrder() error {
 	if sm.verbose {
-		fmt.Println("\n[SystemManager] Building execution order...")
-		fmt.Printf("Total systems registered: %d\n", len(sm.systems))
+		managerLog.Debug("Building execution order", "systems", len(sm.systems))
 	}
 
 	// Use the dependency resolver to build the execution order
-	return sm.resolver.ResolveDependencies(sm.systems, &sm.updateOrder, &sm.drawOrder)
+	if err := sm.resolver.ResolveDependencies(sm.systems, &sm.updateOrder, &sm.drawOrder); err != nil {
+		var missing *MissingCapabilityError
+		if errors.As(err, &missing) {
+			missing.Disabled = sm.disabledProviders(missing.Capability)
+		}
+		return err
+	}
+
+	sm.buildPhases()
+	sm.built = true
+	if sm.verbose {
+		for _, ps := range sm.phases {
+			managerLog.Debug("Stages", "phase", ps.phase, "stages", ps.stages)
+		}
+	}
+	return nil
 }
 
-// ResolveDependencies implements robust dependency resolution based on libsolv practices
-func (dr *DependencyResolver) ResolveDependencies(systems map[SystemType]*SystemInfo, updateOrder *[]SystemType, drawOrder *[]SystemType) error {
-	if dr.verbose {
-		fmt.Println("\n[DependencyResolver] Starting dependency resolution...")
+// isRegistered reports whether a system of systemType is registered, enabled
+// or not
+func (sm *SystemManager) isRegistered(systemType SystemType) bool {
+	_, enabled := sm.systems[systemType]
+	_, disabled := sm.disabled[systemType]
+	return enabled || disabled
+}
+
+// rebuild rebuilds the execution order after a runtime change, if it was
+// built before
+func (sm *SystemManager) rebuild() error {
+	if !sm.built {
+		return nil
+	}
+	return sm.BuildExecutionOrder()
+}
+
+// Add registers and enables a system while the game runs. The system is given
+// everything attached to the manager so far.
+func (sm *SystemManager) Add(info *SystemInfo) error {
+	if err := sm.RegisterSystem(info); err != nil {
+		return err
+	}
+	if err := sm.rebuild(); err != nil {
+		delete(sm.systems, info.Type)
+		if rollbackErr := sm.rebuild(); rollbackErr != nil {
+			return fmt.Errorf("failed to restore execution order: %w", rollbackErr)
+		}
+		return fmt.Errorf("failed to add system %s: %w", info.Type, err)
+	}
+	sm.attachAll(info.System)
+	sm.initialize(info.System)
+	return nil
+}
+
+// Remove unregisters an optional system, enabled or not
+func (sm *SystemManager) Remove(systemType SystemType) error {
+	if info, disabled := sm.disabled[systemType]; disabled {
+		if !info.Optional {
+			return fmt.Errorf("system %s is not optional", systemType)
+		}
+		delete(sm.disabled, systemType)
+		return nil
+	}
+	if err := sm.Disable(systemType); err != nil {
+		return err
+	}
+	delete(sm.disabled, systemType)
+	return nil
+}
+
+// Enable turns a disabled system back on
+func (sm *SystemManager) Enable(systemType SystemType) error {
+	info, exists := sm.disabled[systemType]
+	if !exists {
+		if _, enabled := sm.systems[systemType]; enabled {
+			return nil
+		}
+		return fmt.Errorf("system %s is not registered", systemType)
+	}
+
+	delete(sm.disabled, systemType)
+	sm.systems[systemType] = info
+	if err := sm.rebuild(); err != nil {
+		delete(sm.systems, systemType)
+		sm.disabled[systemType] = info
+		if rollbackErr := sm.rebuild(); rollbackErr != nil {
+			return fmt.Errorf("failed to restore execution order: %w", rollbackErr)
+		}
+		return fmt.Errorf("failed to enable system %s: %w", systemType, err)
+	}
+	sm.initialize(info.System)
+	if sm.verbose {
+		managerLog.Info("Enabled system", "system", systemType)
+	}
+	return nil
+}
+
+// Disable turns an optional system off, keeping it registered so it can be
+// enabled again. It fails if another enabled system depends on it or requires
+// a capability only it provides.
+func (sm *SystemManager) Disable(systemType SystemType) error {
+	info, exists := sm.systems[systemType]
+	if !exists {
+		if _, disabled := sm.disabled[systemType]; disabled {
+			return nil
+		}
+		return fmt.Errorf("system %s is not registered", systemType)
+	}
+	if !info.Optional {
+		return fmt.Errorf("system %s is not optional", systemType)
+	}
+	if dependent, reason := sm.dependentOn(info); dependent != "" {
+		return fmt.Errorf("cannot disable system %s: %s %s", systemType, dependent, reason)
+	}
+
+	delete(sm.systems, systemType)
+	sm.disabled[systemType] = info
+	if err := sm.rebuild(); err != nil {
+		delete(sm.disabled, systemType)
+		sm.systems[systemType] = info
+		if rollbackErr := sm.rebuild(); rollbackErr != nil {
+			return fmt.Errorf("failed to restore execution order: %w", rollbackErr)
+		}
+		return fmt.Errorf("failed to disable system %s: %w", systemType, err)
+	}
+	sm.unsubscribe(info.System)
+	if sm.verbose {
+		managerLog.Info("Disabled system", "system", systemType)
+	}
+	return nil
+}
+
+// dependentOn finds an enabled system that cannot run without the system of
+// info, returning it and the reason, or "" if there is none
+func (sm *SystemManager) dependentOn(info *SystemInfo) (SystemType, string) {
+	for _, systemType := range sortedTypes(sm.systems) {
+		other := sm.systems[systemType]
+		if systemType == info.Type {
+			continue
+		}
+		if slices.Contains(other.Dependencies, info.Type) {
+			return systemType, "depends on it"
+		}
+		for _, capability := range other.Requires {
+			if slices.Contains(info.Provides, capability) && !sm.providedByOther(capability, info.Type) {
+				return systemType, fmt.Sprintf("requires its capability '%s'", capability)
+			}
+		}
 	}
+	return "", ""
+}
 
+// providedByOther reports whether an enabled system other than except
+// provides capability
+func (sm *SystemManager) providedByOther(capability string, except SystemType) bool {
+	for systemType, info := range sm.systems {
+		if systemType != except && slices.Contains(info.Provides, capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// disabledProviders returns the disabled systems that provide capability
+func (sm *SystemManager) disabledProviders(capability string) []SystemType {
+	providers := make([]SystemType, 0)
+	for _, systemType := range sortedTypes(sm.disabled) {
+		if slices.Contains(sm.disabled[systemType].Provides, capability) {
+			providers = append(providers, systemType)
+		}
+	}
+	return providers
+}
+
+// IsEnabled reports whether a system of systemType is registered and enabled
+func (sm *SystemManager) IsEnabled(systemType SystemType) bool {
+	_, enabled := sm.systems[systemType]
+	return enabled
+}
+
+// DisabledSystems returns the types of the disabled systems in order
+func (sm *SystemManager) DisabledSystems() []SystemType {
+	return sortedTypes(sm.disabled)
+}
+
+// ResolveDependencies implements robust dependency resolution based on libsolv practices
+func (dr *DependencyResolver) ResolveDependencies(systems map[SystemType]*SystemInfo, updateOrder *[]SystemType, drawOrder *[]SystemType) error {
 	// Clear existing orders
 	*updateOrder = make([]SystemType, 0)
 	*drawOrder = make([]SystemType, 0)
@@ -132,6 +375,10 @@ func (dr *DependencyResolver) ResolveDependencies(systems map[SystemType]*System
 		return err
 	}
 
+	// Phases run one after another, each in its resolved order
+	slices.SortStableFunc(order, func(a, b SystemType) int {
+		return systems[a].Phase.rank() - systems[b].Phase.rank()
+	})
 	*updateOrder = order
 
 	// Build draw order
@@ -142,9 +389,7 @@ func (dr *DependencyResolver) ResolveDependencies(systems map[SystemType]*System
 	}
 
 	if dr.verbose {
-		fmt.Printf("[DependencyResolver] Resolution completed successfully\n")
-		fmt.Printf("Update order: %v\n", *updateOrder)
-		fmt.Printf("Draw order: %v\n", *drawOrder)
+		managerLog.Debug("Resolved dependencies", "update", *updateOrder, "draw", *drawOrder)
 	}
 
 	return nil
@@ -152,59 +397,52 @@ func (dr *DependencyResolver) ResolveDependencies(systems map[SystemType]*System
 
 // buildGraphs builds the dependency and capability graphs
 func (dr *DependencyResolver) buildGraphs(systems map[SystemType]*SystemInfo) error {
-	if dr.verbose {
-		fmt.Println("\n[DependencyResolver] Building dependency and capability graphs...")
-	}
-
 	dr.systems = systems
+	dr.capabilityMap = make(map[string][]SystemType)
+	dr.dependencyGraph = make(map[SystemType][]SystemType)
+	dr.reverseGraph = make(map[SystemType][]SystemType)
 
 	// Build capability map
-	if dr.verbose {
-		fmt.Println("Building capability map...")
-	}
-	for systemType, info := range systems {
-		for _, capability := range info.Provides {
+	for _, systemType := range sortedTypes(systems) {
+		for _, capability := range systems[systemType].Provides {
 			dr.capabilityMap[capability] = append(dr.capabilityMap[capability], systemType)
-			if dr.verbose {
-				fmt.Printf("  %s provides capability: %s\n", systemType, capability)
-			}
 		}
 	}
-
-	// Print capability map
 	if dr.verbose {
-		fmt.Println("\nCapability Map:")
-		for capability, providers := range dr.capabilityMap {
-			fmt.Printf("  %s -> %v\n", capability, providers)
-		}
+		managerLog.Debug("Built capability map", "capabilities", dr.capabilityMap)
 	}
 
 	// Build dependency graph
-	if dr.verbose {
-		fmt.Println("\nBuilding dependency graph...")
-	}
-	for systemType, info := range systems {
+	for _, systemType := range sortedTypes(systems) {
+		info := systems[systemType]
 		deps := make([]SystemType, 0)
 
 		// Add direct dependencies
-		deps = append(deps, info.Dependencies...)
-		if dr.verbose && len(info.Dependencies) > 0 {
-			fmt.Printf("  %s direct dependencies: %v\n", systemType, info.Dependencies)
+		for _, dep := range info.Dependencies {
+			if _, exists := systems[dep]; !exists {
+				return fmt.Errorf("system %s depends on system %s which is not enabled", systemType, dep)
+			}
+			samePhase, err := dr.samePhase(systemType, dep)
+			if err != nil {
+				return err
+			}
+			if samePhase && !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
 		}
 
 		// Add capability-based dependencies
 		for _, requiredCapability := range info.Requires {
-			if providers, exists := dr.capabilityMap[requiredCapability]; exists {
-				// For now, take the first provider. In a more sophisticated system,
-				// we could implement provider selection based on preferences
-				if len(providers) > 0 {
-					deps = append(deps, providers[0])
-					if dr.verbose {
-						fmt.Printf("  %s requires capability '%s' -> depends on %s\n", systemType, requiredCapability, providers[0])
-					}
-				}
-			} else {
-				return fmt.Errorf("system %s requires capability '%s' but no system provides it", systemType, requiredCapability)
+			provider, err := dr.provider(systemType, requiredCapability)
+			if err != nil {
+				return err
+			}
+			samePhase, err := dr.samePhase(systemType, provider)
+			if err != nil {
+				return err
+			}
+			if samePhase && !slices.Contains(deps, provider) {
+				deps = append(deps, provider)
 			}
 		}
 
@@ -216,17 +454,8 @@ func (dr *DependencyResolver) buildGraphs(systems map[SystemType]*SystemInfo) er
 		}
 	}
 
-	// Print dependency graph
 	if dr.verbose {
-		fmt.Println("\nDependency Graph:")
-		for systemType, deps := range dr.dependencyGraph {
-			fmt.Printf("  %s -> %v\n", systemType, deps)
-		}
-
-		fmt.Println("\nReverse Dependency Graph:")
-		for systemType, dependents := range dr.reverseGraph {
-			fmt.Printf("  %s <- %v\n", systemType, dependents)
-		}
+		managerLog.Debug("Built dependency graph", "dependencies", dr.dependencyGraph, "dependents", dr.reverseGraph)
 	}
 
 	return nil
@@ -234,68 +463,63 @@ func (dr *DependencyResolver) buildGraphs(systems map[SystemType]*SystemInfo) er
 
 // checkConflicts verifies that no conflicting systems are present
 func (dr *DependencyResolver) checkConflicts(systems map[SystemType]*SystemInfo) error {
-	if dr.verbose {
-		fmt.Println("\n[DependencyResolver] Checking for system conflicts...")
-	}
-
 	for systemType, info := range systems {
 		for _, conflict := range info.Conflicts {
 			if _, exists := systems[conflict]; exists {
-				if dr.verbose {
-					fmt.Printf("  CONFLICT: %s conflicts with %s\n", systemType, conflict)
-				}
 				return fmt.Errorf("conflict detected: system %s conflicts with system %s", systemType, conflict)
 			}
 		}
 	}
-
-	if dr.verbose {
-		fmt.Println("  No conflicts detected")
-	}
 	return nil
 }
 
 // resolveCapabilities resolves capability-based dependencies
 func (dr *DependencyResolver) resolveCapabilities(systems map[SystemType]*SystemInfo) error {
-	if dr.verbose {
-		fmt.Println("\n[DependencyResolver] Resolving capability dependencies...")
-	}
-
-	// This is a simplified implementation. A full resolver would:
-	// 1. Handle multiple providers for the same capability
-	// 2. Implement provider selection based on preferences
-	// 3. Handle version constraints
-	// 4. Implement backtracking for complex dependency resolution
-
-	for systemType, info := range systems {
-		for _, requiredCapability := range info.Requires {
-			providers := dr.capabilityMap[requiredCapability]
-			if len(providers) == 0 {
-				if dr.verbose {
-					fmt.Printf("  ERROR: %s requires capability '%s' but no system provides it\n", systemType, requiredCapability)
-				}
-				return fmt.Errorf("system %s requires capability '%s' but no system provides it", systemType, requiredCapability)
+	for _, systemType := range sortedTypes(systems) {
+		for _, requiredCapability := range systems[systemType].Requires {
+			provider, err := dr.provider(systemType, requiredCapability)
+			if err != nil {
+				return err
 			}
 			if dr.verbose {
-				fmt.Printf("  %s requires '%s' -> provided by %v\n", systemType, requiredCapability, providers)
+				managerLog.Debug("Resolved capability", "system", systemType, "capability", requiredCapability, "provider", provider)
 			}
-			// For now, we just verify that at least one provider exists
-			// In a more sophisticated system, we'd select the best provider
 		}
 	}
+	return nil
+}
 
-	if dr.verbose {
-		fmt.Println("  All capability dependencies resolved")
+// samePhase reports whether systemType and the system it depends on run in
+// the same phase, so that the dependency orders them. Depending on a system of
+// an earlier phase needs no ordering; depending on a later one is an error.
+func (dr *DependencyResolver) samePhase(systemType, dep SystemType) (bool, error) {
+	phase, depPhase := dr.systems[systemType].Phase, dr.systems[dep].Phase
+	switch {
+	case phase == depPhase:
+		return true, nil
+	case depPhase.rank() < phase.rank():
+		return false, nil
+	default:
+		return false, fmt.Errorf("system %s in phase %v depends on system %s in the later phase %v", systemType, phase, dep, depPhase)
 	}
-	return nil
 }
 
-// topologicalSort performs topological sorting of the dependency graph
-func (dr *DependencyResolver) topologicalSort() ([]SystemType, error) {
-	if dr.verbose {
-		fmt.Println("\n[DependencyResolver] Performing topological sort...")
+// provider returns the one system that provides a capability systemType
+// requires
+func (dr *DependencyResolver) provider(systemType SystemType, capability string) (SystemType, error) {
+	providers := dr.capabilityMap[capability]
+	switch len(providers) {
+	case 0:
+		return "", &MissingCapabilityError{System: systemType, Capability: capability}
+	case 1:
+		return providers[0], nil
+	default:
+		return "", &AmbiguousCapabilityError{System: systemType, Capability: capability, Providers: slices.Clone(providers)}
 	}
+}
 
+// topologicalSort performs topological sorting of the dependency graph
+func (dr *DependencyResolver) topologicalSort() ([]SystemType, error) {
 	// Kahn's algorithm for topological sorting
 	inDegree := make(map[SystemType]int)
 
@@ -308,24 +532,15 @@ func (dr *DependencyResolver) topologicalSort() ([]SystemType, error) {
 		inDegree[systemType] = len(deps)
 	}
 
-	if dr.verbose {
-		fmt.Println("Initial in-degrees:")
-		for systemType, degree := range inDegree {
-			fmt.Printf("  %s: %d\n", systemType, degree)
-		}
-	}
-
-	// Find systems with no incoming edges
+	// Find systems with no incoming edges. The queue is kept sorted so that
+	// the order does not depend on map iteration.
 	queue := make([]SystemType, 0)
 	for systemType, degree := range inDegree {
 		if degree == 0 {
 			queue = append(queue, systemType)
 		}
 	}
-
-	if dr.verbose {
-		fmt.Printf("Initial queue (systems with no dependencies): %v\n", queue)
-	}
+	slices.Sort(queue)
 
 	result := make([]SystemType, 0)
 
@@ -335,31 +550,18 @@ func (dr *DependencyResolver) topologicalSort() ([]SystemType, error) {
 		queue = queue[1:]
 		result = append(result, current)
 
-		if dr.verbose {
-			fmt.Printf("Processing: %s (queue size: %d)\n", current, len(queue))
-		}
-
 		// Reduce in-degree for all neighbors
 		for _, neighbor := range dr.reverseGraph[current] {
 			inDegree[neighbor]--
-			if dr.verbose {
-				fmt.Printf("  Reducing in-degree for %s: %d -> %d\n", neighbor, inDegree[neighbor]+1, inDegree[neighbor])
-			}
 			if inDegree[neighbor] == 0 {
 				queue = append(queue, neighbor)
-				if dr.verbose {
-					fmt.Printf("  Adding %s to queue\n", neighbor)
-				}
 			}
 		}
+		slices.Sort(queue)
 	}
 
 	// Check for cycles
 	if len(result) != len(dr.systems) {
-		if dr.verbose {
-			fmt.Printf("CYCLE DETECTED! Processed %d systems, but %d systems exist\n", len(result), len(dr.systems))
-		}
-
 		// Find systems that weren't processed (part of a cycle)
 		processed := make(map[SystemType]bool)
 		for _, systemType := range result {
@@ -374,37 +576,41 @@ func (dr *DependencyResolver) topologicalSort() ([]SystemType, error) {
 		}
 
 		if dr.verbose {
-			fmt.Printf("Systems in cycle: %v\n", cycleSystems)
-			fmt.Println("Dependency details for systems in cycle:")
 			for _, systemName := range cycleSystems {
 				systemType := SystemType(systemName)
-				if info, exists := dr.systems[systemType]; exists {
-					fmt.Printf("  %s:\n", systemType)
-					fmt.Printf("    Dependencies: %v\n", info.Dependencies)
-					fmt.Printf("    Requires: %v\n", info.Requires)
-					fmt.Printf("    Provides: %v\n", info.Provides)
-					if deps, exists := dr.dependencyGraph[systemType]; exists {
-						fmt.Printf("    Resolved dependencies: %v\n", deps)
-					}
-				}
+				managerLog.Debug("System in cycle", "system", systemType, "dependencies", dr.dependencyGraph[systemType])
 			}
 		}
 
 		return nil, fmt.Errorf("circular dependency detected among systems: %v", cycleSystems)
 	}
 
-	if dr.verbose {
-		fmt.Printf("Topological sort completed successfully: %v\n", result)
-	}
-
 	return result, nil
 }
 
-// UpdateAll updates all systems in the correct order
+// UpdateAll updates all systems phase by phase and stage by stage. The
+// systems of a stage run concurrently unless the manager is in deterministic
+// mode. Events queued by a stage are flushed once it finishes, and entities
+// deactivated during a phase are removed before the next one starts.
 func (sm *SystemManager) UpdateAll(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
-	for _, systemType := range sm.updateOrder {
-		if info, exists := sm.systems[systemType]; exists {
-			info.System.Update(deltaTime, entities, eventDispatcher)
+	for _, ps := range sm.phases {
+		for _, stage := range ps.stages {
+			sm.runStage(stage, deltaTime, entities, eventDispatcher)
+			// Sync point: deliver the events the stage queued while the entities
+			// they refer to still exist, then apply the structural changes the
+			// stage made before the next stage runs
+			if eventDispatcher != nil {
+				eventDispatcher.Flush()
+			}
+			if sm.commands != nil && sm.commands.Len() > 0 {
+				entities = sm.commands.Flush()
+			}
+		}
+
+		// Phase boundary: drop what the phase deactivated
+		if sm.store != nil {
+			sm.store.RemoveInactiveEntities()
+			entities = sm.store.EntityList()
 		}
 	}
 }
@@ -417,13 +623,272 @@ func (sm *SystemManager) DrawAll(screen interface{}, entities []Entity) {
 			if drawable, ok := info.System.(DrawableSystem); ok {
 				// Type assert screen to *ebiten.Image for type safety
 				if ebitenScreen, ok := screen.(*ebiten.Image); ok {
-					drawable.Draw(ebitenScreen, entities)
+					if sm.profiler == nil {
+						drawable.Draw(ebitenScreen, entities)
+						continue
+					}
+					sm.profiler.Measure(systemType, ProfileDraw, drawable, func() {
+						drawable.Draw(ebitenScreen, entities)
+					})
 				}
 			}
 		}
 	}
 }
 
+// SetProfiler times every system update and draw with profiler from now on;
+// nil stops profiling
+func (sm *SystemManager) SetProfiler(profiler *Profiler) {
+	sm.profiler = profiler
+}
+
+// Profiler returns the attached profiler, or nil
+func (sm *SystemManager) Profiler() *Profiler {
+	return sm.profiler
+}
+
+// AttachViews gives every registered system that supports it a live view of
+// the entities matching its required components
+func (sm *SystemManager) AttachViews(provider ViewProvider) {
+	sm.views = provider
+	sm.forEachSystem(sm.attachViews)
+}
+
+// AttachTables gives every registered system that runs typed queries the
+// component tables to read
+func (sm *SystemManager) AttachTables(tables ComponentTables) {
+	sm.tables = tables
+	sm.forEachSystem(sm.attachTables)
+}
+
+// AttachCommands gives every registered system that supports it the command
+// buffer, and makes UpdateAll flush it after each stage that recorded commands.
+// Stages only run in parallel once a command buffer is attached.
+func (sm *SystemManager) AttachCommands(commands CommandBuffer) {
+	sm.commands = commands
+	sm.forEachSystem(sm.attachCommands)
+}
+
+// AttachResolver gives every registered system that follows entity handles
+// the resolver to look them up with
+func (sm *SystemManager) AttachResolver(resolver EntityResolver) {
+	sm.entityResolver = resolver
+	sm.forEachSystem(sm.attachResolver)
+}
+
+// AttachComponentPool gives every registered system that creates components
+// for high-churn entities the pool to draw them from
+func (sm *SystemManager) AttachComponentPool(pool ComponentPool) {
+	sm.pool = pool
+	sm.forEachSystem(sm.attachComponentPool)
+}
+
+// AttachPrefabs gives every registered system that builds entities from
+// prefabs the library to build them from
+func (sm *SystemManager) AttachPrefabs(library *prefabs.Library) {
+	sm.prefabs = library
+	sm.forEachSystem(sm.attachPrefabs)
+}
+
+// AttachRNG gives every registered system that draws random numbers its
+// streams from rng
+func (sm *SystemManager) AttachRNG(rng *random.RNG) {
+	sm.rng = rng
+	sm.forEachSystem(sm.attachRNG)
+}
+
+// AttachRenderer gives every registered system that can submit what it
+// draws the shared renderer
+func (sm *SystemManager) AttachRenderer(renderer *Renderer) {
+	sm.renderer = renderer
+	sm.forEachSystem(sm.attachRenderer)
+}
+
+// AttachEvents initializes every enabled system that subscribes to events
+// with eventDispatcher. Systems enabled later are initialized as they are
+// enabled, and disabled systems stop receiving events.
+func (sm *SystemManager) AttachEvents(eventDispatcher *events.EventDispatcher) {
+	sm.eventDispatcher = eventDispatcher
+	for _, systemType := range sm.enabledTypes() {
+		sm.initialize(sm.systems[systemType].System)
+	}
+}
+
+// forEachSystem calls attach for every enabled and disabled system, in
+// update order and then by type
+func (sm *SystemManager) forEachSystem(attach func(system System)) {
+	for _, systemType := range sm.enabledTypes() {
+		attach(sm.systems[systemType].System)
+	}
+	for _, systemType := range sm.DisabledSystems() {
+		attach(sm.disabled[systemType].System)
+	}
+}
+
+// attachAll gives a system added after the Attach methods ran everything
+// they attached
+func (sm *SystemManager) attachAll(system System) {
+	if sm.views != nil {
+		sm.attachViews(system)
+	}
+	if sm.tables != nil {
+		sm.attachTables(system)
+	}
+	if sm.commands != nil {
+		sm.attachCommands(system)
+	}
+	if sm.entityResolver != nil {
+		sm.attachResolver(system)
+	}
+	if sm.pool != nil {
+		sm.attachComponentPool(system)
+	}
+	if sm.prefabs != nil {
+		sm.attachPrefabs(system)
+	}
+	if sm.rng != nil {
+		sm.attachRNG(system)
+	}
+	if sm.renderer != nil {
+		sm.attachRenderer(system)
+	}
+}
+
+func (sm *SystemManager) attachViews(system System) {
+	if user, ok := system.(ViewUser); ok {
+		user.SetView(sm.views.NewView(user.GetRequiredComponents()))
+	}
+}
+
+func (sm *SystemManager) attachTables(system System) {
+	if user, ok := system.(TablesUser); ok {
+		user.SetTables(sm.tables)
+	}
+}
+
+func (sm *SystemManager) attachCommands(system System) {
+	if user, ok := system.(CommandUser); ok {
+		user.SetCommands(sm.commands)
+	}
+}
+
+func (sm *SystemManager) attachResolver(system System) {
+	if user, ok := system.(ResolverUser); ok {
+		user.SetResolver(sm.entityResolver)
+	}
+}
+
+func (sm *SystemManager) attachComponentPool(system System) {
+	if user, ok := system.(PoolUser); ok {
+		user.SetComponentPool(sm.pool)
+	}
+}
+
+func (sm *SystemManager) attachPrefabs(system System) {
+	if user, ok := system.(PrefabUser); ok {
+		user.SetPrefabs(sm.prefabs)
+	}
+}
+
+func (sm *SystemManager) attachRNG(system System) {
+	if user, ok := system.(RandomUser); ok {
+		user.SetRNG(sm.rng)
+	}
+}
+
+func (sm *SystemManager) attachRenderer(system System) {
+	if user, ok := system.(RendererUser); ok {
+		user.SetRenderer(sm.renderer)
+	}
+}
+
+// initialize subscribes a system to the attached dispatcher
+func (sm *SystemManager) initialize(system System) {
+	if sm.eventDispatcher == nil {
+		return
+	}
+	if initializer, ok := system.(Initializer); ok {
+		initializer.Initialize(sm.eventDispatcher)
+	}
+}
+
+// unsubscribe removes the event handlers of a system
+func (sm *SystemManager) unsubscribe(system System) {
+	if unsubscriber, ok := system.(Unsubscriber); ok {
+		unsubscriber.Unsubscribe()
+	}
+}
+
+// enabledTypes returns the enabled system types in update order, or sorted
+// by type before the execution order is built
+func (sm *SystemManager) enabledTypes() []SystemType {
+	if sm.built {
+		return sm.updateOrder
+	}
+	return sortedTypes(sm.systems)
+}
+
+// sortedTypes returns the keys of systems in order
+func sortedTypes(systems map[SystemType]*SystemInfo) []SystemType {
+	types := make([]SystemType, 0, len(systems))
+	for systemType := range systems {
+		types = append(types, systemType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// UnsubscribeAll removes the event handlers of every registered system, for
+// when the manager is discarded while its dispatcher lives on
+func (sm *SystemManager) UnsubscribeAll() {
+	for _, info := range sm.systems {
+		sm.unsubscribe(info.System)
+	}
+}
+
+// SaveStates collects the internal state of every stateful system, keyed by system type
+func (sm *SystemManager) SaveStates() (map[SystemType][]byte, error) {
+	states := make(map[SystemType][]byte)
+	for _, systemType := range sm.updateOrder {
+		if stateful, ok := sm.systems[systemType].System.(StatefulSystem); ok {
+			data, err := stateful.SaveState()
+			if err != nil {
+				return nil, fmt.Errorf("failed to save state of system %s: %w", systemType, err)
+			}
+			states[systemType] = data
+		}
+	}
+	return states, nil
+}
+
+// LoadStates restores system state saved by SaveStates. Systems without a
+// saved entry keep their current state.
+func (sm *SystemManager) LoadStates(states map[SystemType][]byte) error {
+	for _, systemType := range sm.updateOrder {
+		data, exists := states[systemType]
+		if !exists {
+			continue
+		}
+		if stateful, ok := sm.systems[systemType].System.(StatefulSystem); ok {
+			if err := stateful.LoadState(data); err != nil {
+				return fmt.Errorf("failed to load state of system %s: %w", systemType, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CopyStatesFrom gives every stateful system the state of the system of the
+// same type in other, e.g. to continue a cloned world from where the
+// original stands
+func (sm *SystemManager) CopyStatesFrom(other *SystemManager) error {
+	states, err := other.SaveStates()
+	if err != nil {
+		return err
+	}
+	return sm.LoadStates(states)
+}
+
 // GetSystem returns a system by type
 func (sm *SystemManager) GetSystem(systemType SystemType) (System, bool) {
 	if info, exists := sm.systems[systemType]; exists {
@@ -450,32 +915,36 @@ func (sm *SystemManager) GetDrawOrder() []SystemType {
 	return sm.drawOrder
 }
 
-// PrintExecutionOrder prints the current execution order for debugging
-func (sm *SystemManager) PrintExecutionOrder() {
-	fmt.Println("=== System Execution Order ===")
-	fmt.Println("Update Order:")
+// LogExecutionOrder logs the current execution order at debug level. The
+// "systems graph" command prints it in full.
+func (sm *SystemManager) LogExecutionOrder() {
+	update := make([]string, len(sm.updateOrder))
 	for i, systemType := range sm.updateOrder {
-		fmt.Printf("  %d. %s\n", i+1, systemType)
+		update[i] = fmt.Sprintf("%s (%v)", systemType, sm.systems[systemType].Phase)
 	}
-	fmt.Println("Draw Order:")
-	for i, systemType := range sm.drawOrder {
-		fmt.Printf("  %d. %s\n", i+1, systemType)
+	managerLog.Debug("Execution order", "update", update, "draw", sm.drawOrder)
+}
+
+// PrintExecutionOrder logs the current execution order at debug level.
+//
+// Deprecated: use LogExecutionOrder instead.
+func (sm *SystemManager) PrintExecutionOrder() {
+	sm.LogExecutionOrder()
+}
+
+// LogDependencyGraph logs the dependencies of every system at debug level
+func (sm *SystemManager) LogDependencyGraph() {
+	for _, systemType := range sortedTypes(sm.systems) {
+		info := sm.systems[systemType]
+		managerLog.Debug("Dependencies", "system", systemType,
+			"dependencies", info.Dependencies, "requires", info.Requires, "provides", info.Provides,
+			"resolved", sm.resolver.dependencyGraph[systemType])
 	}
-	fmt.Println("==============================")
 }
 
-// PrintDependencyGraph prints the current dependency graph for debugging
+// PrintDependencyGraph logs the dependencies of every system at debug level.
+//
+// Deprecated: use LogDependencyGraph instead.
 func (sm *SystemManager) PrintDependencyGraph() {
-	fmt.Println("=== Dependency Graph ===")
-	for systemType, info := range sm.systems {
-		fmt.Printf("%s:\n", systemType)
-		fmt.Printf("  Dependencies: %v\n", info.Dependencies)
-		fmt.Printf("  Requires: %v\n", info.Requires)
-		fmt.Printf("  Provides: %v\n", info.Provides)
-		if deps, exists := sm.resolver.dependencyGraph[systemType]; exists {
-			fmt.Printf("  Resolved deps: %v\n", deps)
-		}
-		fmt.Println()
-	}
-	fmt.Println("==========================")
+	sm.LogDependencyGraph()
 }
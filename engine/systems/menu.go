@@ -62,12 +62,13 @@ func (ms *MenuSystem) Update(deltaTime float64, entities []Entity, eventDispatch
 }
 
 func (ms *MenuSystem) startGame(eventDispatcher *events.EventDispatcher) {
-	// Publish game start event with selected mode
-	eventDispatcher.Publish(events.NewEvent(events.EventGameStart, &events.EventData{
-		Mode:   &ms.selectedMode,
-		SLA:    &ms.menuSLA[ms.selectedMode],
-		Errors: &ms.menuErrors[ms.selectedMode],
-	}))
+	// Publish game start event with selected mode, right away so the game
+	// switches scenes before the menu goes on
+	events.PublishImmediate(eventDispatcher, events.GameStart{
+		Mode:   ms.selectedMode,
+		SLA:    ms.menuSLA[ms.selectedMode],
+		Errors: ms.menuErrors[ms.selectedMode],
+	})
 }
 
 func (ms *MenuSystem) Draw(screen *ebiten.Image) {
@@ -1,18 +1,29 @@
 package systems
 
 import (
-	"fmt"
+	"context"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"log/slog"
+	"time"
 )
 
 const SystemTypeMovement SystemType = "movement"
 
+var movementFrameLog = logging.Every(logging.For(string(SystemTypeMovement)), time.Second)
+
 type MovementSystem struct {
 	BaseSystem
 	callCount int
 }
 
+func init() {
+	Register(SystemTypeMovement, func(ctx *SystemContext) SystemInfoer {
+		return NewMovementSystem()
+	})
+}
+
 func NewMovementSystem() *MovementSystem {
 	return &MovementSystem{
 		BaseSystem: BaseSystem{
@@ -30,56 +41,53 @@ func (ms *MovementSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeMovement,
 		System:       ms,
-		Dependencies: []SystemType{SystemTypeSpawn},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"entity_movement", "physics_update"},
-		Requires:     []string{},
+		Requires:     []string{"entity_spawning"},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhaseUpdate,
+		Reads:        []string{"Transform", "Physics"},
+		Writes:       []string{"Transform", "Physics"},
 	}
 }
 
 func (ms *MovementSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
 	ms.callCount++
 
+	// Log every second, building the arguments only when they are logged
+	logFrame := ms.callCount%60 == 0 && movementFrameLog.Enabled(context.Background(), slog.LevelDebug)
 	packetCount := 0
-	for _, entity := range ms.FilterEntities(entities) {
-		transformComp := entity.GetTransform()
-		physicsComp := entity.GetPhysics()
-
-		if transformComp == nil || physicsComp == nil {
-			continue
-		}
-
-		transform := transformComp
-		physics := physicsComp
+	for entity, row := range Query2[*components.Transform, *components.Physics](ms.tables, entities) {
+		ms.processed++
+		transform, physics := row.First, row.Second
 
 		// Update physics
-		physicsObj := physicsComp.(*components.Physics)
-		physicsObj.Update(deltaTime)
+		physics.Update(deltaTime)
 
 		// Update position
 		oldX, oldY := transform.GetX(), transform.GetY()
 		transform.SetPosition(transform.GetX()+physics.GetVelocityX()*deltaTime,
 			transform.GetY()+physics.GetVelocityY()*deltaTime)
 
-		if ms.callCount%60 == 0 {
+		if logFrame {
 			if entityInterface, ok := entity.(interface{ GetComponentNames() []string }); ok {
-				fmt.Printf("[MovementSystem] Entity moved from (%.1f, %.1f) to (%.1f, %.1f), components: %v\n", oldX, oldY, transform.GetX(), transform.GetY(), entityInterface.GetComponentNames())
+				movementFrameLog.Debug("Entity moved", "fromX", oldX, "fromY", oldY, "toX", transform.GetX(), "toY", transform.GetY(), "components", entityInterface.GetComponentNames())
 			}
 		}
 
 		// Check if this is a packet (has PacketType component)
 		if entity.HasComponent("PacketType") {
 			packetCount++
-			if ms.callCount%60 == 0 { // Print every second
-				fmt.Printf("[MovementSystem] Packet at (%.1f, %.1f) -> (%.1f, %.1f), vel(%.1f, %.1f)\n",
-					oldX, oldY, transform.GetX(), transform.GetY(), physics.GetVelocityX(), physics.GetVelocityY())
+			if logFrame {
+				movementFrameLog.Debug("Packet moved", "fromX", oldX, "fromY", oldY, "toX", transform.GetX(), "toY", transform.GetY(),
+					"velocityX", physics.GetVelocityX(), "velocityY", physics.GetVelocityY())
 			}
 		}
 	}
 
-	if ms.callCount%60 == 0 && packetCount > 0 {
-		fmt.Printf("[MovementSystem] Processing %d packets\n", packetCount)
+	if logFrame && packetCount > 0 {
+		movementFrameLog.Debug("Processing packets", "count", packetCount)
 	}
 }
@@ -1,16 +1,24 @@
 package systems
 
 import (
-	"fmt"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 )
 
 const SystemTypePacketRouting SystemType = "packet_routing"
 
+var packetRoutingLog = logging.For(string(SystemTypePacketRouting))
+
 type PacketRoutingSystem struct {
 	BaseSystem
 }
 
+func init() {
+	Register(SystemTypePacketRouting, func(ctx *SystemContext) SystemInfoer {
+		return NewPacketRoutingSystem()
+	})
+}
+
 func NewPacketRoutingSystem() *PacketRoutingSystem {
 	return &PacketRoutingSystem{
 		BaseSystem: BaseSystem{
@@ -27,36 +35,34 @@ func (prs *PacketRoutingSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypePacketRouting,
 		System:       prs,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"packet_routing", "backend_delivery"},
-		Requires:     []string{},
+		Requires:     []string{"collision_detection"},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhaseUpdate,
+		Reads:        []string{"Transform", "BackendAssignment", "Routing", "Physics"},
+		Writes:       []string{"Physics", "Routing", EventResource(events.EventPacketDelivered)},
 	}
 }
 
 func (prs *PacketRoutingSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
 	// Find all routed packets
 	var routedPackets []Entity
-	var backends []Entity
-
-	// Separate entities
 	for _, entity := range entities {
 		if entity.HasComponent("Routing") {
 			routedPackets = append(routedPackets, entity)
-		} else if entity.HasComponent("BackendAssignment") {
-			backends = append(backends, entity)
 		}
 	}
 
 	// Process each routed packet
 	for _, packet := range routedPackets {
-		prs.processRoutedPacket(packet, backends, deltaTime, eventDispatcher)
+		prs.processRoutedPacket(packet, deltaTime, eventDispatcher)
 	}
 }
 
-func (prs *PacketRoutingSystem) processRoutedPacket(packet Entity, backends []Entity, deltaTime float64, eventDispatcher *events.EventDispatcher) {
+func (prs *PacketRoutingSystem) processRoutedPacket(packet Entity, deltaTime float64, eventDispatcher *events.EventDispatcher) {
 	routingComp := packet.GetRouting()
 	transformComp := packet.GetTransform()
 	physicsComp := packet.GetPhysics()
@@ -65,23 +71,15 @@ func (prs *PacketRoutingSystem) processRoutedPacket(packet Entity, backends []En
 		return
 	}
 
-	targetBackendID := routingComp.GetTargetBackendID()
-
-	// Find target backend
-	var targetBackend Entity
-	for _, backend := range backends {
-		backendAssignment := backend.GetBackendAssignment()
-		if backendAssignment != nil && backendAssignment.GetBackendID() == targetBackendID {
-			targetBackend = backend
-			break
-		}
-	}
-
-	if targetBackend == nil {
+	// Follow the handle to the target backend; a stale handle means the
+	// backend has been removed
+	targetBackend, ok := prs.resolve(routingComp.GetTargetBackend())
+	if !ok || targetBackend.GetBackendAssignment() == nil {
 		// Target backend not found, destroy packet
-		packet.(interface{ SetActive(bool) }).SetActive(false)
+		prs.destroyEntity(packet)
 		return
 	}
+	targetBackendID := targetBackend.GetBackendAssignment().GetBackendID()
 
 	// Get backend position
 	backendTransform := targetBackend.GetTransform()
@@ -102,16 +100,16 @@ func (prs *PacketRoutingSystem) processRoutedPacket(packet Entity, backends []En
 	// Check if packet has reached the backend
 	if distance < 100.0 { // Within 10 pixels of backend center
 		// Packet delivered to backend
-		fmt.Printf("Packet delivered to backend %d!\n", targetBackendID)
+		packetRoutingLog.Debug("Packet delivered", "backend", targetBackendID)
 
 		// Remove routing component and destroy packet
-		packet.RemoveComponent("Routing")
-		packet.(interface{ SetActive(bool) }).SetActive(false)
+		prs.removeComponent(packet, "Routing")
+		prs.destroyEntity(packet)
 
 		// Publish packet delivered event
-		eventDispatcher.Publish(events.NewEvent(events.EventPacketDelivered, &events.EventData{
-			BackendID: &targetBackendID,
-		}))
+		events.Publish(eventDispatcher, events.PacketDelivered{
+			BackendID: targetBackendID,
+		})
 
 		return
 	}
@@ -145,5 +143,5 @@ func (prs *PacketRoutingSystem) processRoutedPacket(packet Entity, backends []En
 
 func (prs *PacketRoutingSystem) Initialize(eventDispatcher *events.EventDispatcher) {
 	// Initialize packet routing system
-	fmt.Println("[PacketRoutingSystem] Initialized")
+	packetRoutingLog.Debug("Initialized")
 }
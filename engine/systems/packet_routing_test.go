@@ -0,0 +1,69 @@
+package systems
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+	"testing"
+)
+
+// newRoutedPacket creates a packet at (x, y) routed to target
+func newRoutedPacket(id uint64, x, y float64, target components.EntityHandle) *entities.Entity {
+	packet := entities.NewEntity(id)
+	packet.AddComponent(components.NewTransform(x, y))
+	packet.AddComponent(components.NewPhysics())
+	packet.AddComponent(components.NewRouting(target, 100))
+	return packet
+}
+
+// TestPacketRoutingSystem_FollowsTargetHandle tests that packets are steered to and delivered at their target backend
+func TestPacketRoutingSystem_FollowsTargetHandle(t *testing.T) {
+	prs := NewPacketRoutingSystem()
+	resolver := &stubResolver{}
+	prs.SetResolver(resolver)
+	eventDispatcher := events.NewEventDispatcher()
+
+	backend := entities.NewEntity(1)
+	backend.AddComponent(components.NewTransform(100, 500))
+	backend.AddComponent(components.NewBackendAssignment(2))
+	target := resolver.register(backend)
+
+	delivered := -1
+	eventDispatcher.Subscribe(events.EventPacketDelivered, func(event *events.Event) {
+		delivered = *event.Data.BackendID
+	})
+
+	moving := newRoutedPacket(2, 160, 100, target)
+	arrived := newRoutedPacket(3, 160, 520, target)
+	prs.Update(0.016, []Entity{backend, moving, arrived}, eventDispatcher)
+
+	if vy := moving.GetPhysics().GetVelocityY(); vy <= 0 {
+		t.Errorf("Expected packet to be steered down toward its backend, got velocity %f", vy)
+	}
+	if !moving.IsActive() {
+		t.Error("Expected a packet still en route to stay active")
+	}
+	if arrived.IsActive() || delivered != 2 {
+		t.Errorf("Expected arrived packet to be delivered to backend 2, got active=%v backend=%d", arrived.IsActive(), delivered)
+	}
+}
+
+// TestPacketRoutingSystem_StaleTarget tests that packets routed to a removed backend are destroyed
+func TestPacketRoutingSystem_StaleTarget(t *testing.T) {
+	prs := NewPacketRoutingSystem()
+	resolver := &stubResolver{}
+	prs.SetResolver(resolver)
+
+	backend := entities.NewEntity(1)
+	backend.AddComponent(components.NewTransform(100, 500))
+	backend.AddComponent(components.NewBackendAssignment(0))
+	target := resolver.register(backend)
+	resolver.remove(target)
+
+	packet := newRoutedPacket(2, 160, 100, target)
+	prs.Update(0.016, []Entity{packet}, events.NewEventDispatcher())
+
+	if packet.IsActive() {
+		t.Error("Expected packet routed to a removed backend to be destroyed")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"image/color"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/random"
 	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,14 +15,34 @@ const SystemTypeParticle SystemType = "particle"
 
 type ParticleSystem struct {
 	BaseSystem
-	particles []*components.Particle
+	particles    []*components.Particle
+	particleRand *rand.Rand
+	renderer     *Renderer
+}
+
+func init() {
+	Register(SystemTypeParticle, func(ctx *SystemContext) SystemInfoer {
+		return NewParticleSystem()
+	})
 }
 
 func NewParticleSystem() *ParticleSystem {
-	return &ParticleSystem{
+	ps := &ParticleSystem{
 		BaseSystem: BaseSystem{},
 		particles:  make([]*components.Particle, 0),
 	}
+	ps.SetRNG(random.New(random.NewSeed()))
+	return ps
+}
+
+// SetRNG implements RandomUser
+func (ps *ParticleSystem) SetRNG(rng *random.RNG) {
+	ps.particleRand = rng.Stream(random.StreamParticles)
+}
+
+// SetRenderer implements RendererUser
+func (ps *ParticleSystem) SetRenderer(renderer *Renderer) {
+	ps.renderer = renderer
 }
 
 // GetSystemInfo returns the system metadata for dependency resolution
@@ -29,12 +50,15 @@ func (ps *ParticleSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeParticle,
 		System:       ps,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"visual_effects", "particle_rendering"},
-		Requires:     []string{},
+		Requires:     []string{"packet_catching"},
 		Drawable:     true,
 		Optional:     true,
+		Phase:        PhaseRender,
+		Reads:        []string{"Transform", "Sprite", EventResource(events.EventPacketCaught), EventResource(events.EventPowerUpCollected)},
+		Writes:       []string{},
 	}
 }
 
@@ -62,6 +86,10 @@ func (ps *ParticleSystem) Draw(screen *ebiten.Image, entities []Entity) {
 				B: particle.Color.B,
 				A: alpha,
 			}
+			if ps.renderer != nil {
+				ps.renderer.Circle(components.LayerEffects, particle.X, particle.Y, particle.Size, particleColor)
+				continue
+			}
 			vector.DrawFilledCircle(screen, float32(particle.X), float32(particle.Y), float32(particle.Size), particleColor, false)
 		}
 	}
@@ -71,11 +99,11 @@ func (ps *ParticleSystem) Draw(screen *ebiten.Image, entities []Entity) {
 func (ps *ParticleSystem) CreatePacketCatchEffect(x, y float64, packetColor color.RGBA) {
 	// Create multiple particles in a burst
 	for range 8 {
-		speed := 50.0 + rand.Float64()*50.0
-		vx := speed * float64(rand.Float64()-0.5)
-		vy := speed * float64(rand.Float64()-0.5)
-		life := 0.5 + rand.Float64()*0.5
-		size := 2.0 + rand.Float64()*3.0
+		speed := 50.0 + ps.particleRand.Float64()*50.0
+		vx := speed * float64(ps.particleRand.Float64()-0.5)
+		vy := speed * float64(ps.particleRand.Float64()-0.5)
+		life := 0.5 + ps.particleRand.Float64()*0.5
+		size := 2.0 + ps.particleRand.Float64()*3.0
 
 		particle := components.NewParticle(x, y, vx, vy, life, packetColor, size)
 		ps.particles = append(ps.particles, particle)
@@ -86,11 +114,11 @@ func (ps *ParticleSystem) CreatePacketCatchEffect(x, y float64, packetColor colo
 func (ps *ParticleSystem) CreatePowerUpEffect(x, y float64, powerUpColor color.RGBA) {
 	// Create sparkle effect
 	for i := 0; i < 12; i++ {
-		speed := 30.0 + rand.Float64()*40.0
-		vx := speed * float64(rand.Float64()-0.5)
-		vy := speed * float64(rand.Float64()-0.5)
-		life := 1.0 + rand.Float64()*1.0
-		size := 1.0 + rand.Float64()*2.0
+		speed := 30.0 + ps.particleRand.Float64()*40.0
+		vx := speed * float64(ps.particleRand.Float64()-0.5)
+		vy := speed * float64(ps.particleRand.Float64()-0.5)
+		life := 1.0 + ps.particleRand.Float64()*1.0
+		size := 1.0 + ps.particleRand.Float64()*2.0
 
 		particle := components.NewParticle(x, y, vx, vy, life, powerUpColor, size)
 		ps.particles = append(ps.particles, particle)
@@ -98,27 +126,25 @@ func (ps *ParticleSystem) CreatePowerUpEffect(x, y float64, powerUpColor color.R
 }
 
 func (ps *ParticleSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := ps.subscriptionScope(eventDispatcher)
+
 	// Listen for collision events to create particle effects
-	eventDispatcher.Subscribe(events.EventPacketCaught, func(event *events.Event) {
-		if event.Data.Packet != nil {
-			if packetEntity, ok := event.Data.Packet.(Entity); ok {
-				transformComp := packetEntity.GetTransform()
-				spriteComp := packetEntity.GetSprite()
-				if transformComp != nil && spriteComp != nil {
-					ps.CreatePacketCatchEffect(transformComp.GetX()+7.5, transformComp.GetY()+7.5, spriteComp.GetColor())
-				}
+	events.Subscribe(scope, func(caught events.PacketCaught) {
+		if packetEntity, ok := ps.resolve(caught.Packet); ok {
+			transformComp := packetEntity.GetTransform()
+			spriteComp := packetEntity.GetSprite()
+			if transformComp != nil && spriteComp != nil {
+				ps.CreatePacketCatchEffect(transformComp.GetX()+7.5, transformComp.GetY()+7.5, spriteComp.GetColor())
 			}
 		}
 	})
 
-	eventDispatcher.Subscribe(events.EventPowerUpCollected, func(event *events.Event) {
-		if event.Data.Packet != nil {
-			if powerupEntity, ok := event.Data.Packet.(Entity); ok {
-				transformComp := powerupEntity.GetTransform()
-				spriteComp := powerupEntity.GetSprite()
-				if transformComp != nil && spriteComp != nil {
-					ps.CreatePowerUpEffect(transformComp.GetX()+7.5, transformComp.GetY()+7.5, spriteComp.GetColor())
-				}
+	events.Subscribe(scope, func(collected events.PowerUpCollected) {
+		if powerupEntity, ok := ps.resolve(collected.PowerUp); ok {
+			transformComp := powerupEntity.GetTransform()
+			spriteComp := powerupEntity.GetSprite()
+			if transformComp != nil && spriteComp != nil {
+				ps.CreatePowerUpEffect(transformComp.GetX()+7.5, transformComp.GetY()+7.5, spriteComp.GetColor())
 			}
 		}
 	})
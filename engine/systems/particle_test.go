@@ -5,6 +5,7 @@ import (
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/entities"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/random"
 	"math"
 	"testing"
 
@@ -388,6 +389,8 @@ func TestParticleSystem_Initialize(t *testing.T) {
 
 func TestParticleSystem_EventHandling_PacketCaught(t *testing.T) {
 	ps := NewParticleSystem()
+	resolver := &stubResolver{}
+	ps.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -400,7 +403,7 @@ func TestParticleSystem_EventHandling_PacketCaught(t *testing.T) {
 
 	// Publish packet caught event
 	eventData := &events.EventData{
-		Packet: packetEntity,
+		Packet: resolver.register(packetEntity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -413,6 +416,8 @@ func TestParticleSystem_EventHandling_PacketCaught(t *testing.T) {
 
 func TestParticleSystem_EventHandling_PowerUpCollected(t *testing.T) {
 	ps := NewParticleSystem()
+	resolver := &stubResolver{}
+	ps.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -425,7 +430,7 @@ func TestParticleSystem_EventHandling_PowerUpCollected(t *testing.T) {
 
 	// Publish power-up collected event
 	eventData := &events.EventData{
-		Packet: powerUpEntity,
+		Packet: resolver.register(powerUpEntity),
 	}
 	event := events.NewEvent(events.EventPowerUpCollected, eventData)
 	eventDispatcher.Publish(event)
@@ -438,6 +443,8 @@ func TestParticleSystem_EventHandling_PowerUpCollected(t *testing.T) {
 
 func TestParticleSystem_EventHandling_InvalidEntity(t *testing.T) {
 	ps := NewParticleSystem()
+	resolver := &stubResolver{}
+	ps.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -447,7 +454,7 @@ func TestParticleSystem_EventHandling_InvalidEntity(t *testing.T) {
 
 	// Publish event with nil packet
 	eventData := &events.EventData{
-		Packet: nil,
+		Packet: components.EntityHandle{},
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -460,6 +467,8 @@ func TestParticleSystem_EventHandling_InvalidEntity(t *testing.T) {
 
 func TestParticleSystem_EventHandling_EntityWithoutComponents(t *testing.T) {
 	ps := NewParticleSystem()
+	resolver := &stubResolver{}
+	ps.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -472,7 +481,7 @@ func TestParticleSystem_EventHandling_EntityWithoutComponents(t *testing.T) {
 
 	// Publish event with entity without components
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: resolver.register(entity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -485,6 +494,8 @@ func TestParticleSystem_EventHandling_EntityWithoutComponents(t *testing.T) {
 
 func TestParticleSystem_Integration(t *testing.T) {
 	ps := NewParticleSystem()
+	resolver := &stubResolver{}
+	ps.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -495,8 +506,8 @@ func TestParticleSystem_Integration(t *testing.T) {
 	powerUpEntity := createTestPowerUpEntity(2, 200, 200, color.RGBA{0, 255, 0, 255})
 
 	// Publish events
-	packetEvent := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: packetEntity})
-	powerUpEvent := events.NewEvent(events.EventPowerUpCollected, &events.EventData{Packet: powerUpEntity})
+	packetEvent := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: resolver.register(packetEntity)})
+	powerUpEvent := events.NewEvent(events.EventPowerUpCollected, &events.EventData{Packet: resolver.register(powerUpEntity)})
 
 	eventDispatcher.Publish(packetEvent)
 	eventDispatcher.Publish(powerUpEvent)
@@ -552,3 +563,22 @@ func createTestPowerUpEntity(id uint64, x, y float64, color color.RGBA) Entity {
 	entity.AddComponent(sprite)
 	return entity
 }
+
+// TestParticleSystem_SeededEffectsAreReproducible tests that the particle stream drives effects
+func TestParticleSystem_SeededEffectsAreReproducible(t *testing.T) {
+	first, second := NewParticleSystem(), NewParticleSystem()
+	first.SetRNG(random.New(5))
+	second.SetRNG(random.New(5))
+
+	first.CreatePacketCatchEffect(100, 100, color.RGBA{255, 0, 0, 255})
+	second.CreatePacketCatchEffect(100, 100, color.RGBA{255, 0, 0, 255})
+
+	if len(first.particles) != len(second.particles) {
+		t.Fatalf("Expected same particle count, got %d and %d", len(first.particles), len(second.particles))
+	}
+	for i := range first.particles {
+		if *first.particles[i] != *second.particles[i] {
+			t.Errorf("Expected particle %d to match, got %+v and %+v", i, first.particles[i], second.particles[i])
+		}
+	}
+}
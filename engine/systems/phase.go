@@ -0,0 +1,101 @@
+package systems
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Phase is the part of a frame a system runs in. Dependencies are resolved
+// within each phase; between phases the manager delivers queued events,
+// applies deferred commands and removes deactivated entities from the world.
+type Phase int
+
+const (
+	PhaseUpdate     Phase = iota // Simulation; the default
+	PhasePreUpdate               // Input sampling, before the simulation
+	PhasePostUpdate              // Bookkeeping of what the simulation did
+	PhaseRender                  // Preparing what is drawn
+	PhaseCleanup                 // Teardown at the end of the frame
+)
+
+// Phases lists the phases in the order they run
+var Phases = []Phase{PhasePreUpdate, PhaseUpdate, PhasePostUpdate, PhaseRender, PhaseCleanup}
+
+func (p Phase) String() string {
+	switch p {
+	case PhasePreUpdate:
+		return "preupdate"
+	case PhaseUpdate:
+		return "update"
+	case PhasePostUpdate:
+		return "postupdate"
+	case PhaseRender:
+		return "render"
+	case PhaseCleanup:
+		return "cleanup"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// rank returns the position of the phase in Phases
+func (p Phase) rank() int {
+	return slices.Index(Phases, p)
+}
+
+// phaseStages are the stages of one phase
+type phaseStages struct {
+	phase  Phase
+	stages [][]SystemType
+}
+
+// EntityStore is the world as the manager sees it between phases
+type EntityStore interface {
+	// RemoveInactiveEntities removes the entities deactivated so far
+	RemoveInactiveEntities()
+	EntityList() []Entity
+}
+
+// AttachEntityStore makes the manager remove deactivated entities from store
+// at the end of every phase and hand the remaining ones to the next
+func (sm *SystemManager) AttachEntityStore(store EntityStore) {
+	sm.store = store
+}
+
+// GetPhaseOrder returns the update order of the systems of one phase
+func (sm *SystemManager) GetPhaseOrder(phase Phase) []SystemType {
+	order := make([]SystemType, 0)
+	for _, systemType := range sm.updateOrder {
+		if sm.systems[systemType].Phase == phase {
+			order = append(order, systemType)
+		}
+	}
+	return order
+}
+
+// GetPhaseStages returns the stages of one phase
+func (sm *SystemManager) GetPhaseStages(phase Phase) [][]SystemType {
+	for _, ps := range sm.phases {
+		if ps.phase == phase {
+			return ps.stages
+		}
+	}
+	return nil
+}
+
+// buildPhases groups the update order into the stages of each phase
+func (sm *SystemManager) buildPhases() {
+	sm.phases = make([]phaseStages, 0, len(Phases))
+	sm.stages = make([][]SystemType, 0)
+	for _, phase := range Phases {
+		order := sm.GetPhaseOrder(phase)
+		if len(order) == 0 {
+			continue
+		}
+		// Access is computed over every system, since the handlers of
+		// systems in other phases may run on this phase's workers
+		stages := buildStages(sm.systems, order, sm.resolver.dependencyGraph)
+		sm.phases = append(sm.phases, phaseStages{phase: phase, stages: stages})
+		sm.stages = append(sm.stages, stages...)
+	}
+}
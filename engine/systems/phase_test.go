@@ -0,0 +1,129 @@
+package systems
+
+import (
+	"lbbaspack/engine/events"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubStore is an entity store that records when inactive entities are removed
+type stubStore struct {
+	entities []Entity
+	removals int
+}
+
+func (ss *stubStore) RemoveInactiveEntities() {
+	ss.removals++
+	ss.entities = slices.DeleteFunc(ss.entities, func(entity Entity) bool { return !entity.IsActive() })
+}
+
+func (ss *stubStore) EntityList() []Entity {
+	return ss.entities
+}
+
+// phaseTestSystem deactivates every entity or counts those it is handed
+type phaseTestSystem struct {
+	BaseSystem
+	deactivate bool
+	seen       int
+	order      *[]string
+	name       string
+}
+
+func (pts *phaseTestSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+	*pts.order = append(*pts.order, pts.name)
+	pts.seen = len(entities)
+	if pts.deactivate {
+		for _, entity := range entities {
+			entity.(*systemTestEntity).isActive = false
+		}
+	}
+}
+
+// TestSystemManager_Phases tests that phases run in order, each resolved on its own
+func TestSystemManager_Phases(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	var order []string
+	teardown := &phaseTestSystem{name: "teardown", order: &order}
+	sampler := &phaseTestSystem{name: "sampler", order: &order}
+	simulate := &phaseTestSystem{name: "simulate", order: &order, deactivate: true}
+	tally := &phaseTestSystem{name: "tally", order: &order}
+	infos := []*SystemInfo{
+		{Type: "teardown", System: teardown, Phase: PhaseCleanup},
+		// A dependency on an earlier phase is met by the phase order
+		{Type: "tally", System: tally, Phase: PhasePostUpdate, Dependencies: []SystemType{"sampler"}},
+		{Type: "simulate", System: simulate},
+		{Type: "sampler", System: sampler, Phase: PhasePreUpdate, Provides: []string{"input"}},
+	}
+	for _, info := range infos {
+		if err := manager.RegisterSystem(info); err != nil {
+			t.Fatalf("Failed to register %s: %v", info.Type, err)
+		}
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+
+	want := []SystemType{"sampler", "simulate", "tally", "teardown"}
+	if !slices.Equal(manager.GetUpdateOrder(), want) {
+		t.Errorf("Expected update order %v, got %v", want, manager.GetUpdateOrder())
+	}
+	if got := manager.GetPhaseOrder(PhasePostUpdate); !slices.Equal(got, []SystemType{"tally"}) {
+		t.Errorf("Expected tally alone in post-update, got %v", got)
+	}
+	if stages := manager.GetPhaseStages(PhaseRender); stages != nil {
+		t.Errorf("Expected no render stages, got %v", stages)
+	}
+
+	// Entities deactivated by the simulation are gone by the next phase
+	store := &stubStore{entities: []Entity{newSystemTestEntity(1), newSystemTestEntity(2)}}
+	manager.AttachEntityStore(store)
+	manager.UpdateAll(1.0/60.0, store.EntityList(), events.NewEventDispatcher())
+
+	if !slices.Equal(order, []string{"sampler", "simulate", "tally", "teardown"}) {
+		t.Errorf("Expected systems to run phase by phase, got %v", order)
+	}
+	if simulate.seen != 2 || tally.seen != 0 || teardown.seen != 0 {
+		t.Errorf("Expected entities removed after the update phase, simulate saw %d, tally %d, teardown %d", simulate.seen, tally.seen, teardown.seen)
+	}
+	if store.removals != 4 {
+		t.Errorf("Expected a removal after each of the 4 phases, got %d", store.removals)
+	}
+}
+
+// TestSystemManager_PhaseErrors tests that systems cannot depend on later phases
+func TestSystemManager_PhaseErrors(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	if err := manager.RegisterSystem(&SystemInfo{Type: "odd", System: &phaseTestSystem{}, Phase: Phase(42)}); err == nil {
+		t.Error("Expected an unknown phase to be refused")
+	}
+
+	infos := []*SystemInfo{
+		{Type: "tally", System: &phaseTestSystem{}, Phase: PhasePostUpdate, Provides: []string{"score"}},
+		{Type: "simulate", System: &phaseTestSystem{}, Requires: []string{"score"}},
+	}
+	for _, info := range infos {
+		if err := manager.RegisterSystem(info); err != nil {
+			t.Fatalf("Failed to register %s: %v", info.Type, err)
+		}
+	}
+	err := manager.BuildExecutionOrder()
+	if err == nil || !strings.Contains(err.Error(), "later phase postupdate") {
+		t.Errorf("Expected a dependency on a later phase to fail, got %v", err)
+	}
+}
+
+// TestGameSystems_Phases tests the phases the game systems run in
+func TestGameSystems_Phases(t *testing.T) {
+	manager := newGameSystemManager(t)
+	order := manager.GetUpdateOrder()
+	if order[0] != SystemTypeInput || order[len(order)-1] != SystemTypeCleanup {
+		t.Errorf("Expected input sampled first and cleanup last, got %v", order)
+	}
+	if got := manager.GetPhaseOrder(PhaseRender); !slices.Equal(got, manager.GetDrawOrder()) {
+		t.Errorf("Expected the drawn systems to prepare in the render phase, got %v", got)
+	}
+}
@@ -1,17 +1,26 @@
 package systems
 
 import (
-	"fmt"
+	"encoding/json"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 )
 
 const SystemTypePowerUp SystemType = "powerup"
 
+var powerUpLog = logging.For(string(SystemTypePowerUp))
+
 type PowerUpSystem struct {
 	BaseSystem
 	activePowerUps map[string]float64 // powerup name -> remaining time
 }
 
+func init() {
+	Register(SystemTypePowerUp, func(ctx *SystemContext) SystemInfoer {
+		return NewPowerUpSystem()
+	})
+}
+
 func NewPowerUpSystem() *PowerUpSystem {
 	return &PowerUpSystem{
 		BaseSystem: BaseSystem{
@@ -28,12 +37,15 @@ func (pus *PowerUpSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypePowerUp,
 		System:       pus,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"powerup_management", "effect_activation"},
-		Requires:     []string{},
+		Requires:     []string{"collision_detection"},
 		Drawable:     false,
 		Optional:     true,
+		Phase:        PhaseUpdate,
+		Reads:        []string{EventResource(events.EventPowerUpCollected)},
+		Writes:       []string{EventResource(events.EventPowerUpActivated)},
 	}
 }
 
@@ -43,16 +55,18 @@ func (pus *PowerUpSystem) Update(deltaTime float64, entities []Entity, eventDisp
 		pus.activePowerUps[powerUpName] = remainingTime - deltaTime
 		if pus.activePowerUps[powerUpName] <= 0 {
 			delete(pus.activePowerUps, powerUpName)
-			fmt.Printf("Power-up %s expired\n", powerUpName)
+			powerUpLog.Info("Power-up expired", "powerUp", powerUpName)
 		}
 	}
 }
 
 func (pus *PowerUpSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := pus.subscriptionScope(eventDispatcher)
+
 	// Listen for power-up collected events
-	eventDispatcher.Subscribe(events.EventPowerUpCollected, func(event *events.Event) {
-		if event.Data.Powerup != nil {
-			pus.activatePowerUp(*event.Data.Powerup, eventDispatcher)
+	events.Subscribe(scope, func(collected events.PowerUpCollected) {
+		if collected.Name != "" {
+			pus.activatePowerUp(collected.Name, eventDispatcher)
 		}
 	})
 }
@@ -72,13 +86,28 @@ func (pus *PowerUpSystem) activatePowerUp(powerUpName string, eventDispatcher *e
 	}
 
 	pus.activePowerUps[powerUpName] = duration
-	fmt.Printf("Power-up %s activated for %.1f seconds\n", powerUpName, duration)
+	powerUpLog.Info("Power-up activated", "powerUp", powerUpName, "duration", duration)
 
 	// Publish power-up activated event
-	eventDispatcher.Publish(events.NewEvent(events.EventPowerUpActivated, &events.EventData{
-		Powerup:  &powerUpName,
-		Duration: &duration,
-	}))
+	events.Publish(eventDispatcher, events.PowerUpActivated{
+		Name:     powerUpName,
+		Duration: duration,
+	})
+}
+
+// SaveState implements StatefulSystem
+func (pus *PowerUpSystem) SaveState() ([]byte, error) {
+	return json.Marshal(pus.activePowerUps)
+}
+
+// LoadState implements StatefulSystem
+func (pus *PowerUpSystem) LoadState(data []byte) error {
+	activePowerUps := make(map[string]float64)
+	if err := json.Unmarshal(data, &activePowerUps); err != nil {
+		return err
+	}
+	pus.activePowerUps = activePowerUps
+	return nil
 }
 
 func (pus *PowerUpSystem) IsPowerUpActive(powerUpName string) bool {
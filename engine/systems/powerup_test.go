@@ -518,3 +518,23 @@ func TestPowerUpSystem_Integration(t *testing.T) {
 		t.Errorf("Expected GetActivePowerUps to return 2 power-ups, got %d", len(activePowerUps))
 	}
 }
+
+// TestPowerUpSystem_SaveLoadState tests that active power-ups survive a roundtrip
+func TestPowerUpSystem_SaveLoadState(t *testing.T) {
+	pus := NewPowerUpSystem()
+	pus.activePowerUps["Speed Boost"] = 3.5
+
+	data, err := pus.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewPowerUpSystem()
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if !restored.IsPowerUpActive("Speed Boost") || restored.GetActivePowerUps()["Speed Boost"] != 3.5 {
+		t.Errorf("Expected Speed Boost with 3.5s left, got %v", restored.GetActivePowerUps())
+	}
+}
@@ -0,0 +1,411 @@
+package systems
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/basicfont"
+)
+
+// SystemTypeRender identifies the render system in profiles. It is drawn by
+// the playing scene rather than the system manager.
+const SystemTypeRender SystemType = "render"
+
+// DefaultProfileWindow is the number of frames a profiler keeps per system
+const DefaultProfileWindow = 300
+
+// ProfilePhase tells whether a sample timed a system's update or its draw
+type ProfilePhase string
+
+const (
+	ProfileUpdate ProfilePhase = "update"
+	ProfileDraw   ProfilePhase = "draw"
+)
+
+// ProfileBuckets are the upper bounds of the histogram buckets of
+// SystemStats; the last bucket counts every slower call
+var ProfileBuckets = []time.Duration{
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2 * time.Millisecond,
+	4 * time.Millisecond,
+	8 * time.Millisecond,
+	16 * time.Millisecond,
+}
+
+// allocMetrics are the runtime metrics read around every profiled call
+var allocMetrics = [...]string{"/gc/heap/allocs:bytes", "/gc/heap/allocs:objects"}
+
+// allocSamples holds the runtime metrics read before and after a call
+type allocSamples struct {
+	before, after [len(allocMetrics)]metrics.Sample
+}
+
+// newAllocSamples creates buffers for reading allocMetrics
+func newAllocSamples() *allocSamples {
+	samples := &allocSamples{}
+	for i, name := range allocMetrics {
+		samples.before[i].Name = name
+		samples.after[i].Name = name
+	}
+	return samples
+}
+
+// processedCounter is implemented by systems that count the entities they
+// process (every BaseSystem does, through RequiredEntities, FilterEntities
+// and typed queries)
+type processedCounter interface {
+	takeProcessed() int
+}
+
+// profileSample is one timed call of a system
+type profileSample struct {
+	duration     time.Duration
+	entities     int
+	allocBytes   uint64
+	allocObjects uint64
+}
+
+// profileKey identifies the samples of one system in one phase
+type profileKey struct {
+	system SystemType
+	phase  ProfilePhase
+}
+
+// profileSeries is a rolling window of samples
+type profileSeries struct {
+	calls   uint64
+	samples []profileSample
+	next    int
+}
+
+// add stores a sample, overwriting the oldest once the window is full
+func (ps *profileSeries) add(sample profileSample, window int) {
+	ps.calls++
+	if len(ps.samples) < window {
+		ps.samples = append(ps.samples, sample)
+		return
+	}
+	ps.samples[ps.next] = sample
+	ps.next = (ps.next + 1) % window
+}
+
+// last returns the most recent sample
+func (ps *profileSeries) last() profileSample {
+	if len(ps.samples) < cap(ps.samples) || ps.next == 0 {
+		return ps.samples[len(ps.samples)-1]
+	}
+	return ps.samples[ps.next-1]
+}
+
+// Profiler records how long every system takes per frame, how many entities
+// it processed and how much it allocated, over a rolling window of frames.
+// Allocations are read from process-wide counters, so in parallel stages they
+// include whatever the systems running alongside allocated.
+type Profiler struct {
+	mu     sync.Mutex
+	window int
+	series map[profileKey]*profileSeries
+	// spare holds the metric buffers of calls that have finished, one set
+	// for each call measured at the same time
+	spare []*allocSamples
+}
+
+// NewProfiler creates a profiler keeping the last window calls per system
+func NewProfiler(window int) *Profiler {
+	if window <= 0 {
+		window = DefaultProfileWindow
+	}
+	return &Profiler{
+		window: window,
+		series: make(map[profileKey]*profileSeries),
+	}
+}
+
+// Measure times fn as one call of systemType in phase. If system counts the
+// entities it processes, that count is recorded with the call.
+func (p *Profiler) Measure(systemType SystemType, phase ProfilePhase, system interface{}, fn func()) {
+	counter, counts := system.(processedCounter)
+	if counts {
+		counter.takeProcessed()
+	}
+
+	samples := p.takeSamples()
+
+	metrics.Read(samples.before[:])
+	start := time.Now()
+	fn()
+	duration := time.Since(start)
+	metrics.Read(samples.after[:])
+
+	sample := profileSample{
+		duration:     duration,
+		allocBytes:   allocDelta(samples.before[0], samples.after[0]),
+		allocObjects: allocDelta(samples.before[1], samples.after[1]),
+	}
+	if counts {
+		sample.entities = counter.takeProcessed()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.spare = append(p.spare, samples)
+	key := profileKey{system: systemType, phase: phase}
+	series, exists := p.series[key]
+	if !exists {
+		series = &profileSeries{samples: make([]profileSample, 0, p.window)}
+		p.series[key] = series
+	}
+	series.add(sample, p.window)
+}
+
+// takeSamples returns spare metric buffers, or new ones if every set is in
+// use by a call being measured
+func (p *Profiler) takeSamples() *allocSamples {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.spare)
+	if n == 0 {
+		return newAllocSamples()
+	}
+	samples := p.spare[n-1]
+	p.spare = p.spare[:n-1]
+	return samples
+}
+
+// allocDelta returns how much a cumulative counter grew, or 0 if the
+// runtime does not support it
+func allocDelta(before, after metrics.Sample) uint64 {
+	if before.Value.Kind() != metrics.KindUint64 || after.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return after.Value.Uint64() - before.Value.Uint64()
+}
+
+// Reset discards every recorded sample
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.series = make(map[profileKey]*profileSeries)
+}
+
+// SystemStats summarizes the calls of one system in one phase over the
+// profiler's window
+type SystemStats struct {
+	System       SystemType    `json:"system"`
+	Phase        ProfilePhase  `json:"phase"`
+	Calls        uint64        `json:"calls"`
+	Samples      int           `json:"samples"`
+	Last         time.Duration `json:"lastNs"`
+	Mean         time.Duration `json:"meanNs"`
+	P95          time.Duration `json:"p95Ns"`
+	Max          time.Duration `json:"maxNs"`
+	Entities     float64       `json:"entities"`
+	AllocBytes   float64       `json:"allocBytes"`
+	AllocObjects float64       `json:"allocObjects"`
+	Histogram    []int         `json:"histogram"` // Calls per ProfileBuckets bucket
+}
+
+// summarize computes the statistics of a series
+func (ps *profileSeries) summarize(key profileKey) SystemStats {
+	stats := SystemStats{
+		System:    key.system,
+		Phase:     key.phase,
+		Calls:     ps.calls,
+		Samples:   len(ps.samples),
+		Histogram: make([]int, len(ProfileBuckets)+1),
+	}
+	if len(ps.samples) == 0 {
+		return stats
+	}
+	stats.Last = ps.last().duration
+
+	durations := make([]time.Duration, len(ps.samples))
+	var total time.Duration
+	var entities, allocBytes, allocObjects uint64
+	for i, sample := range ps.samples {
+		durations[i] = sample.duration
+		total += sample.duration
+		entities += uint64(sample.entities)
+		allocBytes += sample.allocBytes
+		allocObjects += sample.allocObjects
+
+		bucket, _ := slices.BinarySearch(ProfileBuckets, sample.duration)
+		stats.Histogram[bucket]++
+	}
+	slices.Sort(durations)
+
+	n := len(ps.samples)
+	stats.Mean = total / time.Duration(n)
+	stats.P95 = durations[(n*95+99)/100-1]
+	stats.Max = durations[n-1]
+	stats.Entities = float64(entities) / float64(n)
+	stats.AllocBytes = float64(allocBytes) / float64(n)
+	stats.AllocObjects = float64(allocObjects) / float64(n)
+	return stats
+}
+
+// Stats returns the statistics of every profiled system, updates first,
+// each phase sorted by system type
+func (p *Profiler) Stats() []SystemStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]SystemStats, 0, len(p.series))
+	for key, series := range p.series {
+		stats = append(stats, series.summarize(key))
+	}
+	slices.SortFunc(stats, func(a, b SystemStats) int {
+		if a.Phase != b.Phase {
+			if a.Phase == ProfileUpdate {
+				return -1
+			}
+			if b.Phase == ProfileUpdate {
+				return 1
+			}
+			return strings.Compare(string(a.Phase), string(b.Phase))
+		}
+		return strings.Compare(string(a.System), string(b.System))
+	})
+	return stats
+}
+
+// SystemStats returns the statistics of one system in one phase
+func (p *Profiler) SystemStats(systemType SystemType, phase ProfilePhase) (SystemStats, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := profileKey{system: systemType, phase: phase}
+	series, exists := p.series[key]
+	if !exists {
+		return SystemStats{}, false
+	}
+	return series.summarize(key), true
+}
+
+// profileReport is the JSON form of a profile
+type profileReport struct {
+	Window  int             `json:"window"`
+	Buckets []time.Duration `json:"bucketsNs"`
+	Systems []SystemStats   `json:"systems"`
+}
+
+// WriteJSON writes the statistics of every system as a JSON report
+func (p *Profiler) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(profileReport{
+		Window:  p.window,
+		Buckets: ProfileBuckets,
+		Systems: p.Stats(),
+	})
+}
+
+// WriteCSV writes the statistics of every system as CSV, one row per system
+// and phase with times in microseconds
+func (p *Profiler) WriteCSV(w io.Writer) error {
+	header := []string{"system", "phase", "calls", "samples", "last_us", "mean_us", "p95_us", "max_us", "entities", "alloc_bytes", "alloc_objects"}
+	for _, bound := range ProfileBuckets {
+		header = append(header, "le_"+strconv.FormatInt(bound.Microseconds(), 10)+"us")
+	}
+	header = append(header, "slower")
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, stats := range p.Stats() {
+		row := []string{
+			string(stats.System),
+			string(stats.Phase),
+			strconv.FormatUint(stats.Calls, 10),
+			strconv.Itoa(stats.Samples),
+			microseconds(stats.Last),
+			microseconds(stats.Mean),
+			microseconds(stats.P95),
+			microseconds(stats.Max),
+			strconv.FormatFloat(stats.Entities, 'f', 1, 64),
+			strconv.FormatFloat(stats.AllocBytes, 'f', 1, 64),
+			strconv.FormatFloat(stats.AllocObjects, 'f', 1, 64),
+		}
+		for _, count := range stats.Histogram {
+			row = append(row, strconv.Itoa(count))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// microseconds formats a duration in microseconds for reports
+func microseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Microsecond), 'f', 1, 64)
+}
+
+// WriteReport writes the report to path, as CSV if it ends in .csv and as
+// JSON otherwise
+func (p *Profiler) WriteReport(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create profile report: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = p.WriteCSV(file)
+	} else {
+		err = p.WriteJSON(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write profile report: %w", err)
+	}
+	return nil
+}
+
+// overlayLines formats the statistics shown by DrawOverlay, slowest first
+func (p *Profiler) overlayLines() []string {
+	stats := p.Stats()
+	slices.SortStableFunc(stats, func(a, b SystemStats) int {
+		return cmp.Compare(b.Mean, a.Mean)
+	})
+
+	lines := []string{fmt.Sprintf("%-16s %8s %8s %8s %6s %8s", "system", "mean", "p95", "max", "ents", "alloc")}
+	for _, s := range stats {
+		name := string(s.System)
+		if s.Phase != ProfileUpdate {
+			name += "/" + string(s.Phase)
+		}
+		lines = append(lines, fmt.Sprintf("%-16s %8s %8s %8s %6.0f %7.0fB",
+			name, microseconds(s.Mean), microseconds(s.P95), microseconds(s.Max), s.Entities, s.AllocBytes))
+	}
+	return lines
+}
+
+// DrawOverlay draws a table of the per-system statistics, slowest first,
+// in the top left corner of screen. Times are in microseconds.
+func (p *Profiler) DrawOverlay(screen *ebiten.Image) {
+	lines := p.overlayLines()
+	const lineHeight = 14
+	vector.DrawFilledRect(screen, 5, 5, 470, float32(len(lines)*lineHeight+10), color.RGBA{0, 0, 0, 200}, false)
+	for i, line := range lines {
+		text.Draw(screen, line, basicfont.Face7x13, 10, 20+i*lineHeight, color.RGBA{200, 255, 200, 255})
+	}
+}
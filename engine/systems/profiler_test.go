@@ -0,0 +1,193 @@
+package systems
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"lbbaspack/engine/events"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProfiler_Window tests that only the last window calls are summarized
+func TestProfiler_Window(t *testing.T) {
+	p := NewProfiler(3)
+	for i := 0; i < 5; i++ {
+		p.Measure(SystemTypeCombo, ProfileUpdate, nil, func() {})
+	}
+
+	stats, ok := p.SystemStats(SystemTypeCombo, ProfileUpdate)
+	if !ok {
+		t.Fatal("Expected stats for the combo system")
+	}
+	if stats.Calls != 5 {
+		t.Errorf("Expected 5 calls, got %d", stats.Calls)
+	}
+	if stats.Samples != 3 {
+		t.Errorf("Expected 3 samples, got %d", stats.Samples)
+	}
+
+	total := 0
+	for _, count := range stats.Histogram {
+		total += count
+	}
+	if total != 3 || len(stats.Histogram) != len(ProfileBuckets)+1 {
+		t.Errorf("Expected 3 calls in %d buckets, got %v", len(ProfileBuckets)+1, stats.Histogram)
+	}
+
+	if _, ok := p.SystemStats(SystemTypeCombo, ProfileDraw); ok {
+		t.Error("Expected no stats for a phase that never ran")
+	}
+	p.Reset()
+	if len(p.Stats()) != 0 {
+		t.Error("Expected no stats after Reset")
+	}
+}
+
+// TestProfiler_MeasureDoesNotAllocate tests that measuring reuses its metric
+// buffers once the window is full
+func TestProfiler_MeasureDoesNotAllocate(t *testing.T) {
+	p := NewProfiler(3)
+	fn := func() {}
+	for range 3 {
+		p.Measure(SystemTypeCombo, ProfileUpdate, nil, fn)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Measure(SystemTypeCombo, ProfileUpdate, nil, fn)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected Measure to run without allocating, got %v allocations per call", allocs)
+	}
+}
+
+// TestProfileSeries_Summarize tests the statistics computed from a window
+func TestProfileSeries_Summarize(t *testing.T) {
+	series := &profileSeries{samples: make([]profileSample, 0, 20)}
+	for i := 1; i <= 20; i++ {
+		series.add(profileSample{duration: time.Duration(i) * time.Millisecond, entities: 10, allocBytes: 64}, 20)
+	}
+	// Overwrites the 1ms sample
+	series.add(profileSample{duration: 30 * time.Millisecond, entities: 30, allocBytes: 64}, 20)
+
+	stats := series.summarize(profileKey{system: SystemTypeCollision, phase: ProfileUpdate})
+	if stats.Last != 30*time.Millisecond {
+		t.Errorf("Expected last 30ms, got %v", stats.Last)
+	}
+	if stats.Max != 30*time.Millisecond {
+		t.Errorf("Expected max 30ms, got %v", stats.Max)
+	}
+	if stats.P95 != 20*time.Millisecond {
+		t.Errorf("Expected p95 20ms, got %v", stats.P95)
+	}
+	// (2+...+20+30)/20 = 239/20
+	if stats.Mean != 11950*time.Microsecond {
+		t.Errorf("Expected mean 11.95ms, got %v", stats.Mean)
+	}
+	if stats.Entities != 11 {
+		t.Errorf("Expected 11 entities on average, got %v", stats.Entities)
+	}
+	if stats.AllocBytes != 64 {
+		t.Errorf("Expected 64 bytes on average, got %v", stats.AllocBytes)
+	}
+	// Calls over 16ms land in the last bucket
+	if slower := stats.Histogram[len(ProfileBuckets)]; slower != 5 {
+		t.Errorf("Expected 5 calls slower than 16ms, got %d", slower)
+	}
+}
+
+// TestProfiler_Entities tests that the entities returned by FilterEntities are counted
+func TestProfiler_Entities(t *testing.T) {
+	cs := NewComboSystem()
+	entities := []Entity{createComboEntity(1), createComboEntity(2), newSystemTestEntity(3)}
+	p := NewProfiler(10)
+
+	cs.FilterEntities(entities) // Outside the profiler, not counted
+	p.Measure(SystemTypeCombo, ProfileUpdate, cs, func() {
+		cs.Update(0.016, entities, events.NewEventDispatcher())
+	})
+
+	stats, _ := p.SystemStats(SystemTypeCombo, ProfileUpdate)
+	if stats.Entities != 2 {
+		t.Errorf("Expected 2 entities processed, got %v", stats.Entities)
+	}
+}
+
+// TestSystemManager_Profiler tests that every system update is profiled
+func TestSystemManager_Profiler(t *testing.T) {
+	manager := newGameSystemManager(t)
+	manager.SetVerbose(false)
+	p := NewProfiler(10)
+	manager.SetProfiler(p)
+	if manager.Profiler() != p {
+		t.Fatal("Expected the attached profiler")
+	}
+
+	dispatcher := events.NewEventDispatcher()
+	for i := 0; i < 3; i++ {
+		manager.UpdateAll(0.016, nil, dispatcher)
+	}
+
+	for _, systemType := range manager.GetUpdateOrder() {
+		stats, ok := p.SystemStats(systemType, ProfileUpdate)
+		if !ok || stats.Calls != 3 {
+			t.Errorf("Expected 3 profiled updates of %s, got %+v", systemType, stats)
+		}
+	}
+
+	manager.SetProfiler(nil)
+	manager.UpdateAll(0.016, nil, dispatcher)
+	if stats, _ := p.SystemStats(SystemTypeCollision, ProfileUpdate); stats.Calls != 3 {
+		t.Errorf("Expected no more calls once the profiler is removed, got %d", stats.Calls)
+	}
+}
+
+// TestProfiler_Reports tests the JSON and CSV reports
+func TestProfiler_Reports(t *testing.T) {
+	p := NewProfiler(10)
+	p.Measure(SystemTypeCollision, ProfileUpdate, nil, func() {})
+	p.Measure(SystemTypeParticle, ProfileDraw, nil, func() {})
+	p.Measure(SystemTypeCombo, ProfileUpdate, nil, func() {})
+
+	var report profileReport
+	var buf bytes.Buffer
+	if err := p.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if len(report.Systems) != 3 || report.Window != 10 {
+		t.Fatalf("Expected 3 systems with window 10, got %+v", report)
+	}
+	// Updates first, sorted by system
+	if report.Systems[0].System != SystemTypeCollision || report.Systems[1].System != SystemTypeCombo || report.Systems[2].Phase != ProfileDraw {
+		t.Errorf("Unexpected report order: %+v", report.Systems)
+	}
+
+	path := filepath.Join(t.TempDir(), "profile.csv")
+	if err := p.WriteReport(path); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Report is not valid CSV: %v", err)
+	}
+	if len(rows) != 4 || rows[0][0] != "system" || rows[1][0] != string(SystemTypeCollision) {
+		t.Errorf("Unexpected CSV report: %v", rows)
+	}
+	if len(rows[1]) != len(rows[0]) {
+		t.Errorf("Expected %d columns, got %d", len(rows[0]), len(rows[1]))
+	}
+
+	lines := p.overlayLines()
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "system") {
+		t.Errorf("Unexpected overlay: %v", lines)
+	}
+}
@@ -0,0 +1,90 @@
+package systems
+
+import (
+	"iter"
+	"lbbaspack/engine/components"
+	"reflect"
+)
+
+// Table holds the entities that share a set of component types, with one
+// column of components for each type asked for
+type Table struct {
+	Entities []Entity
+	Columns  [][]components.Component
+}
+
+// TableKey lists the component types a query reads, in order, with nil after
+// the last. It is an array so that asking for tables does not allocate.
+type TableKey [3]reflect.Type
+
+// ComponentTables gives systems typed access to the world's component
+// tables, without looking components up by name. Implemented by ecs.World.
+type ComponentTables interface {
+	// Tables returns every table whose entities have all of the types of
+	// key, with their columns in the order of key. The tables are the
+	// world's own, read in place: until ReleaseTables is called, a table is
+	// copied before rows are moved out of it.
+	Tables(key TableKey) []Table
+	// ReleaseTables ends a read of the tables returned by Tables
+	ReleaseTables()
+}
+
+// TablesUser is implemented by systems that query component tables directly
+type TablesUser interface {
+	SetTables(tables ComponentTables)
+}
+
+// Row2 holds the components yielded by Query2 for a single entity
+type Row2[A, B components.Component] struct {
+	First  A
+	Second B
+}
+
+// Query2 iterates over the active entities that have components of both
+// type A and B: those of the tables when given, or else those of entities,
+// whose components are looked up by name. The tables are read as they were
+// when iteration started, so the world may be changed during iteration; the
+// changes are not seen by it.
+//
+// A and B must be the stored pointer types, e.g. *components.Transform.
+func Query2[A, B components.Component](tables ComponentTables, entities []Entity) iter.Seq2[Entity, Row2[A, B]] {
+	return func(yield func(Entity, Row2[A, B]) bool) {
+		query2(tables, entities, yield)
+	}
+}
+
+func query2[A, B components.Component](tables ComponentTables, entities []Entity, yield func(Entity, Row2[A, B]) bool) {
+	if tables == nil {
+		// The component types name themselves without reading their value
+		var a A
+		var b B
+		nameA, nameB := a.GetType(), b.GetType()
+		for _, entity := range entities {
+			if !entity.IsActive() {
+				continue
+			}
+			first, okA := entity.GetComponent(nameA).(A)
+			second, okB := entity.GetComponent(nameB).(B)
+			if okA && okB && !yield(entity, Row2[A, B]{First: first, Second: second}) {
+				return
+			}
+		}
+		return
+	}
+
+	defer tables.ReleaseTables()
+	for _, table := range tables.Tables(TableKey{reflect.TypeFor[A](), reflect.TypeFor[B]()}) {
+		for row, entity := range table.Entities {
+			if !entity.IsActive() {
+				continue
+			}
+			result := Row2[A, B]{
+				First:  table.Columns[0][row].(A),
+				Second: table.Columns[1][row].(B),
+			}
+			if !yield(entity, result) {
+				return
+			}
+		}
+	}
+}
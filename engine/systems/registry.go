@@ -0,0 +1,120 @@
+package systems
+
+import (
+	"encoding/json"
+	"fmt"
+	"lbbaspack/engine/events"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Constructor creates a registered system
+type Constructor func(ctx *SystemContext) SystemInfoer
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[SystemType]Constructor{}
+)
+
+// Register associates a system type with the constructor that creates it.
+// Systems register themselves from their own files; registering an existing
+// type replaces its constructor.
+func Register(systemType SystemType, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[systemType] = constructor
+}
+
+// RegisteredSystemTypes returns all registered system types in sorted order
+func RegisteredSystemTypes() []SystemType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]SystemType, 0, len(registry))
+	for systemType := range registry {
+		types = append(types, systemType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// SystemContext is what constructors create systems from
+type SystemContext struct {
+	EntityFactory   func() Entity
+	EventDispatcher *events.EventDispatcher
+
+	constructors map[SystemType]Constructor
+	created      map[SystemType]SystemInfoer
+}
+
+// newSystemContext creates a context for the currently registered systems
+func newSystemContext(entityFactory func() Entity, eventDispatcher *events.EventDispatcher) *SystemContext {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	ctx := &SystemContext{
+		EntityFactory:   entityFactory,
+		EventDispatcher: eventDispatcher,
+		constructors:    make(map[SystemType]Constructor, len(registry)),
+		created:         make(map[SystemType]SystemInfoer),
+	}
+	for systemType, constructor := range registry {
+		ctx.constructors[systemType] = constructor
+	}
+	return ctx
+}
+
+// System returns the system of systemType created for this context, creating
+// it first if needed, so constructors can hand one system to another
+func (ctx *SystemContext) System(systemType SystemType) (SystemInfoer, bool) {
+	if system, exists := ctx.created[systemType]; exists {
+		return system, true
+	}
+	constructor, exists := ctx.constructors[systemType]
+	if !exists {
+		return nil, false
+	}
+	system := constructor(ctx)
+	ctx.created[systemType] = system
+	return system, true
+}
+
+// SystemConfig chooses which registered systems a new manager enables.
+// Systems that are not enabled are still registered, disabled, so they can
+// be enabled while the game runs. Only optional systems can be left out.
+type SystemConfig struct {
+	// Enabled lists the systems to enable; when empty, every registered
+	// system is enabled
+	Enabled []SystemType `json:"enabled,omitempty"`
+	// Disabled lists systems to leave out of Enabled
+	Disabled []SystemType `json:"disabled,omitempty"`
+}
+
+// LoadSystemConfig reads a system configuration from a JSON file
+func LoadSystemConfig(path string) (SystemConfig, error) {
+	var config SystemConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read system config: %w", err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse system config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// disabledTypes returns the registered types config leaves out, in order
+func (config SystemConfig) disabledTypes(registered []SystemType) ([]SystemType, error) {
+	for _, systemType := range slices.Concat(config.Enabled, config.Disabled) {
+		if !slices.Contains(registered, systemType) {
+			return nil, fmt.Errorf("unknown system %s in system config", systemType)
+		}
+	}
+	disabled := make([]SystemType, 0)
+	for _, systemType := range registered {
+		enabled := len(config.Enabled) == 0 || slices.Contains(config.Enabled, systemType)
+		if !enabled || slices.Contains(config.Disabled, systemType) {
+			disabled = append(disabled, systemType)
+		}
+	}
+	return disabled, nil
+}
@@ -0,0 +1,243 @@
+package systems
+
+import (
+	"errors"
+	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// scoreboardSystem is an optional system that requires the combo system's capability
+type scoreboardSystem struct {
+	BaseSystem
+	initialized int
+}
+
+func (ss *scoreboardSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+}
+
+func (ss *scoreboardSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	ss.initialized++
+}
+
+func (ss *scoreboardSystem) info() *SystemInfo {
+	return &SystemInfo{Type: "scoreboard", System: ss, Requires: []string{"combo_tracking"}, Optional: true, Phase: PhasePostUpdate}
+}
+
+// newTestFactoryManager creates a manager from the registered systems with config
+func newTestFactoryManager(t *testing.T, config SystemConfig) (*SystemManager, *events.EventDispatcher, error) {
+	t.Helper()
+	dispatcher := events.NewEventDispatcher()
+	factory := NewSystemFactory(func() Entity { return entities.NewEntity(1) }, dispatcher)
+	manager, err := factory.CreateConfiguredSystemManager(config)
+	if manager != nil {
+		manager.SetVerbose(false)
+	}
+	return manager, dispatcher, err
+}
+
+// TestRegisteredSystemTypes tests that every game system registers itself
+func TestRegisteredSystemTypes(t *testing.T) {
+	registered := RegisteredSystemTypes()
+	for _, systemType := range []SystemType{
+		SystemTypeSpawn, SystemTypeInput, SystemTypeMovement, SystemTypeCollision,
+		SystemTypePowerUp, SystemTypeBackend, SystemTypeSLA, SystemTypeCombo,
+		SystemTypeGameState, SystemTypeParticle, SystemTypeRouting,
+		SystemTypePacketRouting, SystemTypeCleanup,
+	} {
+		if !slices.Contains(registered, systemType) {
+			t.Errorf("Expected system %s to be registered", systemType)
+		}
+	}
+}
+
+// TestSystemFactory_Config tests that the config chooses the enabled systems
+func TestSystemFactory_Config(t *testing.T) {
+	manager, _, err := newTestFactoryManager(t, SystemConfig{Disabled: []SystemType{SystemTypeParticle, SystemTypeRouting}})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if manager.IsEnabled(SystemTypeParticle) || slices.Contains(manager.GetUpdateOrder(), SystemTypeRouting) {
+		t.Errorf("Expected particle and routing to be disabled, got order %v", manager.GetUpdateOrder())
+	}
+	if disabled := manager.DisabledSystems(); len(disabled) != 2 {
+		t.Errorf("Expected 2 disabled systems, got %v", disabled)
+	}
+	slaSys, _ := manager.GetSystem(SystemTypeSLA)
+	spawnSys, _ := manager.GetSystem(SystemTypeSpawn)
+	if slaSys.(*SLASystem).spawnSys != spawnSys {
+		t.Error("Expected the SLA system to be given the manager's spawn system")
+	}
+
+	enabled := slices.DeleteFunc(RegisteredSystemTypes(), func(systemType SystemType) bool {
+		return systemType == SystemTypeCombo
+	})
+	if manager, _, err := newTestFactoryManager(t, SystemConfig{Enabled: enabled}); err != nil || manager.IsEnabled(SystemTypeCombo) {
+		t.Errorf("Expected only combo to be left out, got %v", err)
+	}
+
+	if _, _, err := newTestFactoryManager(t, SystemConfig{Disabled: []SystemType{SystemTypeSLA}}); err == nil {
+		t.Error("Expected an error for disabling a required system")
+	}
+	if _, _, err := newTestFactoryManager(t, SystemConfig{Disabled: []SystemType{"teleporter"}}); err == nil {
+		t.Error("Expected an error for an unknown system")
+	}
+}
+
+// TestLoadSystemConfig tests reading a system config file
+func TestLoadSystemConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "systems.json")
+	if err := os.WriteFile(path, []byte(`{"disabled": ["particle"]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, err := LoadSystemConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSystemConfig failed: %v", err)
+	}
+	if len(config.Disabled) != 1 || config.Disabled[0] != SystemTypeParticle {
+		t.Errorf("Unexpected config %+v", config)
+	}
+}
+
+// TestSystemManager_EnableDisable tests turning systems off and on while running
+func TestSystemManager_EnableDisable(t *testing.T) {
+	manager, dispatcher, err := newTestFactoryManager(t, SystemConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	comboSys, _ := manager.GetSystem(SystemTypeCombo)
+	combo := comboSys.(*ComboSystem)
+
+	if err := manager.Disable(SystemTypeCombo); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if slices.Contains(manager.GetUpdateOrder(), SystemTypeCombo) {
+		t.Error("Expected combo to leave the update order")
+	}
+	events.Publish(dispatcher, events.PacketCaught{})
+	if combo.currentCombo != 0 {
+		t.Error("Expected a disabled system to stop receiving events")
+	}
+
+	if err := manager.Enable(SystemTypeCombo); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	events.Publish(dispatcher, events.PacketCaught{})
+	if combo.currentCombo != 1 || !slices.Contains(manager.GetUpdateOrder(), SystemTypeCombo) {
+		t.Errorf("Expected combo to run again, got combo %d", combo.currentCombo)
+	}
+
+	if err := manager.Disable(SystemTypeCollision); err == nil || !strings.Contains(err.Error(), "not optional") {
+		t.Errorf("Expected collision to be required, got %v", err)
+	}
+	if err := manager.Enable("teleporter"); err == nil {
+		t.Error("Expected an error for an unknown system")
+	}
+}
+
+// TestSystemManager_DisableRollback tests that a system stays enabled when the order cannot be rebuilt without it
+func TestSystemManager_DisableRollback(t *testing.T) {
+	manager, dispatcher, err := newTestFactoryManager(t, SystemConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	comboSys, _ := manager.GetSystem(SystemTypeCombo)
+	combo := comboSys.(*ComboSystem)
+
+	// Registered without rebuilding, so the next rebuild fails
+	broken := &SystemInfo{Type: "broken", System: &scoreboardSystem{}, Dependencies: []SystemType{"teleporter"}, Phase: PhaseUpdate}
+	if err := manager.RegisterSystem(broken); err != nil {
+		t.Fatalf("RegisterSystem failed: %v", err)
+	}
+
+	if err := manager.Disable(SystemTypeCombo); err == nil {
+		t.Fatal("Expected Disable to fail when the order cannot be rebuilt")
+	}
+	if !manager.IsEnabled(SystemTypeCombo) || slices.Contains(manager.DisabledSystems(), SystemTypeCombo) {
+		t.Errorf("Expected combo to stay enabled, got disabled %v", manager.DisabledSystems())
+	}
+	events.Publish(dispatcher, events.PacketCaught{})
+	if combo.currentCombo != 1 {
+		t.Error("Expected combo to keep receiving events")
+	}
+}
+
+// TestSystemManager_AddRemove tests registering systems while running
+func TestSystemManager_AddRemove(t *testing.T) {
+	manager, _, err := newTestFactoryManager(t, SystemConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	scoreboard := &scoreboardSystem{}
+	if err := manager.Add(scoreboard.info()); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	order := manager.GetUpdateOrder()
+	if scoreboard.initialized != 1 || slices.Index(order, "scoreboard") < slices.Index(order, SystemTypeCombo) {
+		t.Errorf("Expected the scoreboard to be initialized and run after combo, got %v", order)
+	}
+
+	// The scoreboard requires a capability only combo provides
+	if err := manager.Disable(SystemTypeCombo); err == nil || !strings.Contains(err.Error(), "scoreboard") {
+		t.Errorf("Expected combo to be kept for the scoreboard, got %v", err)
+	}
+	if err := manager.Remove("scoreboard"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := manager.Disable(SystemTypeCombo); err != nil {
+		t.Errorf("Expected combo to be disabled once the scoreboard is gone, got %v", err)
+	}
+
+	conflicting := &SystemInfo{Type: "rival", System: &scoreboardSystem{}, Conflicts: []SystemType{SystemTypeSLA}}
+	if err := manager.Add(conflicting); err == nil {
+		t.Fatal("Expected a conflicting system to be refused")
+	}
+	if manager.IsEnabled("rival") || len(manager.GetUpdateOrder()) != len(RegisteredSystemTypes())-1 {
+		t.Errorf("Expected the execution order to be restored, got %v", manager.GetUpdateOrder())
+	}
+}
+
+// TestSystemManager_Capabilities tests that missing and ambiguous providers are reported
+func TestSystemManager_Capabilities(t *testing.T) {
+	manager, _, err := newTestFactoryManager(t, SystemConfig{Disabled: []SystemType{SystemTypeCombo}})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var missing *MissingCapabilityError
+	scoreboard := &scoreboardSystem{}
+	err = manager.Add(scoreboard.info())
+	if !errors.As(err, &missing) || missing.Capability != "combo_tracking" || !slices.Equal(missing.Disabled, []SystemType{SystemTypeCombo}) {
+		t.Fatalf("Expected combo_tracking to be missing with combo disabled, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "combo") {
+		t.Errorf("Expected the error to name the disabled provider, got %v", err)
+	}
+
+	if err := manager.Enable(SystemTypeCombo); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if err := manager.Add(scoreboard.info()); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	var ambiguous *AmbiguousCapabilityError
+	rival := &SystemInfo{Type: "rival", System: &scoreboardSystem{}, Provides: []string{"combo_tracking"}, Optional: true, Phase: PhasePostUpdate}
+	err = manager.Add(rival)
+	if !errors.As(err, &ambiguous) || ambiguous.System != "scoreboard" || !slices.Equal(ambiguous.Providers, []SystemType{SystemTypeCombo, "rival"}) {
+		t.Fatalf("Expected combo_tracking to be ambiguous, got %v", err)
+	}
+	if manager.IsEnabled("rival") || !manager.IsEnabled("scoreboard") {
+		t.Error("Expected the rival to be refused and the scoreboard kept")
+	}
+
+	// A dependency on a system that is not enabled is named
+	orphan := &SystemInfo{Type: "orphan", System: &scoreboardSystem{}, Dependencies: []SystemType{"teleporter"}, Optional: true}
+	if err := manager.Add(orphan); err == nil || !strings.Contains(err.Error(), "teleporter") {
+		t.Errorf("Expected the missing dependency to be named, got %v", err)
+	}
+}
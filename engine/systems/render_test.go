@@ -367,3 +367,36 @@ func TestRenderSystem_Integration(t *testing.T) {
 
 	rs.UpdateWithScreen(0.016, entities, eventDispatcher, screen)
 }
+
+// TestRenderSystem_SetInterpolationAlpha tests that alpha defaults to the current step and is clamped
+func TestRenderSystem_SetInterpolationAlpha(t *testing.T) {
+	rs := NewRenderSystem()
+	if rs.alpha != 1 {
+		t.Errorf("Expected default alpha 1, got %f", rs.alpha)
+	}
+
+	cases := []struct {
+		in, want float64
+	}{
+		{0.25, 0.25},
+		{-1, 0},
+		{3, 1},
+	}
+	for _, c := range cases {
+		rs.SetInterpolationAlpha(c.in)
+		if rs.alpha != c.want {
+			t.Errorf("SetInterpolationAlpha(%f): expected %f, got %f", c.in, c.want, rs.alpha)
+		}
+	}
+
+	// Drawing with an interpolated position should not panic
+	screen := ebiten.NewImage(800, 600)
+	defer screen.Dispose()
+	entity := entities.NewEntity(1)
+	transform := components.NewTransform(10, 10)
+	transform.SetPosition(20, 30)
+	entity.AddComponent(transform)
+	entity.AddComponent(components.NewSprite(5, 5, color.RGBA{255, 255, 255, 255}))
+	rs.SetInterpolationAlpha(0.5)
+	rs.UpdateWithScreen(1.0/60.0, []Entity{entity}, events.NewEventDispatcher(), screen)
+}
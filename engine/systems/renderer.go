@@ -0,0 +1,342 @@
+package systems
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// circleSegments is the number of triangles a circle is drawn with
+const circleSegments = 12
+
+// maxBatchVertices keeps a batch within the range of 16-bit indices
+const maxBatchVertices = math.MaxUint16 - 3*circleSegments
+
+// labelFace is the font labels are drawn in. Its glyphs are copied into the
+// renderer's atlas below a white block that shapes are drawn from.
+var labelFace = basicfont.Face7x13
+
+// atlasGlyphTop is where the glyphs of labelFace start in the atlas
+const atlasGlyphTop = 3
+
+// Camera maps world coordinates to the screen
+type Camera struct {
+	X, Y float64 // World position shown at the top left corner of the screen
+	Zoom float64 // Screen pixels per world unit
+
+	shakeIntensity float64
+	shakeDuration  float64
+	shakeLeft      float64
+	shakeTime      float64
+}
+
+// NewCamera creates a camera showing the world unscaled from the origin
+func NewCamera() *Camera {
+	return &Camera{Zoom: 1}
+}
+
+// Shake makes the view tremble by up to intensity screen pixels, fading out
+// over duration seconds
+func (c *Camera) Shake(intensity, duration float64) {
+	if duration <= 0 {
+		return
+	}
+	c.shakeIntensity = intensity
+	c.shakeDuration = duration
+	c.shakeLeft = duration
+}
+
+// Update advances the shake by deltaTime seconds. It is called once per
+// simulation step, so the shake lasts as long whatever the frame rate.
+func (c *Camera) Update(deltaTime float64) {
+	if c.shakeLeft <= 0 {
+		return
+	}
+	c.shakeTime += deltaTime
+	c.shakeLeft = max(0, c.shakeLeft-deltaTime)
+}
+
+// shakeOffset returns the current shake in screen pixels. It follows a fixed
+// pattern rather than random numbers so replays look the same.
+func (c *Camera) shakeOffset() (float64, float64) {
+	if c.shakeLeft <= 0 {
+		return 0, 0
+	}
+	strength := c.shakeIntensity * c.shakeLeft / c.shakeDuration
+	return strength * math.Sin(c.shakeTime*47), strength * math.Cos(c.shakeTime*31)
+}
+
+// WorldToScreen returns where a world position appears on the screen
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	dx, dy := c.shakeOffset()
+	return (x-c.X)*c.Zoom + dx, (y-c.Y)*c.Zoom + dy
+}
+
+// drawKind is the shape of a draw item
+type drawKind int
+
+const (
+	drawRect drawKind = iota
+	drawCircle
+	drawLine
+	drawLabel
+)
+
+// drawItem is a shape or label submitted to the renderer, in world
+// coordinates until Flush transforms it to the screen
+type drawItem struct {
+	kind  drawKind
+	layer int
+	// Rect: top left and size. Circle: center and radius in w.
+	// Line: start and end in (w, h), stroke width in width.
+	// Label: top left and height of the box the label belongs to.
+	x, y, w, h float64
+	width      float64
+	color      color.RGBA
+	text       string
+}
+
+// Renderer collects what systems draw in a frame and draws it sorted by
+// layer, through the camera, leaving out what is off the screen. Each layer
+// is drawn in one batch of triangles from an atlas holding a white block and
+// the glyphs of the label font: its shapes first, then its labels over them,
+// a quad per glyph.
+type Renderer struct {
+	Camera *Camera
+
+	items    []drawItem
+	labels   []drawItem
+	vertices []ebiten.Vertex
+	indices  []uint16
+	atlas    *ebiten.Image
+	drawn    int
+	culled   int
+}
+
+// RendererUser is implemented by systems that can submit what they draw to
+// a shared renderer instead of drawing directly
+type RendererUser interface {
+	SetRenderer(renderer *Renderer)
+}
+
+// NewRenderer creates a renderer with its own camera
+func NewRenderer() *Renderer {
+	return &Renderer{Camera: NewCamera()}
+}
+
+// Rect submits a filled rectangle
+func (r *Renderer) Rect(layer int, x, y, width, height float64, c color.RGBA) {
+	r.items = append(r.items, drawItem{kind: drawRect, layer: layer, x: x, y: y, w: width, h: height, color: c})
+}
+
+// Circle submits a filled circle
+func (r *Renderer) Circle(layer int, x, y, radius float64, c color.RGBA) {
+	r.items = append(r.items, drawItem{kind: drawCircle, layer: layer, x: x, y: y, w: radius, color: c})
+}
+
+// Line submits a line of the given stroke width
+func (r *Renderer) Line(layer int, x1, y1, x2, y2, width float64, c color.RGBA) {
+	r.items = append(r.items, drawItem{kind: drawLine, layer: layer, x: x1, y: y1, w: x2, h: y2, width: width, color: c})
+}
+
+// Label submits a label for the box at x, y of the given height. It is
+// drawn just above the box, or below it when there is no room above.
+func (r *Renderer) Label(layer int, label string, x, y, height float64, c color.RGBA) {
+	r.items = append(r.items, drawItem{kind: drawLabel, layer: layer, x: x, y: y, h: height, color: c, text: label})
+}
+
+// Pending returns the number of items submitted since the last Flush
+func (r *Renderer) Pending() int {
+	return len(r.items)
+}
+
+// Stats returns how many items the last Flush drew and how many it culled
+func (r *Renderer) Stats() (drawn, culled int) {
+	return r.drawn, r.culled
+}
+
+// prepare transforms the submitted items to a screen of the given size,
+// drops those outside it and sorts the rest by layer, keeping the order of
+// submission within a layer
+func (r *Renderer) prepare(width, height int) []drawItem {
+	camera := r.Camera
+	visible := r.items[:0]
+	r.culled = 0
+	for _, item := range r.items {
+		item.x, item.y = camera.WorldToScreen(item.x, item.y)
+		var minX, minY, maxX, maxY float64
+		switch item.kind {
+		case drawRect:
+			item.w *= camera.Zoom
+			item.h *= camera.Zoom
+			minX, minY, maxX, maxY = item.x, item.y, item.x+item.w, item.y+item.h
+		case drawCircle:
+			item.w *= camera.Zoom
+			minX, minY, maxX, maxY = item.x-item.w, item.y-item.w, item.x+item.w, item.y+item.w
+		case drawLine:
+			item.w, item.h = camera.WorldToScreen(item.w, item.h)
+			item.width *= camera.Zoom
+			minX, maxX = min(item.x, item.w)-item.width, max(item.x, item.w)+item.width
+			minY, maxY = min(item.y, item.h)-item.width, max(item.y, item.h)+item.width
+		case drawLabel:
+			item.h *= camera.Zoom
+			// Room for the text above or below the box
+			minX, minY, maxX, maxY = item.x, item.y-20, item.x+float64(7*len(item.text)), item.y+item.h+20
+		}
+		if maxX < 0 || maxY < 0 || minX > float64(width) || minY > float64(height) {
+			r.culled++
+			continue
+		}
+		visible = append(visible, item)
+	}
+	slices.SortStableFunc(visible, func(a, b drawItem) int {
+		return a.layer - b.layer
+	})
+	return visible
+}
+
+// Flush draws everything submitted since the last Flush onto screen and
+// starts a new frame
+func (r *Renderer) Flush(screen *ebiten.Image) {
+	bounds := screen.Bounds()
+	items := r.prepare(bounds.Dx(), bounds.Dy())
+	r.drawn = len(items)
+
+	for i, item := range items {
+		if item.kind == drawLabel {
+			r.labels = append(r.labels, item)
+		} else {
+			r.appendShape(item)
+		}
+
+		// Draw the layer once all of its items are batched
+		if i == len(items)-1 || items[i+1].layer != item.layer || len(r.vertices) >= maxBatchVertices {
+			for _, label := range r.labels {
+				r.appendLabel(screen, label)
+			}
+			r.drawBatch(screen)
+			r.labels = r.labels[:0]
+		}
+	}
+	r.items = r.items[:0]
+}
+
+// appendLabel adds a quad for every glyph of a label to the batch, drawing
+// the batch first whenever it is full
+func (r *Renderer) appendLabel(screen *ebiten.Image, label drawItem) {
+	textY := int(label.y) - 5
+	if textY < 0 {
+		textY = int(label.y+label.h) + 12
+	}
+	dot := fixed.P(int(label.x), textY)
+	for _, char := range label.text {
+		dst, _, src, advance, ok := labelFace.Glyph(dot, char)
+		if !ok {
+			// Runes the font lacks are drawn as its replacement glyph
+			dst, _, src, advance, _ = labelFace.Glyph(dot, '\ufffd')
+		}
+		dot.X += advance
+		if dst.Empty() {
+			continue
+		}
+		if len(r.vertices)+4 > math.MaxUint16 {
+			r.drawBatch(screen)
+		}
+
+		srcX, srcY := float32(src.X), float32(src.Y+atlasGlyphTop)
+		w, h := float32(dst.Dx()), float32(dst.Dy())
+		base := uint16(len(r.vertices))
+		r.vertices = append(r.vertices,
+			r.glyphVertex(dst.Min.X, dst.Min.Y, srcX, srcY, label.color),
+			r.glyphVertex(dst.Max.X, dst.Min.Y, srcX+w, srcY, label.color),
+			r.glyphVertex(dst.Max.X, dst.Max.Y, srcX+w, srcY+h, label.color),
+			r.glyphVertex(dst.Min.X, dst.Max.Y, srcX, srcY+h, label.color))
+		r.indices = append(r.indices, base, base+1, base+2, base, base+2, base+3)
+	}
+}
+
+// glyphVertex returns a vertex at screen position x, y sampling the atlas
+// at srcX, srcY in color c
+func (r *Renderer) glyphVertex(x, y int, srcX, srcY float32, c color.RGBA) ebiten.Vertex {
+	vertex := r.vertex(float64(x), float64(y), c)
+	vertex.SrcX, vertex.SrcY = srcX, srcY
+	return vertex
+}
+
+// appendShape adds the triangles of a shape in screen coordinates to the batch
+func (r *Renderer) appendShape(item drawItem) {
+	switch item.kind {
+	case drawRect:
+		r.appendQuad(item.x, item.y, item.x+item.w, item.y, item.x+item.w, item.y+item.h, item.x, item.y+item.h, item.color)
+	case drawCircle:
+		base := uint16(len(r.vertices))
+		r.vertices = append(r.vertices, r.vertex(item.x, item.y, item.color))
+		for i := 0; i < circleSegments; i++ {
+			angle := 2 * math.Pi * float64(i) / circleSegments
+			r.vertices = append(r.vertices, r.vertex(item.x+item.w*math.Cos(angle), item.y+item.w*math.Sin(angle), item.color))
+			next := uint16((i+1)%circleSegments) + 1
+			r.indices = append(r.indices, base, base+uint16(i)+1, base+next)
+		}
+	case drawLine:
+		dx, dy := item.w-item.x, item.h-item.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			return
+		}
+		// Offset both ends by half the stroke width across the line
+		nx, ny := -dy/length*item.width/2, dx/length*item.width/2
+		r.appendQuad(item.x+nx, item.y+ny, item.w+nx, item.h+ny, item.w-nx, item.h-ny, item.x-nx, item.y-ny, item.color)
+	}
+}
+
+// appendQuad adds a quadrilateral given by its corners in order
+func (r *Renderer) appendQuad(x0, y0, x1, y1, x2, y2, x3, y3 float64, c color.RGBA) {
+	base := uint16(len(r.vertices))
+	r.vertices = append(r.vertices,
+		r.vertex(x0, y0, c), r.vertex(x1, y1, c), r.vertex(x2, y2, c), r.vertex(x3, y3, c))
+	r.indices = append(r.indices, base, base+1, base+2, base, base+2, base+3)
+}
+
+// vertex returns a vertex sampling the white block of the atlas in color c
+func (r *Renderer) vertex(x, y float64, c color.RGBA) ebiten.Vertex {
+	return ebiten.Vertex{
+		DstX:   float32(x),
+		DstY:   float32(y),
+		SrcX:   1,
+		SrcY:   1,
+		ColorR: float32(c.R) / 255,
+		ColorG: float32(c.G) / 255,
+		ColorB: float32(c.B) / 255,
+		ColorA: float32(c.A) / 255,
+	}
+}
+
+// drawBatch draws the batched triangles in one call and empties the batch
+func (r *Renderer) drawBatch(screen *ebiten.Image) {
+	if len(r.indices) == 0 {
+		return
+	}
+	if r.atlas == nil {
+		r.atlas = ebiten.NewImageFromImage(newAtlas())
+	}
+	screen.DrawTriangles(r.vertices, r.indices, r.atlas, &ebiten.DrawTrianglesOptions{})
+	r.vertices = r.vertices[:0]
+	r.indices = r.indices[:0]
+}
+
+// newAtlas returns the image everything is drawn from: a white block, whose
+// center shapes sample, above the glyphs of labelFace in white
+func newAtlas() *image.RGBA {
+	glyphs := labelFace.Mask.Bounds()
+	atlas := image.NewRGBA(image.Rect(0, 0, max(glyphs.Dx(), 3), atlasGlyphTop+glyphs.Dy()))
+	draw.Draw(atlas, image.Rect(0, 0, 3, atlasGlyphTop), image.White, image.Point{}, draw.Src)
+	draw.DrawMask(atlas, image.Rect(0, atlasGlyphTop, glyphs.Dx(), atlasGlyphTop+glyphs.Dy()),
+		image.White, image.Point{}, labelFace.Mask, glyphs.Min, draw.Over)
+	return atlas
+}
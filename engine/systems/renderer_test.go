@@ -0,0 +1,138 @@
+package systems
+
+import (
+	"image/color"
+	"lbbaspack/engine/components"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestRenderer_Prepare tests that items are sorted by layer in submission order and culled off screen
+func TestRenderer_Prepare(t *testing.T) {
+	renderer := NewRenderer()
+	white := color.RGBA{255, 255, 255, 255}
+	renderer.Rect(components.LayerPackets, 10, 10, 15, 15, white)
+	renderer.Circle(components.LayerEffects, 20, 20, 3, white)
+	renderer.Rect(components.LayerBackends, 0, 550, 120, 40, white)
+	renderer.Label(components.LayerPackets, "HTTP", 10, 10, 15, white)
+	renderer.Rect(components.LayerPackets, 900, 10, 15, 15, white)
+	renderer.Line(components.LayerRoutes, -50, -50, -10, -10, 2, white)
+
+	items := renderer.prepare(800, 600)
+	if renderer.culled != 2 {
+		t.Errorf("Expected 2 items culled, got %d", renderer.culled)
+	}
+	want := []struct {
+		kind  drawKind
+		layer int
+	}{
+		{drawRect, components.LayerBackends},
+		{drawRect, components.LayerPackets},
+		{drawLabel, components.LayerPackets},
+		{drawCircle, components.LayerEffects},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("Expected %d visible items, got %d", len(want), len(items))
+	}
+	for i, item := range items {
+		if item.kind != want[i].kind || item.layer != want[i].layer {
+			t.Errorf("Item %d: expected kind %d on layer %d, got kind %d on layer %d", i, want[i].kind, want[i].layer, item.kind, item.layer)
+		}
+	}
+}
+
+// TestRenderer_LabelGlyphs tests that labels are batched as a quad per glyph
+// sampling the glyph from the atlas
+func TestRenderer_LabelGlyphs(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.appendLabel(nil, drawItem{kind: drawLabel, x: 10, y: 50, h: 15, text: "AB", color: color.RGBA{255, 0, 0, 255}})
+	if len(renderer.vertices) != 8 || len(renderer.indices) != 12 {
+		t.Fatalf("Expected 2 quads, got %d vertices and %d indices", len(renderer.vertices), len(renderer.indices))
+	}
+
+	first, second := renderer.vertices[0], renderer.vertices[4]
+	if first.DstX != 10 || second.DstX != 10+float32(labelFace.Advance) || first.DstY != float32(50-5-labelFace.Ascent) {
+		t.Errorf("Expected glyphs one advance apart above the box, got (%v, %v) and (%v, %v)", first.DstX, first.DstY, second.DstX, second.DstY)
+	}
+	glyphHeight := labelFace.Ascent + labelFace.Descent
+	if want := float32(atlasGlyphTop + ('A'-' ')*glyphHeight); first.SrcY != want {
+		t.Errorf("Expected the A glyph at %v in the atlas, got %v", want, first.SrcY)
+	}
+	if first.ColorR != 1 || first.ColorG != 0 {
+		t.Errorf("Expected glyphs in the label color, got %v, %v", first.ColorR, first.ColorG)
+	}
+
+	atlas := newAtlas()
+	if atlas.RGBAAt(1, 1) != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected shapes to sample white from the atlas, got %v", atlas.RGBAAt(1, 1))
+	}
+}
+
+// TestRenderer_PrepareCamera tests that items are moved and scaled by the camera before culling
+func TestRenderer_PrepareCamera(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.Camera.X, renderer.Camera.Y = 100, 50
+	renderer.Camera.Zoom = 2
+	renderer.Rect(0, 150, 100, 10, 20, color.RGBA{})
+	renderer.Rect(0, 10, 10, 10, 10, color.RGBA{})
+
+	items := renderer.prepare(800, 600)
+	if len(items) != 1 {
+		t.Fatalf("Expected the rectangle left of the camera culled, got %d items", len(items))
+	}
+	if item := items[0]; item.x != 100 || item.y != 100 || item.w != 20 || item.h != 40 {
+		t.Errorf("Expected the rectangle at (100, 100) sized 20x40, got (%v, %v) sized %vx%v", item.x, item.y, item.w, item.h)
+	}
+}
+
+// TestCamera_Shake tests that the shake stays within its intensity and fades out
+func TestCamera_Shake(t *testing.T) {
+	camera := NewCamera()
+	camera.Shake(4, 0.5)
+	shaken := false
+	for range 30 {
+		camera.Update(1.0 / 60.0)
+		x, y := camera.WorldToScreen(100, 100)
+		if math.Abs(x-100) > 4 || math.Abs(y-100) > 4 {
+			t.Fatalf("Expected the shake within 4 pixels, got (%v, %v)", x, y)
+		}
+		shaken = shaken || x != 100 || y != 100
+	}
+	if !shaken {
+		t.Error("Expected the view to shake")
+	}
+
+	camera.Update(0.5)
+	if x, y := camera.WorldToScreen(100, 100); x != 100 || y != 100 {
+		t.Errorf("Expected the shake to be over, got (%v, %v)", x, y)
+	}
+}
+
+// TestRenderer_Users tests that particles and routes are submitted to an attached renderer
+func TestRenderer_Users(t *testing.T) {
+	manager := newGameSystemManager(t)
+	renderer := NewRenderer()
+	manager.AttachRenderer(renderer)
+
+	ps := manager.systems[SystemTypeParticle].System.(*ParticleSystem)
+	ps.CreatePacketCatchEffect(100, 100, color.RGBA{255, 0, 0, 255})
+	rs := manager.systems[SystemTypeRouting].System.(*RoutingSystem)
+	rs.CreateRoute(0, 0, 100, 100, color.RGBA{0, 255, 0, 255})
+	rs.routes[0].Progress = 0.5
+
+	// Nothing is drawn on the screen itself
+	manager.DrawAll((*ebiten.Image)(nil), nil)
+
+	layers := make(map[int]int)
+	for _, item := range renderer.items {
+		layers[item.layer]++
+	}
+	if layers[components.LayerEffects] != 8 {
+		t.Errorf("Expected 8 particles on the effects layer, got %d", layers[components.LayerEffects])
+	}
+	if layers[components.LayerRoutes] != 3 {
+		t.Errorf("Expected a line and two circles on the routes layer, got %d", layers[components.LayerRoutes])
+	}
+}
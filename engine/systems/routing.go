@@ -1,9 +1,10 @@
 package systems
 
 import (
-	"fmt"
 	"image/color"
+	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -11,9 +12,12 @@ import (
 
 const SystemTypeRouting SystemType = "routing"
 
+var routingLog = logging.For(string(SystemTypeRouting))
+
 type RoutingSystem struct {
 	BaseSystem
-	routes []*Route
+	routes   []*Route
+	renderer *Renderer
 }
 
 type Route struct {
@@ -25,6 +29,12 @@ type Route struct {
 	Active         bool
 }
 
+func init() {
+	Register(SystemTypeRouting, func(ctx *SystemContext) SystemInfoer {
+		return NewRoutingSystem()
+	})
+}
+
 func NewRoutingSystem() *RoutingSystem {
 	return &RoutingSystem{
 		BaseSystem: BaseSystem{},
@@ -32,17 +42,25 @@ func NewRoutingSystem() *RoutingSystem {
 	}
 }
 
+// SetRenderer implements RendererUser
+func (rs *RoutingSystem) SetRenderer(renderer *Renderer) {
+	rs.renderer = renderer
+}
+
 // GetSystemInfo returns the system metadata for dependency resolution
 func (rs *RoutingSystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeRouting,
 		System:       rs,
-		Dependencies: []SystemType{SystemTypeCollision},
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"route_visualization", "network_paths"},
-		Requires:     []string{},
+		Requires:     []string{"packet_catching"},
 		Drawable:     true,
 		Optional:     true,
+		Phase:        PhaseRender,
+		Reads:        []string{"Transform", "Sprite", EventResource(events.EventPacketCaught)},
+		Writes:       []string{},
 	}
 }
 
@@ -68,7 +86,7 @@ func (rs *RoutingSystem) Update(deltaTime float64, entities []Entity, eventDispa
 
 func (rs *RoutingSystem) Draw(screen *ebiten.Image, entities []Entity) {
 	// Check if screen is nil to avoid panic
-	if screen == nil {
+	if screen == nil && rs.renderer == nil {
 		return
 	}
 
@@ -79,21 +97,39 @@ func (rs *RoutingSystem) Draw(screen *ebiten.Image, entities []Entity) {
 			currentY := route.StartY + (route.EndY-route.StartY)*route.Progress
 
 			// Draw thicker line from start to current position
-			vector.StrokeLine(screen, float32(route.StartX), float32(route.StartY), float32(currentX), float32(currentY), 2, route.Color, false)
+			rs.line(screen, route.StartX, route.StartY, currentX, currentY, 2, route.Color)
 
 			// Draw packet at current position with larger size
-			vector.DrawFilledCircle(screen, float32(currentX), float32(currentY), 4, route.Color, false)
+			rs.circle(screen, currentX, currentY, 4, route.Color)
 
 			// Draw a small trail effect
 			if route.Progress > 0.1 {
 				trailX := route.StartX + (route.EndX-route.StartX)*(route.Progress-0.1)
 				trailY := route.StartY + (route.EndY-route.StartY)*(route.Progress-0.1)
-				vector.DrawFilledCircle(screen, float32(trailX), float32(trailY), 2, route.Color, false)
+				rs.circle(screen, trailX, trailY, 2, route.Color)
 			}
 		}
 	}
 }
 
+// line submits a line to the renderer, or draws it on screen without one
+func (rs *RoutingSystem) line(screen *ebiten.Image, x1, y1, x2, y2, width float64, c color.RGBA) {
+	if rs.renderer != nil {
+		rs.renderer.Line(components.LayerRoutes, x1, y1, x2, y2, width, c)
+		return
+	}
+	vector.StrokeLine(screen, float32(x1), float32(y1), float32(x2), float32(y2), float32(width), c, false)
+}
+
+// circle submits a circle to the renderer, or draws it on screen without one
+func (rs *RoutingSystem) circle(screen *ebiten.Image, x, y, radius float64, c color.RGBA) {
+	if rs.renderer != nil {
+		rs.renderer.Circle(components.LayerRoutes, x, y, radius, c)
+		return
+	}
+	vector.DrawFilledCircle(screen, float32(x), float32(y), float32(radius), c, false)
+}
+
 func (rs *RoutingSystem) CreateRoute(startX, startY, endX, endY float64, packetColor color.RGBA) {
 	route := &Route{
 		StartX:   startX,
@@ -114,54 +150,50 @@ func (rs *RoutingSystem) GetRoutes() []*Route {
 }
 
 func (rs *RoutingSystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := rs.subscriptionScope(eventDispatcher)
+
 	// Listen for packet caught events to create routing visualization
-	eventDispatcher.Subscribe(events.EventPacketCaught, func(event *events.Event) {
-		fmt.Println("[RoutingSystem] Packet caught event received")
-		if event.Data.Packet != nil {
-			if packetEntity, ok := event.Data.Packet.(Entity); ok {
-				// Get packet position and color
-				transformComp := packetEntity.GetTransform()
-				spriteComp := packetEntity.GetSprite()
-				if transformComp == nil || spriteComp == nil {
-					fmt.Println("[RoutingSystem] Packet entity missing transform or sprite")
-					return
-				}
-
-				transform := transformComp
-				sprite := spriteComp
-
-				// Find target backend using round-robin
-				// We'll need to track which backend to send to next
-				// For now, create routes to different backends based on packet position
-				startX := transform.GetX() + 7.5 // Center of packet
-				startY := transform.GetY() + 7.5
-
-				// Route to different backends based on packet X position
-				backendIndex := int(startX/200) % 4 // 4 backends
-				if backendIndex < 0 {
-					backendIndex = 0
-				}
-				if backendIndex >= 4 {
-					backendIndex = 3
-				}
-
-				// Calculate backend position
-				backendWidth := 120.0
-				backendSpacing := (800.0 - backendWidth*4.0) / 5.0
-				backendX := backendSpacing + float64(backendIndex)*(backendWidth+backendSpacing)
-				backendY := 550.0 // Backend Y position
-
-				endX := backendX + backendWidth/2
-				endY := backendY + 20.0 // Center of backend
-
-				fmt.Printf("[RoutingSystem] Creating route from (%.1f, %.1f) to (%.1f, %.1f) for backend %d\n",
-					startX, startY, endX, endY, backendIndex)
-				rs.CreateRoute(startX, startY, endX, endY, sprite.GetColor())
-			} else {
-				fmt.Println("[RoutingSystem] Packet entity is not of correct type")
+	events.Subscribe(scope, func(caught events.PacketCaught) {
+		if packetEntity, ok := rs.resolve(caught.Packet); ok {
+			// Get packet position and color
+			transformComp := packetEntity.GetTransform()
+			spriteComp := packetEntity.GetSprite()
+			if transformComp == nil || spriteComp == nil {
+				routingLog.Warn("Packet entity missing transform or sprite", "packet", caught.Packet)
+				return
 			}
+
+			transform := transformComp
+			sprite := spriteComp
+
+			// Find target backend using round-robin
+			// We'll need to track which backend to send to next
+			// For now, create routes to different backends based on packet position
+			startX := transform.GetX() + 7.5 // Center of packet
+			startY := transform.GetY() + 7.5
+
+			// Route to different backends based on packet X position
+			backendIndex := int(startX/200) % 4 // 4 backends
+			if backendIndex < 0 {
+				backendIndex = 0
+			}
+			if backendIndex >= 4 {
+				backendIndex = 3
+			}
+
+			// Calculate backend position
+			backendWidth := 120.0
+			backendSpacing := (800.0 - backendWidth*4.0) / 5.0
+			backendX := backendSpacing + float64(backendIndex)*(backendWidth+backendSpacing)
+			backendY := 550.0 // Backend Y position
+
+			endX := backendX + backendWidth/2
+			endY := backendY + 20.0 // Center of backend
+
+			routingLog.Debug("Creating route", "fromX", startX, "fromY", startY, "toX", endX, "toY", endY, "backend", backendIndex)
+			rs.CreateRoute(startX, startY, endX, endY, sprite.GetColor())
 		} else {
-			fmt.Println("[RoutingSystem] Packet data is nil")
+			routingLog.Debug("Packet handle is stale", "packet", caught.Packet)
 		}
 	})
 }
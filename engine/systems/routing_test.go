@@ -398,6 +398,8 @@ func TestRoutingSystem_Initialize(t *testing.T) {
 
 func TestRoutingSystem_EventHandling_PacketCaught(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -412,7 +414,7 @@ func TestRoutingSystem_EventHandling_PacketCaught(t *testing.T) {
 
 	// Publish packet caught event
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: resolver.register(entity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -433,6 +435,8 @@ func TestRoutingSystem_EventHandling_PacketCaught(t *testing.T) {
 
 func TestRoutingSystem_EventHandling_PacketCaught_NilPacket(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -442,7 +446,7 @@ func TestRoutingSystem_EventHandling_PacketCaught_NilPacket(t *testing.T) {
 
 	// Publish packet caught event with nil packet
 	eventData := &events.EventData{
-		Packet: nil,
+		Packet: components.EntityHandle{},
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -453,8 +457,10 @@ func TestRoutingSystem_EventHandling_PacketCaught_NilPacket(t *testing.T) {
 	}
 }
 
-func TestRoutingSystem_EventHandling_PacketCaught_InvalidEntity(t *testing.T) {
+func TestRoutingSystem_EventHandling_PacketCaught_StaleHandle(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -462,9 +468,11 @@ func TestRoutingSystem_EventHandling_PacketCaught_InvalidEntity(t *testing.T) {
 
 	initialRouteCount := len(rs.routes)
 
-	// Publish packet caught event with invalid entity (not Entity interface)
+	// Publish packet caught event with a handle to a removed entity
+	handle := resolver.register(entities.NewEntity(1))
+	resolver.remove(handle)
 	eventData := &events.EventData{
-		Packet: "not an entity",
+		Packet: handle,
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -477,6 +485,8 @@ func TestRoutingSystem_EventHandling_PacketCaught_InvalidEntity(t *testing.T) {
 
 func TestRoutingSystem_EventHandling_PacketCaught_MissingTransform(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -491,7 +501,7 @@ func TestRoutingSystem_EventHandling_PacketCaught_MissingTransform(t *testing.T)
 
 	// Publish packet caught event
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: resolver.register(entity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -504,6 +514,8 @@ func TestRoutingSystem_EventHandling_PacketCaught_MissingTransform(t *testing.T)
 
 func TestRoutingSystem_EventHandling_PacketCaught_MissingSprite(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -518,7 +530,7 @@ func TestRoutingSystem_EventHandling_PacketCaught_MissingSprite(t *testing.T) {
 
 	// Publish packet caught event
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: resolver.register(entity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -531,6 +543,8 @@ func TestRoutingSystem_EventHandling_PacketCaught_MissingSprite(t *testing.T) {
 
 func TestRoutingSystem_EventHandling_MultiplePackets(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -550,8 +564,8 @@ func TestRoutingSystem_EventHandling_MultiplePackets(t *testing.T) {
 	entity2.AddComponent(sprite2)
 
 	// Publish multiple packet caught events
-	event1 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: entity1})
-	event2 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: entity2})
+	event1 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: resolver.register(entity1)})
+	event2 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: resolver.register(entity2)})
 
 	eventDispatcher.Publish(event1)
 	eventDispatcher.Publish(event2)
@@ -571,6 +585,8 @@ func TestRoutingSystem_EventHandling_MultiplePackets(t *testing.T) {
 
 func TestRoutingSystem_Integration(t *testing.T) {
 	rs := NewRoutingSystem()
+	resolver := &stubResolver{}
+	rs.SetResolver(resolver)
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize the system
@@ -585,7 +601,7 @@ func TestRoutingSystem_Integration(t *testing.T) {
 
 	// Publish packet caught event
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: resolver.register(entity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
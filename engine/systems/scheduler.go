@@ -0,0 +1,236 @@
+package systems
+
+import (
+	"lbbaspack/engine/events"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// eventResourcePrefix marks access names that stand for an event type
+const eventResourcePrefix = "event:"
+
+// EventResource returns the access name of an event type for use in
+// SystemInfo.Reads and Writes. Subscribing to an event reads it and
+// publishing it writes it.
+func EventResource(eventType events.EventType) string {
+	return eventResourcePrefix + string(eventType)
+}
+
+// SystemResource returns the access name of a system's internal state. Every
+// system implicitly reads and writes its own; a system that calls into
+// another one declares that system's resource as a write.
+func SystemResource(systemType SystemType) string {
+	return "system:" + string(systemType)
+}
+
+// ExecutionMode selects how UpdateAll runs the systems of a stage
+type ExecutionMode int
+
+const (
+	// ExecutionParallel runs the systems of a stage concurrently on the worker pool
+	ExecutionParallel ExecutionMode = iota
+	// ExecutionDeterministic runs every system on the calling goroutine in stage order
+	ExecutionDeterministic
+)
+
+// access is the set of resources a system touches during an update,
+// including everything its event handlers touch
+type access struct {
+	reads     map[string]bool
+	writes    map[string]bool
+	exclusive bool // Declared no access: must run alone
+}
+
+// newAccess builds the declared access of a system
+func newAccess(info *SystemInfo) *access {
+	a := &access{
+		reads:     map[string]bool{SystemResource(info.Type): true},
+		writes:    map[string]bool{SystemResource(info.Type): true},
+		exclusive: info.Reads == nil && info.Writes == nil,
+	}
+	for _, resource := range info.Reads {
+		a.reads[resource] = true
+	}
+	for _, resource := range info.Writes {
+		a.writes[resource] = true
+	}
+	return a
+}
+
+// merge adds other's access to a and reports whether a grew
+func (a *access) merge(other *access) bool {
+	grew := false
+	for resource := range other.reads {
+		if !a.reads[resource] {
+			a.reads[resource] = true
+			grew = true
+		}
+	}
+	for resource := range other.writes {
+		if !a.writes[resource] {
+			a.writes[resource] = true
+			grew = true
+		}
+	}
+	if other.exclusive && !a.exclusive {
+		a.exclusive = true
+		grew = true
+	}
+	return grew
+}
+
+// conflicts reports whether two systems may not run at the same time
+func (a *access) conflicts(other *access) bool {
+	if a.exclusive || other.exclusive {
+		return true
+	}
+	for resource := range a.writes {
+		if other.reads[resource] || other.writes[resource] {
+			return true
+		}
+	}
+	for resource := range other.writes {
+		if a.reads[resource] {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveAccess computes the access of every system. Event handlers run on
+// the worker of the system that publishes the event, so a publisher also
+// touches everything the subscribers of its events touch.
+func effectiveAccess(systems map[SystemType]*SystemInfo) map[SystemType]*access {
+	effective := make(map[SystemType]*access, len(systems))
+	subscribers := make(map[string][]SystemType)
+	for systemType, info := range systems {
+		effective[systemType] = newAccess(info)
+		for _, resource := range info.Reads {
+			if strings.HasPrefix(resource, eventResourcePrefix) {
+				subscribers[resource] = append(subscribers[resource], systemType)
+			}
+		}
+	}
+
+	// Handlers may publish further events, so repeat until nothing grows
+	for changed := true; changed; {
+		changed = false
+		for systemType, a := range effective {
+			for resource := range a.writes {
+				for _, subscriber := range subscribers[resource] {
+					if subscriber != systemType && a.merge(effective[subscriber]) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return effective
+}
+
+// buildStages groups systems into stages. Each system goes into the earliest
+// stage after all of its dependencies that holds no system it conflicts with.
+func buildStages(systems map[SystemType]*SystemInfo, order []SystemType, dependencies map[SystemType][]SystemType) [][]SystemType {
+	effective := effectiveAccess(systems)
+	stageOf := make(map[SystemType]int, len(order))
+	stages := make([][]SystemType, 0)
+
+	for _, systemType := range order {
+		stage := 0
+		for _, dep := range dependencies[systemType] {
+			if depStage, exists := stageOf[dep]; exists && depStage >= stage {
+				stage = depStage + 1
+			}
+		}
+
+	placement:
+		for ; stage < len(stages); stage++ {
+			for _, other := range stages[stage] {
+				if effective[systemType].conflicts(effective[other]) {
+					continue placement
+				}
+			}
+			break
+		}
+
+		if stage == len(stages) {
+			stages = append(stages, make([]SystemType, 0))
+		}
+		stages[stage] = append(stages[stage], systemType)
+		stageOf[systemType] = stage
+	}
+	return stages
+}
+
+// defaultWorkers is the worker pool size used unless SetWorkers overrides it
+func defaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// runStage runs the systems of one stage, concurrently if allowed
+func (sm *SystemManager) runStage(stage []SystemType, deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+	// Without a command buffer systems change the entity list directly, so
+	// they cannot share a stage safely
+	if sm.mode == ExecutionDeterministic || sm.commands == nil || len(stage) == 1 || sm.workers <= 1 {
+		for _, systemType := range stage {
+			if info, exists := sm.systems[systemType]; exists {
+				sm.updateSystem(info, deltaTime, entities, eventDispatcher)
+			}
+		}
+		return
+	}
+
+	jobs := make(chan *SystemInfo, len(stage))
+	for _, systemType := range stage {
+		if info, exists := sm.systems[systemType]; exists {
+			jobs <- info
+		}
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for range min(sm.workers, len(stage)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for info := range jobs {
+				sm.updateSystem(info, deltaTime, entities, eventDispatcher)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// updateSystem runs one system, timed when a profiler is attached
+func (sm *SystemManager) updateSystem(info *SystemInfo, deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+	if sm.profiler == nil {
+		info.System.Update(deltaTime, entities, eventDispatcher)
+		return
+	}
+	sm.profiler.Measure(info.Type, ProfileUpdate, info.System, func() {
+		info.System.Update(deltaTime, entities, eventDispatcher)
+	})
+}
+
+// SetExecutionMode selects parallel or deterministic execution of stages
+func (sm *SystemManager) SetExecutionMode(mode ExecutionMode) {
+	sm.mode = mode
+}
+
+// GetExecutionMode returns the current execution mode
+func (sm *SystemManager) GetExecutionMode() ExecutionMode {
+	return sm.mode
+}
+
+// SetWorkers sets the number of goroutines that run the systems of a stage.
+// Values below one run stages serially.
+func (sm *SystemManager) SetWorkers(workers int) {
+	sm.workers = workers
+}
+
+// GetStages returns the stages built by BuildExecutionOrder. Systems within
+// a stage have no conflicting access and may run concurrently.
+func (sm *SystemManager) GetStages() [][]SystemType {
+	return sm.stages
+}
@@ -0,0 +1,198 @@
+package systems
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/events"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// newGameSystemManager creates the manager with every game system registered
+func newGameSystemManager(t *testing.T) *SystemManager {
+	t.Helper()
+	factory := NewSystemFactory(func() Entity { return newSystemTestEntity(0) }, events.NewEventDispatcher())
+	manager, err := factory.CreateSystemManager()
+	if err != nil {
+		t.Fatalf("Failed to create system manager: %v", err)
+	}
+	return manager
+}
+
+// TestSystemManager_GameStages tests that the game systems are grouped by their declared access
+func TestSystemManager_GameStages(t *testing.T) {
+	manager := newGameSystemManager(t)
+	stages := manager.GetStages()
+	index := stageIndex(stages)
+
+	if len(index) != len(manager.GetUpdateOrder()) {
+		t.Fatalf("Expected every system in a stage, got %v", stages)
+	}
+
+	// Systems of the same phase without conflicting access share a stage
+	for _, independent := range [][2]SystemType{{SystemTypeParticle, SystemTypeRouting}, {SystemTypeCombo, SystemTypeBackend}} {
+		if index[independent[0]] != index[independent[1]] {
+			t.Errorf("Expected %v to share a stage, got %v", independent, stages)
+		}
+	}
+
+	effective := effectiveAccess(manager.systems)
+	for _, stage := range stages {
+		for i, a := range stage {
+			for _, b := range stage[i+1:] {
+				if effective[a].conflicts(effective[b]) {
+					t.Errorf("Expected conflicting systems %s and %s in different stages", a, b)
+				}
+			}
+		}
+	}
+
+	for systemType, deps := range manager.resolver.dependencyGraph {
+		for _, dep := range deps {
+			if index[dep] >= index[systemType] {
+				t.Errorf("Expected %s to run in a stage before %s, got %v", dep, systemType, stages)
+			}
+		}
+	}
+
+	// Collision publishes the events that drive the bookkeeping systems, so
+	// their handlers make it conflict with them
+	if index[SystemTypeCollision] == index[SystemTypeSLA] || index[SystemTypeCollision] == index[SystemTypeGameState] {
+		t.Errorf("Expected collision to run apart from the systems handling its events, got %v", stages)
+	}
+}
+
+// TestSystemManager_StagesAreStable tests that building twice gives the same stages
+func TestSystemManager_StagesAreStable(t *testing.T) {
+	first := newGameSystemManager(t)
+	second := newGameSystemManager(t)
+
+	if !slices.Equal(first.GetUpdateOrder(), second.GetUpdateOrder()) {
+		t.Errorf("Expected a stable update order, got %v and %v", first.GetUpdateOrder(), second.GetUpdateOrder())
+	}
+	if len(first.GetStages()) != len(second.GetStages()) {
+		t.Fatalf("Expected stable stages, got %v and %v", first.GetStages(), second.GetStages())
+	}
+	for i := range first.GetStages() {
+		if !slices.Equal(first.GetStages()[i], second.GetStages()[i]) {
+			t.Errorf("Expected stable stages, got %v and %v", first.GetStages(), second.GetStages())
+		}
+	}
+}
+
+// TestBuildStages_Access tests conflict detection between declared systems
+func TestBuildStages_Access(t *testing.T) {
+	systems := map[SystemType]*SystemInfo{
+		"reader":    {Type: "reader", Reads: []string{"Transform"}, Writes: []string{}},
+		"writer":    {Type: "writer", Reads: []string{}, Writes: []string{"Transform"}},
+		"other":     {Type: "other", Reads: []string{"Sprite"}, Writes: []string{"Sprite"}},
+		"publisher": {Type: "publisher", Reads: []string{}, Writes: []string{EventResource(events.EventPacketCaught)}},
+		"listener":  {Type: "listener", Reads: []string{EventResource(events.EventPacketCaught)}, Writes: []string{"Combo"}},
+		"combo":     {Type: "combo", Reads: []string{"Combo"}, Writes: []string{}},
+		"legacy":    {Type: "legacy"},
+	}
+	order := []SystemType{"combo", "legacy", "listener", "other", "publisher", "reader", "writer"}
+	index := stageIndex(buildStages(systems, order, map[SystemType][]SystemType{}))
+
+	if index["reader"] == index["writer"] {
+		t.Error("Expected a reader and a writer of the same component in different stages")
+	}
+	if index["other"] != index["reader"] && index["other"] != index["writer"] {
+		t.Error("Expected a system with disjoint access to share a stage")
+	}
+	if index["publisher"] == index["combo"] {
+		t.Error("Expected a publisher to conflict with readers of its subscribers' writes")
+	}
+
+	stages := buildStages(systems, order, map[SystemType][]SystemType{})
+	if len(stages[index["legacy"]]) != 1 {
+		t.Errorf("Expected a system without declared access to run alone, got %v", stages)
+	}
+}
+
+// accessTestSystem counts its updates and spawns an entity each update
+type accessTestSystem struct {
+	BaseSystem
+	mu      *sync.Mutex
+	calls   *[]SystemType
+	self    SystemType
+	updates int
+}
+
+func (ats *accessTestSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+	ats.updates++
+	ats.mu.Lock()
+	*ats.calls = append(*ats.calls, ats.self)
+	ats.mu.Unlock()
+	ats.commands.Spawn(func(entity Entity) {
+		entity.AddComponent(components.NewTransform(float64(ats.updates), 0))
+	})
+}
+
+// lockedCommands makes stubCommands safe to record into from several workers
+type lockedCommands struct {
+	stubCommands
+	mu sync.Mutex
+}
+
+func (lc *lockedCommands) Spawn(build func(entity Entity)) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.stubCommands.Spawn(build)
+}
+
+// runAccessSystems runs four independent systems for a few updates and
+// returns the call order and the number of entities spawned
+func runAccessSystems(t *testing.T, mode ExecutionMode) ([]SystemType, int) {
+	t.Helper()
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	manager.SetExecutionMode(mode)
+	manager.SetWorkers(4)
+
+	var mu sync.Mutex
+	calls := make([]SystemType, 0)
+	for _, name := range []SystemType{"d", "c", "b", "a"} {
+		system := &accessTestSystem{mu: &mu, calls: &calls, self: name}
+		info := &SystemInfo{Type: name, System: system, Reads: []string{}, Writes: []string{string(name)}}
+		if err := manager.RegisterSystem(info); err != nil {
+			t.Fatalf("Failed to register system: %v", err)
+		}
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+	if len(manager.GetStages()) != 1 {
+		t.Fatalf("Expected independent systems in one stage, got %v", manager.GetStages())
+	}
+
+	commands := &lockedCommands{}
+	manager.AttachCommands(commands)
+	for range 5 {
+		manager.UpdateAll(1.0/60.0, commands.entities, events.NewEventDispatcher())
+	}
+	if len(commands.flushes) != 5 {
+		t.Errorf("Expected one flush per stage, got %v", commands.flushes)
+	}
+	return calls, len(commands.entities)
+}
+
+// TestSystemManager_ExecutionModes tests that parallel and deterministic runs do the same work
+func TestSystemManager_ExecutionModes(t *testing.T) {
+	parallelCalls, parallelSpawned := runAccessSystems(t, ExecutionParallel)
+	deterministicCalls, deterministicSpawned := runAccessSystems(t, ExecutionDeterministic)
+
+	if parallelSpawned != 20 || deterministicSpawned != 20 {
+		t.Errorf("Expected 20 spawned entities in both modes, got %d and %d", parallelSpawned, deterministicSpawned)
+	}
+	if len(parallelCalls) != len(deterministicCalls) {
+		t.Errorf("Expected the same number of updates, got %d and %d", len(parallelCalls), len(deterministicCalls))
+	}
+
+	expected := []SystemType{"a", "b", "c", "d"}
+	for i := 0; i < len(deterministicCalls); i += len(expected) {
+		if !slices.Equal(deterministicCalls[i:i+len(expected)], expected) {
+			t.Fatalf("Expected deterministic updates in order %v, got %v", expected, deterministicCalls)
+		}
+	}
+}
@@ -1,12 +1,19 @@
 package systems
 
 import (
-	"fmt"
+	"encoding/json"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"time"
 )
 
 const SystemTypeSLA SystemType = "sla"
 
+var (
+	slaLog      = logging.For(string(SystemTypeSLA))
+	slaFrameLog = logging.Every(slaLog, time.Second)
+)
+
 type SLASystem struct {
 	BaseSystem
 	totalPackets  int
@@ -16,6 +23,15 @@ type SLASystem struct {
 	spawnSys      *SpawnSystem // Reference to SpawnSystem
 }
 
+func init() {
+	Register(SystemTypeSLA, func(ctx *SystemContext) SystemInfoer {
+		// Lost packets speed up the spawner
+		spawnSys, _ := ctx.System(SystemTypeSpawn)
+		spawn, _ := spawnSys.(*SpawnSystem)
+		return NewSLASystem(spawn)
+	})
+}
+
 func NewSLASystem(spawnSys *SpawnSystem) *SLASystem {
 	return &SLASystem{
 		BaseSystem: BaseSystem{
@@ -36,18 +52,21 @@ func (slas *SLASystem) GetSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		Type:         SystemTypeSLA,
 		System:       slas,
-		Dependencies: []SystemType{SystemTypeCollision}, // Depends on collision for packet events
+		Dependencies: []SystemType{},
 		Conflicts:    []SystemType{},
 		Provides:     []string{"sla_monitoring", "performance_tracking"},
-		Requires:     []string{},
+		Requires:     []string{"packet_catching"}, // Depends on collision for packet events
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhasePostUpdate,
+		Reads:        []string{"SLA", EventResource(events.EventPacketCaught), EventResource(events.EventPacketLost)},
+		Writes:       []string{"SLA", EventResource(events.EventSLAUpdated), EventResource(events.EventGameOver), SystemResource(SystemTypeSpawn)},
 	}
 }
 
 func (ss *SLASystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
 	// Update SLA components
-	for _, entity := range ss.FilterEntities(entities) {
+	for _, entity := range ss.RequiredEntities(entities) {
 		slaComp := entity.GetSLA()
 		if slaComp == nil {
 			continue
@@ -68,21 +87,23 @@ func (ss *SLASystem) Update(deltaTime float64, entities []Entity, eventDispatche
 
 			// Check for SLA violations
 			if currentSLA < sla.GetTarget() {
-				fmt.Printf("SLA violation! Current: %.2f%%, Target: %.2f%%\n", currentSLA, sla.GetTarget())
+				slaFrameLog.Warn("SLA violation", "current", currentSLA, "target", sla.GetTarget())
 			}
 		}
 	}
 }
 
 func (ss *SLASystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := ss.subscriptionScope(eventDispatcher)
+
 	// Listen for packet events to update SLA
-	eventDispatcher.Subscribe(events.EventPacketCaught, func(event *events.Event) {
+	events.Subscribe(scope, func(events.PacketCaught) {
 		ss.caughtPackets++
 		ss.totalPackets++
 		ss.updateSLA(eventDispatcher)
 	})
 
-	eventDispatcher.Subscribe(events.EventPacketLost, func(event *events.Event) {
+	events.Subscribe(scope, func(events.PacketLost) {
 		ss.lostPackets++
 		ss.totalPackets++
 		// Increase packet speed by 5% on each lost packet
@@ -98,41 +119,41 @@ func (ss *SLASystem) updateSLA(eventDispatcher *events.EventDispatcher) {
 		currentSLA := float64(ss.caughtPackets) / float64(ss.totalPackets) * 100.0
 		remainingErrors := ss.errorBudget - ss.lostPackets
 
-		// Only print "Packet lost!" message when packets are actually lost
+		// Only log "Packet lost" when packets are actually lost
 		if ss.lostPackets > 0 {
-			fmt.Printf("Packet lost! SLA: %.2f%%, Errors remaining: %d/%d\n", currentSLA, remainingErrors, ss.errorBudget)
+			slaLog.Debug("Packet lost", "sla", currentSLA, "remainingErrors", remainingErrors, "errorBudget", ss.errorBudget)
 		}
 
 		// Publish SLA update event for UI (for both caught and lost packets)
-		eventDispatcher.Publish(events.NewEvent(events.EventSLAUpdated, &events.EventData{
-			Current:   &currentSLA,
-			Caught:    &ss.caughtPackets,
-			Lost:      &ss.lostPackets,
-			Remaining: &remainingErrors,
-			Budget:    &ss.errorBudget,
-		}))
+		events.Publish(eventDispatcher, events.SLAUpdated{
+			Current:   currentSLA,
+			Caught:    ss.caughtPackets,
+			Lost:      ss.lostPackets,
+			Remaining: remainingErrors,
+			Budget:    ss.errorBudget,
+		})
 
 		// Check if error budget has been exceeded
 		if remainingErrors <= 0 {
-			fmt.Printf("ERROR BUDGET EXCEEDED! Game Over!\n")
+			slaLog.Warn("Error budget exceeded", "lost", ss.lostPackets, "errorBudget", ss.errorBudget)
 			// Publish game over event
-			eventDispatcher.Publish(events.NewEvent(events.EventGameOver, &events.EventData{
-				Score: &ss.caughtPackets,
-				Lost:  &ss.lostPackets,
-			}))
+			events.Publish(eventDispatcher, events.GameOver{
+				Caught: ss.caughtPackets,
+				Lost:   ss.lostPackets,
+			})
 		}
 	}
 }
 
 func (ss *SLASystem) SetTargetSLA(target float64) {
 	// This sets the target SLA for all entities with an SLA component
-	fmt.Printf("SLA target set to %.2f%%\n", target)
+	slaLog.Info("SLA target set", "target", target)
 	// Optionally, you could update all SLA components here
 }
 
 func (ss *SLASystem) SetErrorBudget(budget int) {
 	ss.errorBudget = budget
-	fmt.Printf("Error budget set to %d errors\n", budget)
+	slaLog.Info("Error budget set", "errorBudget", budget)
 	// Optionally, you could update all SLA components here
 }
 
@@ -153,10 +174,41 @@ func (ss *SLASystem) GetErrorBudget() int {
 	return ss.errorBudget
 }
 
+// slaState is the serialized form of the SLA system's counters
+type slaState struct {
+	TotalPackets  int `json:"totalPackets"`
+	CaughtPackets int `json:"caughtPackets"`
+	LostPackets   int `json:"lostPackets"`
+	ErrorBudget   int `json:"errorBudget"`
+}
+
+// SaveState implements StatefulSystem
+func (ss *SLASystem) SaveState() ([]byte, error) {
+	return json.Marshal(slaState{
+		TotalPackets:  ss.totalPackets,
+		CaughtPackets: ss.caughtPackets,
+		LostPackets:   ss.lostPackets,
+		ErrorBudget:   ss.errorBudget,
+	})
+}
+
+// LoadState implements StatefulSystem
+func (ss *SLASystem) LoadState(data []byte) error {
+	var state slaState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	ss.totalPackets = state.TotalPackets
+	ss.caughtPackets = state.CaughtPackets
+	ss.lostPackets = state.LostPackets
+	ss.errorBudget = state.ErrorBudget
+	return nil
+}
+
 // Reset method to clear all counters for new game
 func (ss *SLASystem) Reset() {
 	ss.totalPackets = 0
 	ss.caughtPackets = 0
 	ss.lostPackets = 0
-	fmt.Printf("SLA system reset - counters cleared\n")
+	slaLog.Debug("Reset counters")
 }
@@ -261,6 +261,25 @@ func TestSLASystem_Initialize(t *testing.T) {
 	// If we get here, Initialize executed without panicking
 }
 
+// TestSLASystem_InitializeTwice tests that initializing again replaces the event handlers
+func TestSLASystem_InitializeTwice(t *testing.T) {
+	ss := NewSLASystem(nil)
+	eventDispatcher := events.NewEventDispatcher()
+	ss.Initialize(eventDispatcher)
+	ss.Initialize(eventDispatcher)
+
+	events.Publish(eventDispatcher, events.PacketCaught{})
+	if ss.caughtPackets != 1 {
+		t.Errorf("Expected one handler per event, got %d caught packets", ss.caughtPackets)
+	}
+
+	ss.Unsubscribe()
+	events.Publish(eventDispatcher, events.PacketCaught{})
+	if ss.caughtPackets != 1 {
+		t.Errorf("Expected no handlers after Unsubscribe, got %d caught packets", ss.caughtPackets)
+	}
+}
+
 func TestSLASystem_EventHandling_PacketCaught(t *testing.T) {
 	spawnSys := NewSpawnSystem(func() Entity { return entities.NewEntity(1) })
 	ss := NewSLASystem(spawnSys)
@@ -281,7 +300,7 @@ func TestSLASystem_EventHandling_PacketCaught(t *testing.T) {
 
 	// Publish packet caught event
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: HandleOf(entity),
 	}
 	event := events.NewEvent(events.EventPacketCaught, eventData)
 	eventDispatcher.Publish(event)
@@ -315,7 +334,7 @@ func TestSLASystem_EventHandling_PacketLost(t *testing.T) {
 
 	// Publish packet lost event
 	eventData := &events.EventData{
-		Packet: entity,
+		Packet: HandleOf(entity),
 	}
 	event := events.NewEvent(events.EventPacketLost, eventData)
 	eventDispatcher.Publish(event)
@@ -351,8 +370,8 @@ func TestSLASystem_EventHandling_MultipleEvents(t *testing.T) {
 	entity2.AddComponent(sprite2)
 
 	// Publish multiple events
-	event1 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: entity1})
-	event2 := events.NewEvent(events.EventPacketLost, &events.EventData{Packet: entity2})
+	event1 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: HandleOf(entity1)})
+	event2 := events.NewEvent(events.EventPacketLost, &events.EventData{Packet: HandleOf(entity2)})
 
 	eventDispatcher.Publish(event1)
 	eventDispatcher.Publish(event2)
@@ -525,8 +544,8 @@ func TestSLASystem_Integration(t *testing.T) {
 	packet2.AddComponent(sprite2)
 
 	// Publish events
-	event1 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: packet1})
-	event2 := events.NewEvent(events.EventPacketLost, &events.EventData{Packet: packet2})
+	event1 := events.NewEvent(events.EventPacketCaught, &events.EventData{Packet: HandleOf(packet1)})
+	event2 := events.NewEvent(events.EventPacketLost, &events.EventData{Packet: HandleOf(packet2)})
 
 	eventDispatcher.Publish(event1)
 	eventDispatcher.Publish(event2)
@@ -906,3 +925,28 @@ func TestSLASystem_GameRestart(t *testing.T) {
 		}
 	})
 }
+
+// TestSLASystem_SaveLoadState tests that packet counters survive a roundtrip
+func TestSLASystem_SaveLoadState(t *testing.T) {
+	ss := NewSLASystem(nil)
+	ss.totalPackets = 12
+	ss.caughtPackets = 9
+	ss.lostPackets = 3
+	ss.errorBudget = 7
+
+	data, err := ss.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewSLASystem(nil)
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if restored.GetTotalPackets() != 12 || restored.GetCaughtPackets() != 9 ||
+		restored.GetLostPackets() != 3 || restored.GetErrorBudget() != 7 {
+		t.Errorf("Expected counters 12/9/3/7, got %d/%d/%d/%d", restored.GetTotalPackets(),
+			restored.GetCaughtPackets(), restored.GetLostPackets(), restored.GetErrorBudget())
+	}
+}
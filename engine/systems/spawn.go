@@ -1,15 +1,25 @@
 package systems
 
 import (
-	"fmt"
-	"image/color"
+	"context"
+	"encoding/json"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"lbbaspack/engine/prefabs"
+	"lbbaspack/engine/random"
+	"log/slog"
 	"math/rand"
+	"time"
 )
 
 const SystemTypeSpawn SystemType = "spawn"
 
+var (
+	spawnLog      = logging.For(string(SystemTypeSpawn))
+	spawnFrameLog = logging.Every(spawnLog, time.Second)
+)
+
 // SpawnSystem manages the spawning of packets and power-ups in the game.
 // It handles spawn timing, level progression, DDoS attacks, and entity creation.
 type SpawnSystem struct {
@@ -21,6 +31,7 @@ type SpawnSystem struct {
 	spawnCallback    func() Entity
 	packetSpeed      float64
 	level            int
+	prefabs          *prefabs.Library
 
 	// DDoS attack state
 	isDDoSActive   bool
@@ -28,12 +39,23 @@ type SpawnSystem struct {
 	ddosDuration   float64
 	ddosMultiplier float64
 	ddosCooldown   float64
+
+	// Random streams for packet placement, DDoS timing and power-ups
+	spawnRand   *rand.Rand
+	ddosRand    *rand.Rand
+	powerUpRand *rand.Rand
+}
+
+func init() {
+	Register(SystemTypeSpawn, func(ctx *SystemContext) SystemInfoer {
+		return NewSpawnSystem(ctx.EntityFactory)
+	})
 }
 
 // NewSpawnSystem creates a new spawn system with default configuration.
 // The spawnCallback function is used to create new entities when spawning is needed.
 func NewSpawnSystem(spawnCallback func() Entity) *SpawnSystem {
-	return &SpawnSystem{
+	ss := &SpawnSystem{
 		BaseSystem:       BaseSystem{RequiredComponents: []string{}},
 		lastPacketSpawn:  0,
 		packetSpawnRate:  1.0,
@@ -47,7 +69,22 @@ func NewSpawnSystem(spawnCallback func() Entity) *SpawnSystem {
 		ddosDuration:     5.0,
 		ddosMultiplier:   10.0,
 		ddosCooldown:     10.0,
+		prefabs:          prefabs.Default(),
 	}
+	ss.SetRNG(random.New(random.NewSeed()))
+	return ss
+}
+
+// SetRNG implements RandomUser
+func (ss *SpawnSystem) SetRNG(rng *random.RNG) {
+	ss.spawnRand = rng.Stream(random.StreamSpawn)
+	ss.ddosRand = rng.Stream(random.StreamDDoS)
+	ss.powerUpRand = rng.Stream(random.StreamPowerUps)
+}
+
+// SetPrefabs implements PrefabUser
+func (ss *SpawnSystem) SetPrefabs(library *prefabs.Library) {
+	ss.prefabs = library
 }
 
 // GetSystemInfo returns the system metadata for dependency resolution
@@ -61,13 +98,16 @@ func (ss *SpawnSystem) GetSystemInfo() *SystemInfo {
 		Requires:     []string{},
 		Drawable:     false,
 		Optional:     false,
+		Phase:        PhaseUpdate,
+		Reads:        []string{EventResource(events.EventLevelUp)},
+		Writes:       []string{EventResource(events.EventDDoSStart), EventResource(events.EventDDoSEnd)},
 	}
 }
 
 // IncreasePacketSpeed increases the packet speed by a percentage.
 func (ss *SpawnSystem) IncreasePacketSpeed(percent float64) {
 	ss.packetSpeed *= (1.0 + percent/100.0)
-	fmt.Printf("[SpawnSystem] Packet speed increased to %.2f\n", ss.packetSpeed)
+	spawnLog.Info("Packet speed increased", "speed", ss.packetSpeed)
 }
 
 // IncreaseLevel increases the level and adjusts spawn rate for higher density.
@@ -76,22 +116,21 @@ func (ss *SpawnSystem) IncreaseLevel(newLevel int) {
 	ss.level = newLevel
 	// Formula: base rate / (1 + level * 0.2) - increases density by ~20% per level
 	ss.packetSpawnRate = 1.0 / (1.0 + float64(ss.level-1)*0.2)
-	fmt.Printf("[SpawnSystem] Level increased to %d, spawn rate: %.3f seconds\n", ss.level, ss.packetSpawnRate)
+	spawnLog.Info("Level increased", "level", ss.level, "spawnRate", ss.packetSpawnRate)
 }
 
 // Initialize sets up event listeners for the spawn system.
 func (ss *SpawnSystem) Initialize(eventDispatcher *events.EventDispatcher) {
-	eventDispatcher.Subscribe(events.EventLevelUp, func(event *events.Event) {
-		if event.Data.Level != nil {
-			ss.IncreaseLevel(*event.Data.Level)
-		}
+	scope := ss.subscriptionScope(eventDispatcher)
+	events.Subscribe(scope, func(levelUp events.LevelUp) {
+		ss.IncreaseLevel(levelUp.Level)
 	})
 }
 
 // Update processes the spawn system for the given delta time.
 // This includes DDoS attack management, timer updates, and entity spawning.
 func (ss *SpawnSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
-	fmt.Printf("[SpawnSystem] Update called with deltaTime: %.3f, entities count: %d\n", deltaTime, len(entities))
+	spawnFrameLog.Debug("Update", "deltaTime", deltaTime, "entities", len(entities))
 
 	ss.updateDDoSAttack(deltaTime, eventDispatcher)
 	ss.updateTimers(deltaTime)
@@ -119,13 +158,13 @@ func (ss *SpawnSystem) endDDoSAttack(eventDispatcher *events.EventDispatcher) {
 	ss.isDDoSActive = false
 	ss.ddosTimer = 0
 	ss.packetSpawnRate = 1.0 / (1.0 + float64(ss.level-1)*0.2)
-	fmt.Println("[SpawnSystem] DDoS attack ended. Spawn rate restored.")
-	eventDispatcher.Publish(events.NewEvent(events.EventDDoSEnd, nil))
+	spawnLog.Info("DDoS attack ended, spawn rate restored", "spawnRate", ss.packetSpawnRate)
+	events.Publish(eventDispatcher, events.DDoSEnd{})
 }
 
 // tryStartDDoSAttack attempts to start a DDoS attack with a random chance.
 func (ss *SpawnSystem) tryStartDDoSAttack(eventDispatcher *events.EventDispatcher) {
-	if rand.Float64() < 0.01 {
+	if ss.ddosRand.Float64() < 0.01 {
 		ss.startDDoSAttack(eventDispatcher)
 	}
 }
@@ -134,50 +173,55 @@ func (ss *SpawnSystem) tryStartDDoSAttack(eventDispatcher *events.EventDispatche
 func (ss *SpawnSystem) startDDoSAttack(eventDispatcher *events.EventDispatcher) {
 	ss.isDDoSActive = true
 	ss.ddosTimer = 0
-	ss.ddosCooldown = 20.0 + rand.Float64()*20.0
+	ss.ddosCooldown = 20.0 + ss.ddosRand.Float64()*20.0
 	ss.packetSpawnRate = (1.0 / (1.0 + float64(ss.level-1)*0.2)) / ss.ddosMultiplier
-	fmt.Println("[SpawnSystem] DDoS attack started! Spawn rate massively increased.")
-	eventDispatcher.Publish(events.NewEvent(events.EventDDoSStart, &events.EventData{
-		Duration: &ss.ddosDuration,
-		Level:    &ss.level,
-	}))
+	spawnLog.Info("DDoS attack started", "duration", ss.ddosDuration, "spawnRate", ss.packetSpawnRate)
+	events.Publish(eventDispatcher, events.DDoSStart{
+		Duration: ss.ddosDuration,
+		Level:    ss.level,
+	})
 }
 
 // updateTimers increments the spawn timers by the given delta time.
 func (ss *SpawnSystem) updateTimers(deltaTime float64) {
 	ss.lastPacketSpawn += deltaTime
 	ss.lastPowerUpSpawn += deltaTime
-	fmt.Printf("[SpawnSystem] lastPacketSpawn: %.3f, packetSpawnRate: %.3f\n", ss.lastPacketSpawn, ss.packetSpawnRate)
+	spawnFrameLog.Debug("Timers", "lastPacketSpawn", ss.lastPacketSpawn, "packetSpawnRate", ss.packetSpawnRate)
 }
 
 // trySpawnPacket attempts to spawn a packet if enough time has passed.
 func (ss *SpawnSystem) trySpawnPacket(eventDispatcher *events.EventDispatcher) {
 	if ss.lastPacketSpawn >= ss.packetSpawnRate {
-		fmt.Printf("[SpawnSystem] SPAWNING PACKET! lastPacketSpawn: %.3f >= %.3f\n", ss.lastPacketSpawn, ss.packetSpawnRate)
+		spawnLog.Debug("Spawning packet", "lastPacketSpawn", ss.lastPacketSpawn, "packetSpawnRate", ss.packetSpawnRate)
 		ss.lastPacketSpawn = 0
 		ss.spawnPacket()
-	} else {
-		fmt.Printf("[SpawnSystem] Not spawning yet, need %.3f more time\n", ss.packetSpawnRate-ss.lastPacketSpawn)
 	}
 }
 
 // spawnPacket creates a new packet entity with all required components.
+// With a command buffer attached the packet is created at the next sync point.
 func (ss *SpawnSystem) spawnPacket() {
+	if ss.commands != nil {
+		ss.commands.Spawn(ss.buildPacket)
+		return
+	}
 	if ss.spawnCallback == nil {
-		fmt.Println("[SpawnSystem] Spawn callback is nil!")
+		spawnLog.Warn("Spawn callback is nil, not spawning packet")
 		return
 	}
 
-	fmt.Println("[SpawnSystem] Spawn callback is not nil, spawning packet...")
-	packet := ss.spawnCallback()
+	ss.buildPacket(ss.spawnCallback())
+}
 
+// buildPacket turns a freshly created entity into a packet.
+func (ss *SpawnSystem) buildPacket(packet Entity) {
 	// Entity interface for adding components and getting component names
 	entity, ok := packet.(interface {
 		AddComponent(components.Component)
 		GetComponentNames() []string
 	})
 	if !ok {
-		fmt.Println("[SpawnSystem] Failed to cast spawned entity to interface")
+		spawnLog.Error("Failed to cast spawned entity to interface")
 		return
 	}
 
@@ -185,23 +229,21 @@ func (ss *SpawnSystem) spawnPacket() {
 	ss.logPacketSpawn(packet, entity)
 }
 
-// addPacketComponents adds all required components to a packet entity.
+// addPacketComponents builds a packet of a random type from its prefab.
 func (ss *SpawnSystem) addPacketComponents(entity interface {
 	AddComponent(components.Component)
 }) {
-	x := float64(rand.Intn(800 - 15))
-	y := -15.0
-	fmt.Printf("[SpawnSystem] Creating packet at position (%.1f, %.1f)\n", x, y)
-
-	entity.AddComponent(components.NewTransform(x, y))
-	entity.AddComponent(components.NewSprite(15, 15, components.RandomPacketColor()))
-	entity.AddComponent(components.NewCollider(15, 15, "packet"))
-
-	physics := components.NewPhysics()
-	physics.SetVelocity(0, ss.packetSpeed)
-	entity.AddComponent(physics)
-
-	entity.AddComponent(components.NewPacketType(components.RandomPacketName(), 10))
+	x := float64(ss.spawnRand.Intn(800 - 15))
+	name := prefabs.Name("packet", components.RandomPacketNameFrom(ss.spawnRand))
+	spawnLog.Debug("Creating packet", "prefab", name, "x", x)
+
+	err := ss.prefabs.Build(entity, name, prefabs.Overrides{
+		"Transform": {"X": x},
+		"Physics":   {"VelocityY": ss.packetSpeed},
+	}, ss.pool)
+	if err != nil {
+		spawnLog.Error("Failed to build packet", "prefab", name, "error", err)
+	}
 }
 
 // logPacketSpawn logs information about the spawned packet for debugging.
@@ -213,14 +255,17 @@ func (ss *SpawnSystem) logPacketSpawn(packet Entity, entity interface{ GetCompon
 // logEntityStatus logs the active status of an entity.
 func (ss *SpawnSystem) logEntityStatus(packet Entity) {
 	if activeEntity, ok := packet.(interface{ IsActive() bool }); ok {
-		fmt.Printf("[SpawnSystem] Spawned entity is active: %v\n", activeEntity.IsActive())
+		spawnLog.Debug("Spawned entity", "active", activeEntity.IsActive())
 	}
 }
 
 // logComponentInfo logs component information for debugging.
 func (ss *SpawnSystem) logComponentInfo(entity interface{ GetComponentNames() []string }, entityType string) {
+	if !spawnLog.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
 	componentNames := entity.GetComponentNames()
-	fmt.Printf("[SpawnSystem] Spawned %s with components: %v\n", entityType, componentNames)
+	spawnLog.Debug("Spawned entity", "type", entityType, "components", componentNames)
 
 	hasTransform := false
 	hasSprite := false
@@ -232,7 +277,7 @@ func (ss *SpawnSystem) logComponentInfo(entity interface{ GetComponentNames() []
 			hasSprite = true
 		}
 	}
-	fmt.Printf("[SpawnSystem] %s has Transform: %v, Sprite: %v\n", entityType, hasTransform, hasSprite)
+	spawnLog.Debug("Spawned entity", "type", entityType, "transform", hasTransform, "sprite", hasSprite)
 }
 
 // trySpawnPowerUp attempts to spawn a power-up if enough time has passed.
@@ -244,22 +289,29 @@ func (ss *SpawnSystem) trySpawnPowerUp(eventDispatcher *events.EventDispatcher)
 }
 
 // spawnPowerUp creates a new power-up entity with all required components.
+// With a command buffer attached the power-up is created at the next sync point.
 func (ss *SpawnSystem) spawnPowerUp() {
+	if ss.commands != nil {
+		ss.commands.Spawn(ss.buildPowerUp)
+		return
+	}
 	if ss.spawnCallback == nil {
-		fmt.Println("[SpawnSystem] Spawn callback is nil!")
+		spawnLog.Warn("Spawn callback is nil, not spawning power-up")
 		return
 	}
 
-	fmt.Println("[SpawnSystem] Spawn callback is not nil, spawning powerup...")
-	powerup := ss.spawnCallback()
+	ss.buildPowerUp(ss.spawnCallback())
+}
 
+// buildPowerUp turns a freshly created entity into a power-up.
+func (ss *SpawnSystem) buildPowerUp(powerup Entity) {
 	// Entity interface for adding components and getting component names
 	entity, ok := powerup.(interface {
 		AddComponent(components.Component)
 		GetComponentNames() []string
 	})
 	if !ok {
-		fmt.Println("[SpawnSystem] Failed to cast spawned powerup to interface")
+		spawnLog.Error("Failed to cast spawned power-up to interface")
 		return
 	}
 
@@ -267,20 +319,17 @@ func (ss *SpawnSystem) spawnPowerUp() {
 	ss.logPowerUpSpawn(entity)
 }
 
-// addPowerUpComponents adds all required components to a power-up entity.
+// addPowerUpComponents builds a power-up of a random type from its prefab.
 func (ss *SpawnSystem) addPowerUpComponents(entity interface {
 	AddComponent(components.Component)
 }) {
-	name, col := randomPowerUpNameAndColor()
-	entity.AddComponent(components.NewTransform(float64(rand.Intn(800-15)), -15))
-	entity.AddComponent(components.NewSprite(15, 15, col))
-	entity.AddComponent(components.NewCollider(15, 15, "powerup"))
+	name := prefabs.Name("powerup", randomPowerUpName(ss.powerUpRand))
+	x := float64(ss.powerUpRand.Intn(800 - 15))
 
-	physics := components.NewPhysics()
-	physics.SetVelocity(0, 50)
-	entity.AddComponent(physics)
-
-	entity.AddComponent(components.NewPowerUpType(name, 10.0))
+	err := ss.prefabs.Build(entity, name, prefabs.Overrides{"Transform": {"X": x}}, ss.pool)
+	if err != nil {
+		spawnLog.Error("Failed to build power-up", "prefab", name, "error", err)
+	}
 }
 
 // logPowerUpSpawn logs information about the spawned power-up for debugging.
@@ -288,19 +337,63 @@ func (ss *SpawnSystem) logPowerUpSpawn(entity interface{ GetComponentNames() []s
 	ss.logComponentInfo(entity, "powerup")
 }
 
-// randomPowerUpNameAndColor returns a random power-up name and color.
-func randomPowerUpNameAndColor() (string, color.RGBA) {
-	types := []struct {
-		name string
-		col  color.RGBA
-	}{
-		{"Speed Boost", color.RGBA{255, 255, 0, 255}},
-		{"Wide Catch", color.RGBA{0, 255, 255, 255}},
-		{"Multi-Catch", color.RGBA{255, 0, 255, 255}},
-		{"Time Slow", color.RGBA{0, 0, 255, 255}},
-		{"Shield", color.RGBA{0, 255, 0, 255}},
-		{"Auto-Balancer", color.RGBA{255, 165, 0, 255}},
+// spawnState is the serialized form of the spawn system's internal state
+type spawnState struct {
+	LastPacketSpawn  float64 `json:"lastPacketSpawn"`
+	PacketSpawnRate  float64 `json:"packetSpawnRate"`
+	LastPowerUpSpawn float64 `json:"lastPowerUpSpawn"`
+	PowerUpSpawnRate float64 `json:"powerUpSpawnRate"`
+	PacketSpeed      float64 `json:"packetSpeed"`
+	Level            int     `json:"level"`
+	IsDDoSActive     bool    `json:"isDDoSActive"`
+	DDoSTimer        float64 `json:"ddosTimer"`
+	DDoSDuration     float64 `json:"ddosDuration"`
+	DDoSMultiplier   float64 `json:"ddosMultiplier"`
+	DDoSCooldown     float64 `json:"ddosCooldown"`
+}
+
+// SaveState implements StatefulSystem
+func (ss *SpawnSystem) SaveState() ([]byte, error) {
+	return json.Marshal(spawnState{
+		LastPacketSpawn:  ss.lastPacketSpawn,
+		PacketSpawnRate:  ss.packetSpawnRate,
+		LastPowerUpSpawn: ss.lastPowerUpSpawn,
+		PowerUpSpawnRate: ss.powerUpSpawnRate,
+		PacketSpeed:      ss.packetSpeed,
+		Level:            ss.level,
+		IsDDoSActive:     ss.isDDoSActive,
+		DDoSTimer:        ss.ddosTimer,
+		DDoSDuration:     ss.ddosDuration,
+		DDoSMultiplier:   ss.ddosMultiplier,
+		DDoSCooldown:     ss.ddosCooldown,
+	})
+}
+
+// LoadState implements StatefulSystem
+func (ss *SpawnSystem) LoadState(data []byte) error {
+	var state spawnState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
 	}
-	idx := rand.Intn(len(types))
-	return types[idx].name, types[idx].col
+	ss.lastPacketSpawn = state.LastPacketSpawn
+	ss.packetSpawnRate = state.PacketSpawnRate
+	ss.lastPowerUpSpawn = state.LastPowerUpSpawn
+	ss.powerUpSpawnRate = state.PowerUpSpawnRate
+	ss.packetSpeed = state.PacketSpeed
+	ss.level = state.Level
+	ss.isDDoSActive = state.IsDDoSActive
+	ss.ddosTimer = state.DDoSTimer
+	ss.ddosDuration = state.DDoSDuration
+	ss.ddosMultiplier = state.DDoSMultiplier
+	ss.ddosCooldown = state.DDoSCooldown
+	return nil
+}
+
+// powerUpNames lists the power-ups the spawn system chooses from. Each has a
+// "powerup.<name>" prefab defining its look.
+var powerUpNames = []string{"Speed Boost", "Wide Catch", "Multi-Catch", "Time Slow", "Shield", "Auto-Balancer"}
+
+// randomPowerUpName returns a random power-up name drawn from r.
+func randomPowerUpName(r *rand.Rand) string {
+	return powerUpNames[r.Intn(len(powerUpNames))]
 }
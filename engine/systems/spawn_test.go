@@ -1,10 +1,14 @@
 package systems
 
 import (
+	"fmt"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/entities"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/prefabs"
+	"lbbaspack/engine/random"
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -587,49 +591,17 @@ func TestSpawnSystem_Update_LargeDeltaTime(t *testing.T) {
 	}
 }
 
-func TestRandomPowerUpNameAndColor(t *testing.T) {
-	// Test that the function returns valid powerup types
-	validNames := []string{"Speed Boost", "Wide Catch", "Multi-Catch", "Time Slow", "Shield", "Auto-Balancer"}
-	validColors := []struct {
-		r, g, b, a uint8
-	}{
-		{255, 255, 0, 255}, // Yellow
-		{0, 255, 255, 255}, // Cyan
-		{255, 0, 255, 255}, // Magenta
-		{0, 0, 255, 255},   // Blue
-		{0, 255, 0, 255},   // Green
-		{255, 165, 0, 255}, // Orange
-	}
+func TestRandomPowerUpName(t *testing.T) {
+	// Test that the function returns power-ups that have prefabs
+	library := prefabs.Default()
 
 	// Test multiple calls to ensure randomness
+	r := rand.New(rand.NewSource(1))
 	for i := 0; i < 10; i++ {
-		name, color := randomPowerUpNameAndColor()
-
-		// Check name is valid
-		nameValid := false
-		for _, validName := range validNames {
-			if name == validName {
-				nameValid = true
-				break
-			}
-		}
-		if !nameValid {
+		name := randomPowerUpName(r)
+		if !library.Has(prefabs.Name("powerup", name)) {
 			t.Errorf("Invalid powerup name returned: %s", name)
 		}
-
-		// Check color is valid
-		colorValid := false
-		for _, validColor := range validColors {
-			if color.R == validColor.r && color.G == validColor.g &&
-				color.B == validColor.b && color.A == validColor.a {
-				colorValid = true
-				break
-			}
-		}
-		if !colorValid {
-			t.Errorf("Invalid powerup color returned: R=%d, G=%d, B=%d, A=%d",
-				color.R, color.G, color.B, color.A)
-		}
 	}
 }
 
@@ -644,6 +616,7 @@ func TestSpawnSystem_Integration(t *testing.T) {
 	}
 
 	ss := NewSpawnSystem(spawnCallback)
+	ss.SetRNG(random.New(1)) // seed without a DDoS attack in this run
 	eventDispatcher := events.NewEventDispatcher()
 
 	// Initialize system
@@ -678,3 +651,156 @@ func TestSpawnSystem_Integration(t *testing.T) {
 		t.Errorf("Expected spawn rate to be %f for level 2, got %f", expectedSpawnRate, ss.packetSpawnRate)
 	}
 }
+
+// TestSpawnSystem_SaveLoadState tests that level, speed and DDoS timers survive a roundtrip
+func TestSpawnSystem_SaveLoadState(t *testing.T) {
+	ss := NewSpawnSystem(nil)
+	ss.level = 4
+	ss.packetSpeed = 180
+	ss.packetSpawnRate = 0.8
+	ss.lastPacketSpawn = 0.3
+	ss.isDDoSActive = true
+	ss.ddosTimer = 2.5
+	ss.ddosCooldown = 7
+
+	data, err := ss.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewSpawnSystem(nil)
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if restored.level != 4 || restored.packetSpeed != 180 || restored.packetSpawnRate != 0.8 || restored.lastPacketSpawn != 0.3 {
+		t.Errorf("Expected spawn progression to be restored, got level=%d speed=%f rate=%f last=%f",
+			restored.level, restored.packetSpeed, restored.packetSpawnRate, restored.lastPacketSpawn)
+	}
+	if !restored.isDDoSActive || restored.ddosTimer != 2.5 || restored.ddosCooldown != 7 {
+		t.Errorf("Expected DDoS state to be restored, got active=%v timer=%f cooldown=%f",
+			restored.isDDoSActive, restored.ddosTimer, restored.ddosCooldown)
+	}
+
+	if err := restored.LoadState([]byte("not json")); err == nil {
+		t.Error("Expected LoadState to reject invalid data")
+	}
+}
+
+// TestSpawnSystem_SeededRunsAreReproducible tests that the same seed spawns the same packets
+func TestSpawnSystem_SeededRunsAreReproducible(t *testing.T) {
+	run := func(seed int64) []string {
+		spawned := make([]Entity, 0)
+		ss := NewSpawnSystem(func() Entity {
+			entity := newSpawnTestEntity(uint64(len(spawned) + 1))
+			spawned = append(spawned, entity)
+			return entity
+		})
+		ss.SetRNG(random.New(seed))
+		eventDispatcher := events.NewEventDispatcher()
+		for range 30 {
+			ss.Update(1.1, []Entity{}, eventDispatcher)
+		}
+
+		result := make([]string, 0, len(spawned))
+		for _, entity := range spawned {
+			transform := entity.GetTransform()
+			name := ""
+			if packet := entity.GetPacketType(); packet != nil {
+				name = packet.GetName()
+			} else if powerUp := entity.GetPowerUpType(); powerUp != nil {
+				name = powerUp.GetName()
+			}
+			result = append(result, fmt.Sprintf("%s@%.0f", name, transform.GetX()))
+		}
+		return result
+	}
+
+	first, second := run(99), run(99)
+	if len(first) == 0 || fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Errorf("Expected identical spawns for the same seed, got %v and %v", first, second)
+	}
+	if fmt.Sprint(first) == fmt.Sprint(run(100)) {
+		t.Error("Expected a different seed to spawn differently")
+	}
+}
+
+// TestSpawnSystem_WithCommandBuffer tests that spawns are deferred to the command buffer
+func TestSpawnSystem_WithCommandBuffer(t *testing.T) {
+	callbackCalls := 0
+	ss := NewSpawnSystem(func() Entity {
+		callbackCalls++
+		return newSpawnTestEntity(uint64(callbackCalls))
+	})
+	commands := &stubCommands{}
+	ss.SetCommands(commands)
+
+	ss.Update(10.1, []Entity{}, events.NewEventDispatcher())
+
+	if callbackCalls != 0 {
+		t.Errorf("Expected the spawn callback to be bypassed, got %d calls", callbackCalls)
+	}
+	if commands.Len() != 2 {
+		t.Fatalf("Expected a packet and a power-up to be recorded, got %d", commands.Len())
+	}
+
+	spawned := commands.Flush()
+	if len(spawned) != 2 {
+		t.Fatalf("Expected 2 entities after flush, got %d", len(spawned))
+	}
+	if !spawned[0].HasComponent("PacketType") || !spawned[0].HasComponent("Physics") {
+		t.Error("Expected first spawned entity to be a complete packet")
+	}
+	if !spawned[1].HasComponent("PowerUpType") {
+		t.Error("Expected second spawned entity to be a power-up")
+	}
+}
+
+// stubComponentPool hands out components it was primed with, or reset new
+// ones, and records acquisitions
+type stubComponentPool struct {
+	free     map[string]components.Component
+	acquired []string
+}
+
+func (p *stubComponentPool) AcquireComponent(componentType string) (components.Component, bool) {
+	p.acquired = append(p.acquired, componentType)
+	if component, exists := p.free[componentType]; exists {
+		delete(p.free, componentType)
+		return component, true
+	}
+	component, ok := components.New(componentType)
+	if resettable, isResettable := component.(components.Resettable); isResettable {
+		resettable.Reset()
+	}
+	return component, ok
+}
+
+func TestSpawnSystem_WithComponentPool(t *testing.T) {
+	pooledTransform := components.NewTransform(500, 500)
+	pooledTransform.Reset()
+	pool := &stubComponentPool{free: map[string]components.Component{"Transform": pooledTransform}}
+	packet := newSpawnTestEntity(1)
+	ss := NewSpawnSystem(func() Entity { return packet })
+	ss.SetComponentPool(pool)
+
+	ss.spawnPacket()
+
+	expected := []string{"Transform", "Sprite", "Collider", "Physics", "PacketType"}
+	if fmt.Sprint(pool.acquired) != fmt.Sprint(expected) {
+		t.Errorf("Expected components %v to be acquired from the pool, got %v", expected, pool.acquired)
+	}
+	if packet.GetComponent("Transform") != components.Component(pooledTransform) {
+		t.Fatal("Expected the pooled transform to be used")
+	}
+	if pooledTransform.Y != -15 || pooledTransform.PrevY != -15 {
+		t.Errorf("Expected the pooled transform at the spawn position, got %+v", pooledTransform)
+	}
+	collider := packet.GetComponent("Collider").(*components.Collider)
+	if collider.Tag != "packet" || collider.Width != 15 || !collider.Active {
+		t.Errorf("Expected a packet collider, got %+v", collider)
+	}
+	if physics := packet.GetComponent("Physics").(*components.Physics); physics.VelocityY != ss.packetSpeed {
+		t.Errorf("Expected packet speed %.1f, got %.1f", ss.packetSpeed, physics.VelocityY)
+	}
+}
@@ -3,6 +3,8 @@ package systems
 import (
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/prefabs"
+	"lbbaspack/engine/random"
 )
 
 // Entity interface defines what an entity must provide
@@ -42,9 +44,210 @@ type SystemInfoer interface {
 	GetSystemInfo() *SystemInfo
 }
 
+// StatefulSystem is implemented by systems whose internal counters must
+// survive a save and resume of the game
+type StatefulSystem interface {
+	SaveState() ([]byte, error)
+	LoadState(data []byte) error
+}
+
+// EntityView is a live, incrementally maintained set of active entities that
+// have a fixed list of components. Implemented by ecs.World views.
+type EntityView interface {
+	Entities() []Entity
+}
+
+// ViewProvider creates entity views for a list of required components
+type ViewProvider interface {
+	NewView(required []string) EntityView
+}
+
+// ViewUser is implemented by systems that can filter through a cached view
+// instead of scanning every entity each frame
+type ViewUser interface {
+	GetRequiredComponents() []string
+	SetView(view EntityView)
+}
+
+// RandomUser is implemented by systems that draw random numbers, so that
+// they can be given streams from a seeded RNG
+type RandomUser interface {
+	SetRNG(rng *random.RNG)
+}
+
+// CommandBuffer records structural changes made while systems iterate over
+// entities, so that they are applied together at a sync point instead of
+// mid-iteration. Implemented by ecs.CommandBuffer.
+type CommandBuffer interface {
+	Spawn(build func(entity Entity))
+	Destroy(entity Entity)
+	AddComponent(entity Entity, component components.Component)
+	RemoveComponent(entity Entity, componentType string)
+	Len() int
+	// Flush applies all recorded commands in order and returns the
+	// resulting entity list
+	Flush() []Entity
+}
+
+// CommandUser is implemented by systems that can defer structural changes
+// through a command buffer
+type CommandUser interface {
+	SetCommands(commands CommandBuffer)
+}
+
+// EntityResolver looks entities up by generational handle, reporting false
+// for handles to entities that have since been removed. Implemented by
+// ecs.World.
+type EntityResolver interface {
+	ResolveEntity(handle components.EntityHandle) (Entity, bool)
+}
+
+// ResolverUser is implemented by systems that follow entity handles carried
+// in events and components
+type ResolverUser interface {
+	SetResolver(resolver EntityResolver)
+}
+
+// ComponentPool hands out reset component instances for reuse instead of
+// allocating new ones. Implemented by ecs.World.
+type ComponentPool interface {
+	AcquireComponent(componentType string) (components.Component, bool)
+}
+
+// PoolUser is implemented by systems that create components for high-churn
+// entities and can draw them from a pool
+type PoolUser interface {
+	SetComponentPool(pool ComponentPool)
+}
+
+// PrefabUser is implemented by systems that build entities from prefabs
+type PrefabUser interface {
+	SetPrefabs(library *prefabs.Library)
+}
+
+// Initializer is implemented by systems that subscribe to events, which they
+// do when the manager enables them
+type Initializer interface {
+	Initialize(eventDispatcher *events.EventDispatcher)
+}
+
+// Unsubscriber is implemented by systems that subscribe to events and can
+// remove their handlers again
+type Unsubscriber interface {
+	Unsubscribe()
+}
+
+// handleOwner is implemented by entities that carry a world-issued handle
+type handleOwner interface {
+	Handle() components.EntityHandle
+}
+
+// HandleOf returns the handle the world issued for an entity, or the zero
+// handle if the entity does not carry one
+func HandleOf(entity Entity) components.EntityHandle {
+	if owner, ok := entity.(handleOwner); ok {
+		return owner.Handle()
+	}
+	return components.EntityHandle{}
+}
+
 // BaseSystem provides common functionality for systems
 type BaseSystem struct {
 	RequiredComponents []string
+	view               EntityView
+	tables             ComponentTables
+	commands           CommandBuffer
+	resolver           EntityResolver
+	pool               ComponentPool
+	subscriptions      *events.Scope
+	processed          int // Entities returned to the system since the last profile
+}
+
+// subscriptionScope returns a new scope for the handlers an Initialize call
+// subscribes, removing those of an earlier call so they are not registered twice
+func (bs *BaseSystem) subscriptionScope(eventDispatcher *events.EventDispatcher) *events.Scope {
+	bs.Unsubscribe()
+	bs.subscriptions = eventDispatcher.NewScope()
+	return bs.subscriptions
+}
+
+// Unsubscribe removes the event handlers the system subscribed in Initialize
+func (bs *BaseSystem) Unsubscribe() {
+	if bs.subscriptions != nil {
+		bs.subscriptions.Close()
+		bs.subscriptions = nil
+	}
+}
+
+// takeProcessed returns how many entities were returned to the system since the
+// previous call, for the profiler
+func (bs *BaseSystem) takeProcessed() int {
+	processed := bs.processed
+	bs.processed = 0
+	return processed
+}
+
+// SetTables attaches the component tables that typed queries read from
+func (bs *BaseSystem) SetTables(tables ComponentTables) {
+	bs.tables = tables
+}
+
+// SetResolver attaches the resolver used to follow entity handles
+func (bs *BaseSystem) SetResolver(resolver EntityResolver) {
+	bs.resolver = resolver
+}
+
+// resolve looks up the entity behind a handle, reporting false for stale
+// handles or when no resolver is attached
+func (bs *BaseSystem) resolve(handle components.EntityHandle) (Entity, bool) {
+	if bs.resolver == nil || !handle.IsValid() {
+		return nil, false
+	}
+	return bs.resolver.ResolveEntity(handle)
+}
+
+// SetComponentPool attaches the pool that new components are drawn from
+func (bs *BaseSystem) SetComponentPool(pool ComponentPool) {
+	bs.pool = pool
+}
+
+// SetCommands attaches a command buffer; structural changes made through the
+// BaseSystem helpers are deferred to it from then on
+func (bs *BaseSystem) SetCommands(commands CommandBuffer) {
+	bs.commands = commands
+}
+
+// destroyEntity deactivates an entity, deferred to the next sync point when a
+// command buffer is attached
+func (bs *BaseSystem) destroyEntity(entity Entity) {
+	if bs.commands != nil {
+		bs.commands.Destroy(entity)
+		return
+	}
+	entity.(interface{ SetActive(bool) }).SetActive(false)
+}
+
+// addComponent adds a component, deferred when a command buffer is attached
+func (bs *BaseSystem) addComponent(entity Entity, component components.Component) {
+	if bs.commands != nil {
+		bs.commands.AddComponent(entity, component)
+		return
+	}
+	entity.AddComponent(component)
+}
+
+// removeComponent removes a component, deferred when a command buffer is attached
+func (bs *BaseSystem) removeComponent(entity Entity, componentType string) {
+	if bs.commands != nil {
+		bs.commands.RemoveComponent(entity, componentType)
+		return
+	}
+	entity.RemoveComponent(componentType)
+}
+
+// SetView attaches a cached view; RequiredEntities reads it from then on
+func (bs *BaseSystem) SetView(view EntityView) {
+	bs.view = view
 }
 
 // GetRequiredComponents returns the components required by this system
@@ -52,7 +255,29 @@ func (bs *BaseSystem) GetRequiredComponents() []string {
 	return bs.RequiredComponents
 }
 
-// FilterEntities returns entities that have all required components
+// ViewEntities returns the entities of the attached view, and false when no
+// view is attached
+func (bs *BaseSystem) ViewEntities() ([]Entity, bool) {
+	if bs.view == nil {
+		return nil, false
+	}
+	return bs.view.Entities(), true
+}
+
+// RequiredEntities returns the active entities of the world that have all
+// required components. world must be every entity the system is updated
+// with: it is filtered when no view is attached, while an attached view
+// already holds the result and is returned without allocating.
+func (bs *BaseSystem) RequiredEntities(world []Entity) []Entity {
+	if viewed, ok := bs.ViewEntities(); ok {
+		bs.processed += len(viewed)
+		return viewed
+	}
+	return bs.FilterEntities(world)
+}
+
+// FilterEntities returns the active entities out of entities that have all
+// required components
 func (bs *BaseSystem) FilterEntities(entities []Entity) []Entity {
 	var filtered []Entity
 
@@ -74,5 +299,6 @@ func (bs *BaseSystem) FilterEntities(entities []Entity) []Entity {
 		}
 	}
 
+	bs.processed += len(filtered)
 	return filtered
 }
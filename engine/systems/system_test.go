@@ -3,6 +3,8 @@ package systems
 import (
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/entities"
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/random"
 	"testing"
 )
 
@@ -405,6 +407,415 @@ func TestBaseSystem_FilterEntities_EdgeCases(t *testing.T) {
 	})
 }
 
+// stubView is a fixed EntityView for testing
+type stubView struct {
+	entities []Entity
+	calls    int
+}
+
+func (sv *stubView) Entities() []Entity {
+	sv.calls++
+	return sv.entities
+}
+
+// stubViewProvider records the views it hands out
+type stubViewProvider struct {
+	requested [][]string
+}
+
+func (svp *stubViewProvider) NewView(required []string) EntityView {
+	svp.requested = append(svp.requested, required)
+	return &stubView{}
+}
+
+// TestBaseSystem_RequiredEntities_WithView tests that an attached view replaces scanning the world
+func TestBaseSystem_RequiredEntities_WithView(t *testing.T) {
+	bs := &BaseSystem{RequiredComponents: []string{"Transform"}}
+	other := newSystemTestEntity(2)
+	other.AddComponent(components.NewTransform(0, 0))
+
+	if result := bs.RequiredEntities([]Entity{other}); len(result) != 1 || result[0] != other {
+		t.Error("RequiredEntities() should filter the world when no view is attached")
+	}
+	if _, ok := bs.ViewEntities(); ok {
+		t.Error("ViewEntities() should report that no view is attached")
+	}
+
+	cached := newSystemTestEntity(1)
+	view := &stubView{entities: []Entity{cached}}
+	bs.SetView(view)
+
+	result := bs.RequiredEntities([]Entity{other})
+	if len(result) != 1 || result[0] != cached {
+		t.Error("RequiredEntities() should return the view's entities when a view is attached")
+	}
+	if view.calls != 1 {
+		t.Errorf("Expected view to be read once, got %d", view.calls)
+	}
+}
+
+// TestBaseSystem_FilterEntities_WithView tests that FilterEntities filters the given entities even with a view attached
+func TestBaseSystem_FilterEntities_WithView(t *testing.T) {
+	bs := &BaseSystem{RequiredComponents: []string{"Transform"}}
+	view := &stubView{entities: []Entity{newSystemTestEntity(1)}}
+	bs.SetView(view)
+
+	other := newSystemTestEntity(2)
+	other.AddComponent(components.NewTransform(0, 0))
+	without := newSystemTestEntity(3)
+
+	result := bs.FilterEntities([]Entity{other, without})
+	if len(result) != 1 || result[0] != other {
+		t.Errorf("FilterEntities() should filter the given entities, got %d", len(result))
+	}
+	if view.calls != 0 {
+		t.Errorf("Expected the view left unread, got %d reads", view.calls)
+	}
+}
+
+// TestSystemManager_AttachViews tests that the manager hands views to registered systems
+func TestSystemManager_AttachViews(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	collisionSys := NewCollisionSystem()
+	if err := manager.RegisterSystem(collisionSys.GetSystemInfo()); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+
+	provider := &stubViewProvider{}
+	manager.AttachViews(provider)
+
+	if len(provider.requested) != 1 {
+		t.Fatalf("Expected 1 view to be requested, got %d", len(provider.requested))
+	}
+	if len(provider.requested[0]) != 2 || provider.requested[0][0] != "Transform" || provider.requested[0][1] != "Collider" {
+		t.Errorf("Expected view for collision's required components, got %v", provider.requested[0])
+	}
+	if collisionSys.view == nil {
+		t.Error("Expected collision system to receive a view")
+	}
+}
+
+// stubCommands is an in-memory CommandBuffer that applies commands on flush
+type stubCommands struct {
+	pending  []func()
+	entities []Entity
+	flushes  []int
+	nextID   uint64
+}
+
+func (sc *stubCommands) Spawn(build func(entity Entity)) {
+	sc.pending = append(sc.pending, func() {
+		sc.nextID++
+		entity := entities.NewEntity(sc.nextID)
+		build(entity)
+		sc.entities = append(sc.entities, entity)
+	})
+}
+
+func (sc *stubCommands) Destroy(entity Entity) {
+	sc.pending = append(sc.pending, func() { entity.(interface{ SetActive(bool) }).SetActive(false) })
+}
+
+func (sc *stubCommands) AddComponent(entity Entity, component components.Component) {
+	sc.pending = append(sc.pending, func() { entity.AddComponent(component) })
+}
+
+func (sc *stubCommands) RemoveComponent(entity Entity, componentType string) {
+	sc.pending = append(sc.pending, func() { entity.RemoveComponent(componentType) })
+}
+
+func (sc *stubCommands) Len() int {
+	return len(sc.pending)
+}
+
+func (sc *stubCommands) Flush() []Entity {
+	sc.flushes = append(sc.flushes, len(sc.pending))
+	for _, apply := range sc.pending {
+		apply()
+	}
+	sc.pending = nil
+	return sc.entities
+}
+
+// stubResolver is an EntityResolver over entities registered by a test
+type stubResolver struct {
+	entities []Entity
+}
+
+// register issues a handle for an entity
+func (sr *stubResolver) register(entity Entity) components.EntityHandle {
+	sr.entities = append(sr.entities, entity)
+	return components.EntityHandle{Index: uint32(len(sr.entities) - 1), Generation: 1}
+}
+
+// remove makes a handle stale
+func (sr *stubResolver) remove(handle components.EntityHandle) {
+	sr.entities[handle.Index] = nil
+}
+
+func (sr *stubResolver) ResolveEntity(handle components.EntityHandle) (Entity, bool) {
+	if handle.Generation != 1 || int(handle.Index) >= len(sr.entities) || sr.entities[handle.Index] == nil {
+		return nil, false
+	}
+	return sr.entities[handle.Index], true
+}
+
+// TestBaseSystem_Resolve tests that handles resolve through the attached resolver
+func TestBaseSystem_Resolve(t *testing.T) {
+	bs := &BaseSystem{}
+	entity := entities.NewEntity(1)
+	resolver := &stubResolver{}
+	handle := resolver.register(entity)
+
+	if _, ok := bs.resolve(handle); ok {
+		t.Error("Expected resolve to fail without a resolver")
+	}
+
+	bs.SetResolver(resolver)
+	if resolved, ok := bs.resolve(handle); !ok || resolved != entity {
+		t.Errorf("Expected handle to resolve to the entity, got %v", resolved)
+	}
+	if _, ok := bs.resolve(components.EntityHandle{}); ok {
+		t.Error("Expected the zero handle not to resolve")
+	}
+
+	resolver.remove(handle)
+	if _, ok := bs.resolve(handle); ok {
+		t.Error("Expected a stale handle not to resolve")
+	}
+}
+
+// TestHandleOf tests reading handles from entities
+func TestHandleOf(t *testing.T) {
+	entity := entities.NewEntity(1)
+	handle := components.EntityHandle{Index: 4, Generation: 2}
+	entity.SetHandle(handle)
+
+	if got := HandleOf(entity); got != handle {
+		t.Errorf("Expected handle %s, got %s", handle, got)
+	}
+	if got := HandleOf(&systemTestEntity{}); got.IsValid() {
+		t.Errorf("Expected the zero handle for entities without one, got %s", got)
+	}
+}
+
+// TestBaseSystem_CommandHelpers tests that structural changes are deferred once a buffer is attached
+func TestBaseSystem_CommandHelpers(t *testing.T) {
+	bs := &BaseSystem{}
+	entity := entities.NewEntity(1)
+
+	// Without a buffer changes are immediate
+	bs.addComponent(entity, components.NewRouting(components.EntityHandle{}, 100))
+	if !entity.HasComponent("Routing") {
+		t.Fatal("Expected immediate add without a command buffer")
+	}
+
+	commands := &stubCommands{}
+	bs.SetCommands(commands)
+	bs.removeComponent(entity, "Routing")
+	bs.destroyEntity(entity)
+
+	if !entity.HasComponent("Routing") || !entity.IsActive() {
+		t.Error("Expected changes to wait for the flush")
+	}
+	if commands.Len() != 2 {
+		t.Errorf("Expected 2 recorded commands, got %d", commands.Len())
+	}
+
+	commands.Flush()
+	if entity.HasComponent("Routing") || entity.IsActive() {
+		t.Error("Expected changes to apply on flush")
+	}
+}
+
+// syncTestSystem records the entities it sees and optionally spawns one
+type syncTestSystem struct {
+	BaseSystem
+	spawn bool
+	seen  int
+}
+
+func (sts *syncTestSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+	sts.seen = len(entities)
+	if sts.spawn {
+		sts.commands.Spawn(func(entity Entity) {})
+	}
+}
+
+// TestSystemManager_CommandSyncPoints tests that commands are flushed between systems in update order
+func TestSystemManager_CommandSyncPoints(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	producer := &syncTestSystem{spawn: true}
+	consumer := &syncTestSystem{}
+	if err := manager.RegisterSystem(&SystemInfo{Type: "producer", System: producer}); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.RegisterSystem(&SystemInfo{Type: "consumer", System: consumer, Dependencies: []SystemType{"producer"}}); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+
+	commands := &stubCommands{}
+	manager.AttachCommands(commands)
+	manager.UpdateAll(1.0/60.0, []Entity{}, events.NewEventDispatcher())
+
+	if producer.seen != 0 {
+		t.Errorf("Expected producer to see no entities, got %d", producer.seen)
+	}
+	if consumer.seen != 1 {
+		t.Errorf("Expected consumer to see the spawned entity after the sync point, got %d", consumer.seen)
+	}
+	if len(commands.flushes) != 1 || commands.flushes[0] != 1 {
+		t.Errorf("Expected a single flush of 1 command, got %v", commands.flushes)
+	}
+}
+
+// eventSyncTestSystem publishes a packet loss or records how many it has heard of
+type eventSyncTestSystem struct {
+	BaseSystem
+	publish bool
+	heard   *int
+	seen    int
+}
+
+func (ests *eventSyncTestSystem) Update(deltaTime float64, entities []Entity, eventDispatcher *events.EventDispatcher) {
+	if ests.publish {
+		events.Publish(eventDispatcher, events.PacketLost{})
+		return
+	}
+	ests.seen = *ests.heard
+}
+
+// TestSystemManager_EventSyncPoints tests that queued events are delivered between stages
+func TestSystemManager_EventSyncPoints(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	heard := 0
+	producer := &eventSyncTestSystem{publish: true}
+	consumer := &eventSyncTestSystem{heard: &heard}
+	if err := manager.RegisterSystem(&SystemInfo{Type: "producer", System: producer}); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.RegisterSystem(&SystemInfo{Type: "consumer", System: consumer, Dependencies: []SystemType{"producer"}}); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.SetMode(events.DispatchQueued)
+	events.Subscribe(dispatcher, func(events.PacketLost) {
+		heard++
+	})
+	manager.UpdateAll(1.0/60.0, []Entity{}, dispatcher)
+
+	if consumer.seen != 1 {
+		t.Errorf("Expected the consumer to run after the event was delivered, got %d", consumer.seen)
+	}
+	if dispatcher.Pending() != 0 {
+		t.Errorf("Expected no events left in the queue, got %d", dispatcher.Pending())
+	}
+}
+
+// TestSystemManager_AttachRNG tests that the manager hands the seeded RNG to random users
+func TestSystemManager_AttachRNG(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	spawnSys := NewSpawnSystem(nil)
+	if err := manager.RegisterSystem(spawnSys.GetSystemInfo()); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+
+	rng := random.New(11)
+	manager.AttachRNG(rng)
+
+	if spawnSys.spawnRand != rng.Stream(random.StreamSpawn) {
+		t.Error("Expected spawn system to use the spawn stream")
+	}
+	if spawnSys.ddosRand != rng.Stream(random.StreamDDoS) {
+		t.Error("Expected spawn system to use the ddos stream")
+	}
+	if spawnSys.powerUpRand != rng.Stream(random.StreamPowerUps) {
+		t.Error("Expected spawn system to use the powerups stream")
+	}
+}
+
+// TestSystemManager_SaveLoadStates tests that the manager saves and restores stateful systems
+func TestSystemManager_SaveLoadStates(t *testing.T) {
+	manager := NewSystemManager()
+	manager.SetVerbose(false)
+	gameStateSys := NewGameStateSystem()
+	if err := manager.RegisterSystem(gameStateSys.GetSystemInfo()); err != nil {
+		t.Fatalf("Failed to register system: %v", err)
+	}
+	if err := manager.BuildExecutionOrder(); err != nil {
+		t.Fatalf("Failed to build execution order: %v", err)
+	}
+	gameStateSys.score = 500
+
+	states, err := manager.SaveStates()
+	if err != nil {
+		t.Fatalf("SaveStates failed: %v", err)
+	}
+	if _, exists := states[gameStateSys.GetSystemInfo().Type]; !exists {
+		t.Fatalf("Expected game state to be saved, got %v", states)
+	}
+
+	gameStateSys.score = 0
+	if err := manager.LoadStates(states); err != nil {
+		t.Fatalf("LoadStates failed: %v", err)
+	}
+	if gameStateSys.GetScore() != 500 {
+		t.Errorf("Expected score 500 after load, got %d", gameStateSys.GetScore())
+	}
+
+	states[gameStateSys.GetSystemInfo().Type] = []byte("{")
+	if err := manager.LoadStates(states); err == nil {
+		t.Error("Expected LoadStates to report invalid state")
+	}
+}
+
+// TestSystemManager_CopyStatesFrom tests that system state carries over to another manager
+func TestSystemManager_CopyStatesFrom(t *testing.T) {
+	newManager := func() (*SystemManager, *GameStateSystem) {
+		manager := NewSystemManager()
+		manager.SetVerbose(false)
+		gameStateSys := NewGameStateSystem()
+		if err := manager.RegisterSystem(gameStateSys.GetSystemInfo()); err != nil {
+			t.Fatalf("Failed to register system: %v", err)
+		}
+		if err := manager.BuildExecutionOrder(); err != nil {
+			t.Fatalf("Failed to build execution order: %v", err)
+		}
+		return manager, gameStateSys
+	}
+	original, originalSys := newManager()
+	copied, copiedSys := newManager()
+	originalSys.score = 250
+
+	if err := copied.CopyStatesFrom(original); err != nil {
+		t.Fatalf("CopyStatesFrom failed: %v", err)
+	}
+	if copiedSys.GetScore() != 250 {
+		t.Errorf("Expected score 250 after copy, got %d", copiedSys.GetScore())
+	}
+	copiedSys.score = 0
+	if originalSys.GetScore() != 250 {
+		t.Error("Expected the original system to be unaffected by the copy")
+	}
+}
+
 // TestEntityInterface tests the Entity interface methods
 func TestEntityInterface(t *testing.T) {
 	t.Run("Component getters", func(t *testing.T) {
@@ -1,9 +1,11 @@
 package systems
 
 import (
+	"encoding/json"
 	"fmt"
 	"image/color"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 
 	"lbbaspack/engine/components"
 
@@ -14,6 +16,8 @@ import (
 
 const SystemTypeUI SystemType = "ui"
 
+var uiLog = logging.For(string(SystemTypeUI))
+
 type UISystem struct {
 	BaseSystem
 	score           int
@@ -48,43 +52,33 @@ func (uis *UISystem) Update(deltaTime float64, entities []Entity, eventDispatche
 }
 
 func (uis *UISystem) Initialize(eventDispatcher *events.EventDispatcher) {
+	scope := uis.subscriptionScope(eventDispatcher)
+
 	// Listen for SLA update events
-	eventDispatcher.Subscribe(events.EventSLAUpdated, func(event *events.Event) {
-		if event.Data.Current != nil {
-			uis.currentSLA = *event.Data.Current
-		}
-		if event.Data.Target != nil {
-			uis.targetSLA = *event.Data.Target
-		}
-		if event.Data.Caught != nil {
-			uis.caughtPackets = *event.Data.Caught
-		}
-		if event.Data.Lost != nil {
-			uis.lostPackets = *event.Data.Lost
-		}
-		if event.Data.Remaining != nil {
-			uis.remainingErrors = *event.Data.Remaining
-		}
-		if event.Data.Budget != nil {
-			uis.errorBudget = *event.Data.Budget
+	events.Subscribe(scope, func(updated events.SLAUpdated) {
+		uis.currentSLA = updated.Current
+		if updated.Target != 0 {
+			uis.targetSLA = updated.Target
 		}
+		uis.caughtPackets = updated.Caught
+		uis.lostPackets = updated.Lost
+		uis.remainingErrors = updated.Remaining
+		uis.errorBudget = updated.Budget
 	})
 
 	// Note: Packet lost events are handled by the SLA system which publishes EventSLAUpdated
 	// The UI system gets the updated counts from EventSLAUpdated events
 
 	// Listen for level-up events
-	eventDispatcher.Subscribe(events.EventLevelUp, func(event *events.Event) {
-		if event.Data.Level != nil {
-			uis.level = *event.Data.Level
-		}
+	events.Subscribe(scope, func(levelUp events.LevelUp) {
+		uis.level = levelUp.Level
 	})
 
 	// Listen for DDoS events
-	eventDispatcher.Subscribe(events.EventDDoSStart, func(event *events.Event) {
+	events.Subscribe(scope, func(events.DDoSStart) {
 		uis.isDDoSActive = true
 	})
-	eventDispatcher.Subscribe(events.EventDDoSEnd, func(event *events.Event) {
+	events.Subscribe(scope, func(events.DDoSEnd) {
 		uis.isDDoSActive = false
 	})
 }
@@ -106,6 +100,52 @@ func (uis *UISystem) GetErrorBudget() int {
 	return uis.errorBudget
 }
 
+// uiState is the serialized form of the counters shown by the UI
+type uiState struct {
+	Score           int     `json:"score"`
+	CurrentSLA      float64 `json:"currentSLA"`
+	TargetSLA       float64 `json:"targetSLA"`
+	CaughtPackets   int     `json:"caughtPackets"`
+	LostPackets     int     `json:"lostPackets"`
+	RemainingErrors int     `json:"remainingErrors"`
+	ErrorBudget     int     `json:"errorBudget"`
+	Level           int     `json:"level"`
+	IsDDoSActive    bool    `json:"isDDoSActive"`
+}
+
+// SaveState implements StatefulSystem
+func (uis *UISystem) SaveState() ([]byte, error) {
+	return json.Marshal(uiState{
+		Score:           uis.score,
+		CurrentSLA:      uis.currentSLA,
+		TargetSLA:       uis.targetSLA,
+		CaughtPackets:   uis.caughtPackets,
+		LostPackets:     uis.lostPackets,
+		RemainingErrors: uis.remainingErrors,
+		ErrorBudget:     uis.errorBudget,
+		Level:           uis.level,
+		IsDDoSActive:    uis.isDDoSActive,
+	})
+}
+
+// LoadState implements StatefulSystem
+func (uis *UISystem) LoadState(data []byte) error {
+	var state uiState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	uis.score = state.Score
+	uis.currentSLA = state.CurrentSLA
+	uis.targetSLA = state.TargetSLA
+	uis.caughtPackets = state.CaughtPackets
+	uis.lostPackets = state.LostPackets
+	uis.remainingErrors = state.RemainingErrors
+	uis.errorBudget = state.ErrorBudget
+	uis.level = state.Level
+	uis.isDDoSActive = state.IsDDoSActive
+	return nil
+}
+
 // Reset method to clear all counters for new game
 func (uis *UISystem) Reset() {
 	uis.score = 0
@@ -115,14 +155,14 @@ func (uis *UISystem) Reset() {
 	uis.remainingErrors = uis.errorBudget // Reset to current error budget
 	uis.level = 1
 	uis.isDDoSActive = false
-	fmt.Printf("UI system reset - counters cleared, error budget: %d, remaining errors: %d\n", uis.errorBudget, uis.remainingErrors)
+	uiLog.Debug("Reset counters", "errorBudget", uis.errorBudget, "remainingErrors", uis.remainingErrors)
 }
 
 // SetErrorBudget updates the error budget and remaining errors
 func (uis *UISystem) SetErrorBudget(budget int) {
 	uis.errorBudget = budget
 	uis.remainingErrors = budget // Reset remaining errors to new budget
-	fmt.Printf("UI system error budget set to %d, remaining errors: %d\n", uis.errorBudget, uis.remainingErrors)
+	uiLog.Debug("Error budget set", "errorBudget", uis.errorBudget, "remainingErrors", uis.remainingErrors)
 }
 
 func (uis *UISystem) Draw(screen *ebiten.Image, entities []Entity) {
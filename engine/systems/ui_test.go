@@ -268,14 +268,16 @@ func TestUISystem_Initialize(t *testing.T) {
 	t.Run("Packet Lost Event", func(t *testing.T) {
 		initialLost := uis.lostPackets
 
-		// UI system now gets packet lost info from SLA update events
-		event := events.NewEvent(events.EventSLAUpdated, &events.EventData{
-			Lost:      intPtr(initialLost + 1),
-			Remaining: intPtr(uis.errorBudget - (initialLost + 1)),
+		// UI system now gets packet lost info from SLA update events, which
+		// carry the full SLA state
+		events.Publish(eventDispatcher, events.SLAUpdated{
+			Current:   uis.currentSLA,
+			Caught:    uis.caughtPackets,
+			Lost:      initialLost + 1,
+			Remaining: uis.errorBudget - (initialLost + 1),
+			Budget:    uis.errorBudget,
 		})
 
-		eventDispatcher.Publish(event)
-
 		if uis.lostPackets != initialLost+1 {
 			t.Errorf("Expected lostPackets to be %d, got %d", initialLost+1, uis.lostPackets)
 		}
@@ -521,10 +523,13 @@ func TestUISystem_Integration(t *testing.T) {
 		eventDispatcher.Publish(ddosStartEvent)
 
 		// 4. Lose a packet (UI system now gets this info from SLA update)
-		packetLostEvent := events.NewEvent(events.EventSLAUpdated, &events.EventData{
-			Lost: intPtr(4), // Update to 4 lost packets
+		events.Publish(eventDispatcher, events.SLAUpdated{
+			Current:   95.0,
+			Caught:    20,
+			Lost:      4, // Update to 4 lost packets
+			Remaining: 6,
+			Budget:    10,
 		})
-		eventDispatcher.Publish(packetLostEvent)
 
 		// 5. End DDoS attack
 		ddosEndEvent := &events.Event{
@@ -780,3 +785,29 @@ func float64Ptr(v float64) *float64 {
 func intPtr(v int) *int {
 	return &v
 }
+
+// TestUISystem_SaveLoadState tests that the displayed counters survive a roundtrip
+func TestUISystem_SaveLoadState(t *testing.T) {
+	uis := NewUISystem(nil)
+	uis.score = 300
+	uis.caughtPackets = 20
+	uis.lostPackets = 2
+	uis.remainingErrors = 8
+	uis.level = 2
+	uis.isDDoSActive = true
+
+	data, err := uis.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored := NewUISystem(nil)
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	if restored.score != 300 || restored.GetCaughtPackets() != 20 || restored.GetLostPackets() != 2 ||
+		restored.GetRemainingErrors() != 8 || restored.level != 2 || !restored.isDDoSActive {
+		t.Errorf("Expected UI counters to be restored, got %+v", restored)
+	}
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/ecs"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
 	"lbbaspack/engine/systems"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -47,8 +55,8 @@ func TestNewGame(t *testing.T) {
 	}
 
 	// Verify initial game state
-	if game.gameState != components.StateMenu {
-		t.Errorf("Expected initial game state to be StateMenu, got %v", game.gameState)
+	if game.state() != components.StateMenu {
+		t.Errorf("Expected initial game state to be StateMenu, got %v", game.state())
 	}
 }
 
@@ -63,8 +71,8 @@ func TestGame_Update(t *testing.T) {
 	}
 
 	// Verify game state remains menu
-	if game.gameState != components.StateMenu {
-		t.Errorf("Expected game state to remain StateMenu, got %v", game.gameState)
+	if game.state() != components.StateMenu {
+		t.Errorf("Expected game state to remain StateMenu, got %v", game.state())
 	}
 }
 
@@ -77,7 +85,7 @@ func TestGame_Update_GameStart(t *testing.T) {
 		SLA:    float64Ptr(99.5),
 		Errors: intPtr(10),
 	})
-	game.eventDispatcher.Publish(gameStartEvent)
+	game.eventDispatcher.PublishImmediate(gameStartEvent)
 
 	// Update game
 	err := game.Update()
@@ -86,8 +94,8 @@ func TestGame_Update_GameStart(t *testing.T) {
 	}
 
 	// Verify game state changed to playing
-	if game.gameState != components.StatePlaying {
-		t.Errorf("Expected game state to be StatePlaying, got %v", game.gameState)
+	if game.state() != components.StatePlaying {
+		t.Errorf("Expected game state to be StatePlaying, got %v", game.state())
 	}
 }
 
@@ -96,7 +104,7 @@ func TestGame_Update_GameOver(t *testing.T) {
 	game := NewGame()
 
 	// Set game state directly to playing to avoid event chain issues
-	game.gameState = components.StatePlaying
+	game.setState(components.StatePlaying)
 
 	// Publish game over event
 	gameOverEvent := events.NewEvent(events.EventGameOver, nil)
@@ -109,8 +117,8 @@ func TestGame_Update_GameOver(t *testing.T) {
 	}
 
 	// Verify game state changed to game over
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected game state to be StateGameOver, got %v", game.gameState)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected game state to be StateGameOver, got %v", game.state())
 	}
 }
 
@@ -119,11 +127,11 @@ func TestGame_Update_GameOver_EscapeKey(t *testing.T) {
 	game := NewGame()
 
 	// Set game state directly to game over
-	game.gameState = components.StateGameOver
+	game.setState(components.StateGameOver)
 
 	// Verify we start in game over state
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected game state to be StateGameOver, got %v", game.gameState)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected game state to be StateGameOver, got %v", game.state())
 	}
 
 	// Simulate escape key press by mocking ebiten.IsKeyPressed
@@ -138,8 +146,8 @@ func TestGame_Update_GameOver_EscapeKey(t *testing.T) {
 	}
 
 	// Verify game state remains game over when escape is not pressed
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected game state to remain StateGameOver when escape not pressed, got %v", game.gameState)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected game state to remain StateGameOver when escape not pressed, got %v", game.state())
 	}
 }
 
@@ -173,7 +181,7 @@ func TestGame_Draw_PlayingState(t *testing.T) {
 	game := NewGame()
 
 	// Set game state directly to playing to avoid event chain issues
-	game.gameState = components.StatePlaying
+	game.setState(components.StatePlaying)
 
 	// Create a dummy screen for testing
 	screen := ebiten.NewImage(800, 600)
@@ -183,8 +191,8 @@ func TestGame_Draw_PlayingState(t *testing.T) {
 	game.Draw(screen)
 
 	// Verify game state is playing
-	if game.gameState != components.StatePlaying {
-		t.Errorf("Expected game state to be StatePlaying, got %v", game.gameState)
+	if game.state() != components.StatePlaying {
+		t.Errorf("Expected game state to be StatePlaying, got %v", game.state())
 	}
 }
 
@@ -193,7 +201,7 @@ func TestGame_Draw_GameOverState(t *testing.T) {
 	game := NewGame()
 
 	// Set game state directly to game over to avoid event chain issues
-	game.gameState = components.StateGameOver
+	game.setState(components.StateGameOver)
 
 	// Create a dummy screen for testing
 	screen := ebiten.NewImage(800, 600)
@@ -203,8 +211,8 @@ func TestGame_Draw_GameOverState(t *testing.T) {
 	game.Draw(screen)
 
 	// Verify game state is game over
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected game state to be StateGameOver, got %v", game.gameState)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected game state to be StateGameOver, got %v", game.state())
 	}
 }
 
@@ -315,15 +323,15 @@ func TestGame_EventHandling(t *testing.T) {
 		SLA:    float64Ptr(99.0),
 		Errors: intPtr(5),
 	})
-	game.eventDispatcher.Publish(gameStartEvent)
+	game.eventDispatcher.PublishImmediate(gameStartEvent)
 
 	// Update to process the event
 	if err := game.Update(); err != nil {
 		t.Errorf("Expected no error from game.Update(), got %v", err)
 	}
 
-	if game.gameState != components.StatePlaying {
-		t.Errorf("Expected game state to be StatePlaying after game start event, got %v", game.gameState)
+	if game.state() != components.StatePlaying {
+		t.Errorf("Expected game state to be StatePlaying after game start event, got %v", game.state())
 	}
 
 	// Test game over event
@@ -335,8 +343,25 @@ func TestGame_EventHandling(t *testing.T) {
 		t.Errorf("Expected no error from game.Update(), got %v", err)
 	}
 
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected game state to be StateGameOver after game over event, got %v", game.gameState)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected game state to be StateGameOver after game over event, got %v", game.state())
+	}
+}
+
+// TestGame_LifecycleEvents tests that systems' dispatcher receives the world's lifecycle events
+func TestGame_LifecycleEvents(t *testing.T) {
+	game := NewGame()
+	destroyed := 0
+	game.eventDispatcher.Subscribe(events.EventEntityDestroyed, func(event *events.Event) {
+		destroyed++
+	})
+
+	entity := game.World.NewEntity()
+	entity.SetActive(false)
+	game.World.RemoveInactiveEntities()
+
+	if destroyed != 1 {
+		t.Errorf("Expected 1 entity destroyed event, got %d", destroyed)
 	}
 }
 
@@ -345,19 +370,19 @@ func TestGame_StateTransitions(t *testing.T) {
 	game := NewGame()
 
 	// Initial state should be menu
-	if game.gameState != components.StateMenu {
-		t.Errorf("Expected initial state to be StateMenu, got %v", game.gameState)
+	if game.state() != components.StateMenu {
+		t.Errorf("Expected initial state to be StateMenu, got %v", game.state())
 	}
 
 	// Test direct state transitions to avoid event chain issues
-	game.gameState = components.StatePlaying
-	if game.gameState != components.StatePlaying {
-		t.Errorf("Expected state to be StatePlaying, got %v", game.gameState)
+	game.setState(components.StatePlaying)
+	if game.state() != components.StatePlaying {
+		t.Errorf("Expected state to be StatePlaying, got %v", game.state())
 	}
 
-	game.gameState = components.StateGameOver
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected state to be StateGameOver, got %v", game.gameState)
+	game.setState(components.StateGameOver)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected state to be StateGameOver, got %v", game.state())
 	}
 }
 
@@ -382,13 +407,13 @@ func TestGame_EdgeCases(t *testing.T) {
 
 		// Test rapid state changes directly
 		for i := 0; i < 10; i++ {
-			game.gameState = components.StatePlaying
-			game.gameState = components.StateGameOver
+			game.setState(components.StatePlaying)
+			game.setState(components.StateGameOver)
 		}
 
 		// Should end up in game over state (last assignment)
-		if game.gameState != components.StateGameOver {
-			t.Errorf("Expected final state to be StateGameOver, got %v", game.gameState)
+		if game.state() != components.StateGameOver {
+			t.Errorf("Expected final state to be StateGameOver, got %v", game.state())
 		}
 	})
 
@@ -411,14 +436,14 @@ func TestGame_Integration(t *testing.T) {
 	game := NewGame()
 
 	// Verify initial setup
-	if game.gameState != components.StateMenu {
-		t.Errorf("Expected initial state to be StateMenu, got %v", game.gameState)
+	if game.state() != components.StateMenu {
+		t.Errorf("Expected initial state to be StateMenu, got %v", game.state())
 	}
 
 	// Test state transitions directly
-	game.gameState = components.StatePlaying
-	if game.gameState != components.StatePlaying {
-		t.Errorf("Expected state to be StatePlaying, got %v", game.gameState)
+	game.setState(components.StatePlaying)
+	if game.state() != components.StatePlaying {
+		t.Errorf("Expected state to be StatePlaying, got %v", game.state())
 	}
 
 	// Simulate some game time
@@ -430,9 +455,9 @@ func TestGame_Integration(t *testing.T) {
 	}
 
 	// End the game
-	game.gameState = components.StateGameOver
-	if game.gameState != components.StateGameOver {
-		t.Errorf("Expected state to be StateGameOver, got %v", game.gameState)
+	game.setState(components.StateGameOver)
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected state to be StateGameOver, got %v", game.state())
 	}
 
 	// Test drawing in all states
@@ -486,3 +511,238 @@ func float64Ptr(v float64) *float64 {
 func intPtr(v int) *int {
 	return &v
 }
+
+// TestNewGameWithSeed_Reproducible tests that two games with the same seed play out identically
+func TestNewGameWithSeed_Reproducible(t *testing.T) {
+	run := func(seed int64) []string {
+		game := NewGameWithSeed(seed)
+		game.systemManager.SetExecutionMode(systems.ExecutionDeterministic)
+		now := time.Unix(0, 0)
+		game.World.Clock.SetTimeSource(func() time.Time { return now })
+		game.eventDispatcher.PublishImmediate(events.NewEvent(events.EventGameStart, &events.EventData{
+			SLA:    float64Ptr(99.5),
+			Errors: intPtr(10),
+		}))
+		for range 300 {
+			now = now.Add(time.Second / 60)
+			if err := game.Update(); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+		}
+
+		positions := make([]string, 0)
+		for _, entity := range game.World.Entities {
+			if entity.HasComponent("PacketType") {
+				transform := entity.GetTransform()
+				positions = append(positions, fmt.Sprintf("%d:%.2f,%.2f", entity.ID, transform.GetX(), transform.GetY()))
+			}
+		}
+		return positions
+	}
+
+	first, second := run(2024), run(2024)
+	if len(first) == 0 {
+		t.Fatal("Expected packets to spawn")
+	}
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Errorf("Expected identical packets for the same seed, got %v and %v", first, second)
+	}
+}
+
+// TestGame_Update_FixedTimestep tests that simulation steps follow elapsed time, not update calls
+func TestGame_Update_FixedTimestep(t *testing.T) {
+	game := NewGameWithSeed(1)
+	now := time.Unix(0, 0)
+	game.World.Clock.SetTimeSource(func() time.Time { return now })
+	game.setState(components.StatePlaying)
+
+	gameStateSys, _ := game.systemManager.GetSystem(systems.SystemTypeGameState)
+	gameTime := func() float64 { return gameStateSys.(*systems.GameStateSystem).GetGameTime() }
+
+	// The first update always runs one step
+	if err := game.Update(); err != nil {
+		t.Fatalf("Expected no error from Update, got %v", err)
+	}
+	step := game.World.Clock.Step()
+	if math.Abs(gameTime()-step) > 1e-9 {
+		t.Errorf("Expected one step of game time, got %f", gameTime())
+	}
+
+	// A 144 Hz display calls Update more often than the simulation steps
+	for range 12 {
+		now = now.Add(time.Second / 144)
+		if err := game.Update(); err != nil {
+			t.Fatalf("Expected no error from Update, got %v", err)
+		}
+	}
+	if steps := math.Round(gameTime() / step); steps != 6 {
+		t.Errorf("Expected 6 steps after 1/12s, got %.0f", steps)
+	}
+
+	// A long stall is capped instead of replaying every missed step
+	now = now.Add(5 * time.Second)
+	if err := game.Update(); err != nil {
+		t.Fatalf("Expected no error from Update, got %v", err)
+	}
+	if steps := math.Round(gameTime() / step); steps != 6+ecs.DefaultMaxSteps {
+		t.Errorf("Expected %d steps after a stall, got %.0f", 6+ecs.DefaultMaxSteps, steps)
+	}
+}
+
+// cloneSimulation copies the game into a simulation with the game's systems,
+// created quietly, since clones are made often
+func cloneSimulation(game *Game) (*ecs.Simulation, error) {
+	return ecs.NewSimulation(game.World, game.systemManager, func(clone *ecs.World) (*systems.SystemManager, error) {
+		config := systems.SystemConfig{Disabled: game.systemManager.DisabledSystems()}
+		_, systemManager, err := newSystemManager(clone, config, true)
+		return systemManager, err
+	})
+}
+
+// TestGame_CloneSimulation tests that a cloned simulation plays out like the game without changing it
+func TestGame_CloneSimulation(t *testing.T) {
+	game := NewGameWithSeed(7)
+	game.systemManager.SetExecutionMode(systems.ExecutionDeterministic)
+	now := time.Unix(0, 0)
+	game.World.Clock.SetTimeSource(func() time.Time { return now })
+	game.eventDispatcher.PublishImmediate(events.NewEvent(events.EventGameStart, &events.EventData{
+		SLA:    float64Ptr(99.5),
+		Errors: intPtr(10),
+	}))
+	advance := func(steps int) {
+		for range steps {
+			now = now.Add(time.Second / 60)
+			if err := game.Update(); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+		}
+	}
+	packets := func(world *ecs.World) string {
+		positions := make([]string, 0)
+		for _, entity := range world.Entities {
+			if entity.HasComponent("PacketType") {
+				transform := entity.GetTransform()
+				positions = append(positions, fmt.Sprintf("%d:%.2f,%.2f", entity.ID, transform.GetX(), transform.GetY()))
+			}
+		}
+		return fmt.Sprint(positions)
+	}
+	advance(120)
+
+	sim, err := cloneSimulation(game)
+	if err != nil {
+		t.Fatalf("Failed to clone simulation: %v", err)
+	}
+	before := packets(game.World)
+	if before == "[]" {
+		t.Fatal("Expected packets to spawn")
+	}
+	for range 120 {
+		sim.Step(game.World.Clock.Step())
+	}
+	if packets(game.World) != before {
+		t.Fatal("Expected stepping the clone to leave the game untouched")
+	}
+
+	advance(120)
+	if got, want := packets(sim.World), packets(game.World); got != want {
+		t.Errorf("Expected the clone to predict the game, got %s want %s", got, want)
+	}
+}
+
+// TestGame_Exit tests that an Exit event ends the run loop instead of the process
+func TestGame_Exit(t *testing.T) {
+	game := NewGame()
+	events.PublishImmediate(game.eventDispatcher, events.Exit{})
+	if err := game.Update(); err != ebiten.Termination {
+		t.Errorf("Expected Update to end the run loop, got %v", err)
+	}
+}
+
+// TestGame_CameraShake tests that the camera shake runs on simulation steps
+func TestGame_CameraShake(t *testing.T) {
+	game := NewGame()
+	camera := game.RenderSys.Camera()
+	camera.Shake(4, 0.5)
+
+	step := game.World.Clock.Step()
+	game.stepSimulation(step)
+	if x, y := camera.WorldToScreen(100, 100); x == 100 && y == 100 {
+		t.Error("Expected the view to shake after a step")
+	}
+	for range int(0.5 / step) {
+		game.stepSimulation(step)
+	}
+	if x, y := camera.WorldToScreen(100, 100); x != 100 || y != 100 {
+		t.Errorf("Expected the shake over after half a second of steps, got (%v, %v)", x, y)
+	}
+}
+
+// TestGame_CloneQuiet tests that cloning the game does not report the clone's execution order
+func TestGame_CloneQuiet(t *testing.T) {
+	game := NewGame()
+
+	var logged bytes.Buffer
+	logging.Configure(&logged, logging.FormatJSON, logging.Levels{Default: slog.LevelDebug})
+	t.Cleanup(func() {
+		logging.Configure(os.Stderr, logging.FormatText, logging.Levels{Default: slog.LevelInfo})
+	})
+	if _, err := cloneSimulation(game); err != nil {
+		t.Fatalf("Failed to clone simulation: %v", err)
+	}
+	if strings.Contains(logged.String(), `"subsystem":"systems"`) {
+		t.Errorf("Expected the clone's system manager to log nothing, got %s", logged.String())
+	}
+}
+
+// TestGame_Close tests that a closed game no longer reacts to events on its dispatcher
+func TestGame_Close(t *testing.T) {
+	game := NewGame()
+	game.Close()
+	if game.scenes.Len() != 0 {
+		t.Fatalf("Expected Close to clear the scenes, got %d", game.scenes.Len())
+	}
+
+	events.PublishImmediate(game.eventDispatcher, events.GameStart{Mode: 0})
+	events.PublishImmediate(game.eventDispatcher, events.PacketCaught{})
+	events.PublishImmediate(game.eventDispatcher, events.SLAUpdated{Current: 50, Lost: 7})
+
+	if game.scenes.Len() != 0 {
+		t.Error("Expected the game start handler to be removed")
+	}
+	if game.UISys.GetLostPackets() != 0 {
+		t.Errorf("Expected the UI to ignore events after Close, got %d lost packets", game.UISys.GetLostPackets())
+	}
+	slaSys, _ := game.systemManager.GetSystem(systems.SystemTypeSLA)
+	if slaSys.(*systems.SLASystem).GetTotalPackets() != 0 {
+		t.Error("Expected the SLA system to ignore events after Close")
+	}
+}
+
+// TestGame_Profiling tests that systems are timed only while the overlay is
+// shown or a profile is written
+func TestGame_Profiling(t *testing.T) {
+	game := NewGameWithSeed(1)
+	defer game.Close()
+	if game.systemManager.Profiler() != nil {
+		t.Error("Expected systems not to be timed by default")
+	}
+
+	game.showProfiler = true
+	game.updateProfiling()
+	if game.systemManager.Profiler() != game.profiler {
+		t.Error("Expected systems timed while the overlay is shown")
+	}
+
+	game.showProfiler = false
+	game.updateProfiling()
+	if game.systemManager.Profiler() != nil {
+		t.Error("Expected systems not timed once the overlay is hidden")
+	}
+
+	game.profilePath = "profile.json"
+	game.updateProfiling()
+	if game.systemManager.Profiler() != game.profiler {
+		t.Error("Expected systems timed while a profile is written")
+	}
+}
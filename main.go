@@ -1,21 +1,27 @@
 package main
 
 import (
-	"fmt"
+	"flag"
 	"log"
 	"os"
+	"path/filepath"
+	"time"
 
 	"lbbaspack/engine/components"
 	"lbbaspack/engine/ecs"
 	"lbbaspack/engine/entities"
 	"lbbaspack/engine/events"
+	"lbbaspack/engine/logging"
+	"lbbaspack/engine/random"
+	"lbbaspack/engine/scenes"
 	"lbbaspack/engine/systems"
 
-	"image/color"
-
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/text"
-	"golang.org/x/image/font/basicfont"
+)
+
+var (
+	gameLog      = logging.For("game")
+	gameFrameLog = logging.Every(gameLog, time.Second)
 )
 
 type Game struct {
@@ -24,57 +30,99 @@ type Game struct {
 	RenderSys       *systems.RenderSystem
 	UISys           *systems.UISystem
 	MenuSys         *systems.MenuSystem
-	gameState       components.StateType
+	scenes          scenes.Stack
+	menuScene       *menuScene
+	playingScene    *playingScene
+	pausedScene     *pausedScene
+	gameOverScene   *gameOverScene
 	eventDispatcher *events.EventDispatcher
+	subscriptions   *events.Scope
 	systemManager   *systems.SystemManager
+	profiler        *systems.Profiler
+	showProfiler    bool
+	profilePath     string // Where to write the profile report on exit, if set
+	savePath        string
+	saveStatus      string
+	keysDown        map[ebiten.Key]bool
+	exiting         bool // Set by the Exit event; ends the run loop
+}
+
+// defaultPrefabDir returns the directory of user prefab overrides in the
+// user's config directory, or "" if there is none
+func defaultPrefabDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "lbbaspack", "prefabs")
+	}
+	return ""
 }
 
 func NewGame() *Game {
-	fmt.Println("Initializing game...")
-	world := ecs.NewWorld()
+	return NewGameWithSeed(random.NewSeed())
+}
+
+// NewGameWithSeed creates a game whose randomness is fully determined by seed
+func NewGameWithSeed(seed int64) *Game {
+	return NewGameWithConfig(seed, systems.SystemConfig{})
+}
+
+// NewGameWithConfig creates a seeded game running the systems config enables
+func NewGameWithConfig(seed int64, config systems.SystemConfig) *Game {
+	gameLog.Info("Initializing game", "seed", seed)
+	world := ecs.NewWorldWithSeed(seed)
 
 	// --- Entity/Component Initialization ---
-	// Spawn Load Balancer
-	loadBalancer := world.NewEntity()
-	loadBalancer.AddComponent(components.NewTransform(350, 480))
-	loadBalancer.AddComponent(components.NewSprite(100, 20, color.RGBA{100, 100, 255, 255}))
-	loadBalancer.AddComponent(components.NewCollider(100, 20, "loadbalancer"))
-	loadBalancer.AddComponent(&components.State{Current: components.StateMenu}) // Start in menu state
-	loadBalancer.AddComponent(&components.Combo{})                              // Add combo component
-	loadBalancer.AddComponent(components.NewSLA(99.5, 10))                      // Add SLA component to load balancer
-
-	// Spawn Backends
+	// Prefabs in the user directory override the built-in ones
+	if err := world.Prefabs.LoadDir(defaultPrefabDir()); err != nil {
+		gameLog.Warn("Ignoring user prefabs", "error", err)
+	}
+
+	// Spawn Load Balancer, starting in the menu state
+	if _, err := world.Spawn("loadbalancer", nil); err != nil {
+		log.Fatalf("Failed to spawn load balancer: %v", err)
+	}
+
+	// Spawn Backends, spread evenly across the screen
 	backendCount := 4
-	backendWidth := 120
-	backendSpacing := (800 - backendWidth*backendCount) / (backendCount + 1)
-	backendY := 600 - 50
 	for i := 0; i < backendCount; i++ {
-		backend := world.NewEntity()
-		x := float64(backendSpacing + i*(backendWidth+backendSpacing))
-		backend.AddComponent(components.NewTransform(x, float64(backendY)))
-		backend.AddComponent(components.NewSprite(float64(backendWidth), 40, color.RGBA{0, 255, 0, 255}))
-		backend.AddComponent(components.NewCollider(float64(backendWidth), 40, "backend")) // Add collider for labels
-		backend.AddComponent(components.NewBackendAssignment(i))                           // Add backend assignment
-		backend.AddComponent(components.NewSLA(99.5, 10))                                  // Add SLA component
+		backend, err := world.Spawn("backend", ecs.Overrides{"BackendAssignment": {"BackendID": i}})
+		if err != nil {
+			log.Fatalf("Failed to spawn backend %d: %v", i, err)
+		}
+		width := int(backend.GetSprite().GetWidth())
+		spacing := (800 - width*backendCount) / (backendCount + 1)
+		transform := backend.GetComponent("Transform").(*components.Transform)
+		transform.SetPosition(float64(spacing+i*(width+spacing)), transform.Y)
+		transform.StorePrevious()
 	}
 
 	// --- System Initialization ---
-	eventDispatcher := events.NewEventDispatcher()
+	// Systems share the world's dispatcher so they receive entity and
+	// component lifecycle events
+	eventDispatcher := world.EventDispatcher
 
-	// Create system factory and manager
-	systemFactory := systems.NewSystemFactory(func() systems.Entity {
-		return world.NewEntity()
-	}, eventDispatcher)
+	// Hold events published during the simulation until the end of each
+	// stage, so handlers never change the game state in the middle of a
+	// system update. Menu input is still published immediately.
+	eventDispatcher.SetMode(events.DispatchQueued)
 
-	systemManager, err := systemFactory.CreateSystemManager()
+	systemFactory, systemManager, err := newSystemManager(world, config, false)
 	if err != nil {
 		log.Fatalf("Failed to create system manager: %v", err)
 	}
 
+	// The F3 overlay and the -profile report show where the frame goes.
+	// Systems are timed only while one of them needs it, see updateProfiling.
+	profiler := systems.NewProfiler(systems.DefaultProfileWindow)
+
 	// Get individual systems for special handling
 	uiSys := systems.NewUISystem(nil)     // Will be set in Draw
 	menuSys := systems.NewMenuSystem(nil) // Will be set in Draw
 	renderSys := systems.NewRenderSystem()
+	renderSys.SetTables(world) // Sprites are read straight from the world's tables
+
+	// Particles and routes are drawn through the render system's renderer,
+	// so they are layered with the sprites
+	systemManager.AttachRenderer(renderSys.Renderer())
 
 	// Initialize UI system
 	uiSys.Initialize(eventDispatcher)
@@ -85,14 +133,17 @@ func NewGame() *Game {
 		RenderSys:       renderSys,
 		UISys:           uiSys,
 		MenuSys:         menuSys,
-		gameState:       components.StateMenu,
 		eventDispatcher: eventDispatcher,
+		subscriptions:   eventDispatcher.NewScope(),
 		systemManager:   systemManager,
+		profiler:        profiler,
+		savePath:        defaultSavePath(),
 	}
+	game.setupScenes()
 
 	// Set up event handlers after game is created
-	eventDispatcher.Subscribe(events.EventGameStart, func(event *events.Event) {
-		fmt.Println("Game start event received, transitioning to playing state")
+	events.Subscribe(game.subscriptions, func(start events.GameStart) {
+		gameLog.Info("Game start event received, transitioning to playing state")
 
 		// Clean up any existing game entities (packets, power-ups, etc.)
 		game.cleanupGameEntities()
@@ -103,11 +154,11 @@ func NewGame() *Game {
 				// Reset counters first
 				slaSystem.Reset()
 				// Then set new parameters
-				if event.Data.SLA != nil {
-					slaSystem.SetTargetSLA(*event.Data.SLA)
+				if start.SLA != 0 {
+					slaSystem.SetTargetSLA(start.SLA)
 				}
-				if event.Data.Errors != nil {
-					slaSystem.SetErrorBudget(*event.Data.Errors)
+				if start.Errors != 0 {
+					slaSystem.SetErrorBudget(start.Errors)
 				}
 			}
 		}
@@ -116,150 +167,181 @@ func NewGame() *Game {
 		if game.UISys != nil {
 			game.UISys.Reset()
 			// Update UI error budget to match SLA system
-			if event.Data.Errors != nil {
-				game.UISys.SetErrorBudget(*event.Data.Errors)
+			if start.Errors != 0 {
+				game.UISys.SetErrorBudget(start.Errors)
 			}
 		}
 
 		// Initialize backend system counters
 		if backendSys, err := systemFactory.GetSystemByType(game.systemManager, systems.SystemTypeBackend); err == nil {
 			if backendSystem, ok := backendSys.(*systems.BackendSystem); ok {
-				// Convert entities to interface type
-				entitiesInterface := make([]systems.Entity, len(game.World.Entities))
-				for i, entity := range game.World.Entities {
-					entitiesInterface[i] = entity
-				}
-				backendSystem.InitializeBackendCounters(entitiesInterface)
+				backendSystem.InitializeBackendCounters(game.World.EntityList())
 			}
 		}
 
-		game.gameState = components.StatePlaying
+		game.setState(components.StatePlaying)
+	})
 
-		// Ensure load balancer state is updated to playing
-		for _, entity := range game.World.Entities {
-			if entity.HasComponent("State") {
-				stateComp := entity.GetState()
-				if stateComp != nil {
-					stateComp.SetState("playing")
-				}
-			}
-		}
+	// Shake the view while a DDoS attack hits
+	events.Subscribe(game.subscriptions, func(ddos events.DDoSStart) {
+		renderSys.Camera().Shake(4, min(ddos.Duration, 1))
 	})
 
-	eventDispatcher.Subscribe(events.EventGameOver, func(event *events.Event) {
-		game.gameState = components.StateGameOver
-		fmt.Println("Game over event received, transitioning to game over state")
+	events.Subscribe(game.subscriptions, func(events.GameOver) {
+		game.setState(components.StateGameOver)
+		gameLog.Info("Game over event received, transitioning to game over state")
 	})
 
 	// Add handler for returning to menu
-	eventDispatcher.Subscribe(events.EventReturnToMenu, func(event *events.Event) {
-		game.gameState = components.StateMenu
-		fmt.Println("Return to menu event received, transitioning to menu state")
-
-		// Reset load balancer state to menu
-		for _, entity := range game.World.Entities {
-			if entity.HasComponent("State") {
-				stateComp := entity.GetState()
-				if stateComp != nil {
-					stateComp.SetState("menu")
-					fmt.Printf("[Game] Reset entity %d state to menu\n", entity.ID)
-				}
-			}
-		}
+	events.Subscribe(game.subscriptions, func(events.ReturnToMenu) {
+		game.setState(components.StateMenu)
+		gameLog.Info("Return to menu event received, transitioning to menu state")
 	})
 
 	// Add handler for exit event
-	eventDispatcher.Subscribe(events.EventExit, func(event *events.Event) {
-		fmt.Println("Exit event received, shutting down game")
-		os.Exit(0)
+	// Ending the run loop lets main write the profile and close the journal
+	events.Subscribe(game.subscriptions, func(events.Exit) {
+		gameLog.Info("Exit event received, shutting down game")
+		game.exiting = true
 	})
 
 	return game
 }
 
+// Close removes the event handlers of the game, its systems and its scenes,
+// so that nothing keeps reacting to events once the game is discarded
+func (g *Game) Close() {
+	g.scenes.Clear()
+	if g.subscriptions != nil {
+		g.subscriptions.Close()
+	}
+	if g.systemManager != nil {
+		g.systemManager.UnsubscribeAll()
+	}
+	if g.UISys != nil {
+		g.UISys.Unsubscribe()
+	}
+}
+
 func (g *Game) Update() error {
-	deltaTime := 1.0 / 60.0 // 60 FPS
+	// Run as many fixed simulation steps as the real time since the last
+	// update calls for
+	steps := g.World.Clock.Tick()
+	step := g.World.Clock.Step()
 
-	// Always update input system for global keyboard shortcuts (like Ctrl+X)
-	entitiesInterface := make([]systems.Entity, len(g.World.Entities))
-	for i, entity := range g.World.Entities {
-		entitiesInterface[i] = entity
-	}
+	gameFrameLog.Debug("Update", "state", g.state(), "steps", steps)
 
-	// Get input system from system manager and update it
-	if g.systemManager != nil {
-		if inputSys, exists := g.systemManager.GetSystem(systems.SystemTypeInput); exists {
-			inputSys.Update(deltaTime, entitiesInterface, g.eventDispatcher)
-		}
+	if g.keyJustPressed(ebiten.KeyF3) {
+		g.showProfiler = !g.showProfiler
+		g.updateProfiling()
 	}
 
-	// Debug: Print current game state
-	fmt.Printf("[Game] Current game state: %v\n", g.gameState)
+	// The scene on top handles input and runs the steps
+	g.scenes.Update(steps, step)
 
-	// Handle game state
-	switch g.gameState {
-	case components.StateMenu:
-		fmt.Println("[Game] In menu state - updating menu system only")
-		// Menu system handles its own update
-		if g.MenuSys != nil {
-			g.MenuSys.Update(deltaTime, entitiesInterface, g.eventDispatcher)
-		}
-	case components.StatePlaying:
-		fmt.Println("[Game] In playing state - updating all systems")
-		// Update all systems using the system manager
-		g.systemManager.UpdateAll(deltaTime, entitiesInterface, g.eventDispatcher)
-
-		// Update UI system separately since it's not in the system manager
-		g.UISys.Update(deltaTime, entitiesInterface, g.eventDispatcher)
-
-		// Clean up inactive entities after all systems have updated
-		g.World.RemoveInactiveEntities()
-	case components.StateGameOver:
-		fmt.Println("[Game] In game over state")
-		// Handle escape key to return to menu
-		if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-			fmt.Println("[Game] Escape pressed in game over state, returning to menu")
-			// Clean up game entities when returning to menu
-			g.cleanupGameEntities()
-			// Publish return to menu event
-			g.eventDispatcher.Publish(events.NewEvent(events.EventReturnToMenu, nil))
-		}
+	// Deliver whatever the scenes published outside the simulation
+	if g.eventDispatcher != nil {
+		g.eventDispatcher.Flush()
 	}
 
+	if g.exiting {
+		return ebiten.Termination
+	}
 	return nil
 }
 
+// stepSimulation advances the running game by one fixed step
+func (g *Game) stepSimulation(deltaTime float64) {
+	// Remember where everything was so Draw can interpolate towards the new positions
+	g.World.StorePreviousTransforms()
+
+	// Update all systems using the system manager, phase by phase. Input is
+	// sampled first and inactive entities are removed between phases.
+	g.systemManager.UpdateAll(deltaTime, g.World.EntityList(), g.eventDispatcher)
+
+	// Update UI system separately since it's not in the system manager
+	g.UISys.Update(deltaTime, g.World.EntityList(), g.eventDispatcher)
+	g.eventDispatcher.Flush()
+
+	// The camera shake runs on simulation time, not once per drawn frame
+	g.RenderSys.Camera().Update(deltaTime)
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	if !g.initialized {
-		fmt.Println("Initializing render systems...")
 		g.UISys = systems.NewUISystem(screen)
 		g.MenuSys = systems.NewMenuSystem(screen)
 		g.initialized = true
-		fmt.Println("Render systems added successfully")
+		gameLog.Debug("Render systems initialized")
 	}
 
-	// Handle different game states
-	switch g.gameState {
-	case components.StateMenu:
-		g.MenuSys.Draw(screen)
-	case components.StatePlaying:
-		// Convert []*entities.Entity to []systems.Entity
-		entitiesInterface := make([]systems.Entity, len(g.World.Entities))
-		for i, entity := range g.World.Entities {
-			entitiesInterface[i] = entity
-		}
-		g.RenderSys.UpdateWithScreen(1.0/60.0, entitiesInterface, g.eventDispatcher, screen)
+	g.scenes.Draw(screen)
 
-		// Draw particles and routing using system manager
-		g.systemManager.DrawAll(screen, entitiesInterface)
+	if g.showProfiler && g.profiler != nil {
+		g.profiler.DrawOverlay(screen)
+	}
+}
+
+// profiling reports whether systems are timed: while the F3 overlay is shown
+// or when a profile report is written on exit
+func (g *Game) profiling() bool {
+	return g.profiler != nil && (g.showProfiler || g.profilePath != "")
+}
 
-		g.UISys.Draw(screen, entitiesInterface)
-	case components.StateGameOver:
-		// Draw game over screen
-		screen.Fill(color.RGBA{20, 20, 40, 255})
-		text.Draw(screen, "GAME OVER", basicfont.Face7x13, 350, 280, color.White)
-		text.Draw(screen, "Press ESC to return to menu", basicfont.Face7x13, 300, 320, color.White)
+// updateProfiling attaches the profiler to the system manager while the game
+// is profiled and detaches it otherwise, so that unprofiled frames are not
+// timed
+func (g *Game) updateProfiling() {
+	if g.profiling() {
+		g.systemManager.SetProfiler(g.profiler)
+	} else {
+		g.systemManager.SetProfiler(nil)
+	}
+}
+
+// measure runs fn as one call of a system drawn outside the system manager,
+// timed when the game is profiled
+func (g *Game) measure(systemType systems.SystemType, system interface{}, fn func()) {
+	if !g.profiling() {
+		fn()
+		return
+	}
+	g.profiler.Measure(systemType, systems.ProfileDraw, system, fn)
+}
+
+// writeProfile writes the profile report, if one was asked for
+func (g *Game) writeProfile() {
+	if g.profilePath == "" || g.profiler == nil {
+		return
+	}
+	if err := g.profiler.WriteReport(g.profilePath); err != nil {
+		gameLog.Error("Failed to write profile", "error", err)
+		return
 	}
+	gameLog.Info("Profile written", "path", g.profilePath)
+}
+
+// keyJustPressed reports whether key went down since the previous check
+func (g *Game) keyJustPressed(key ebiten.Key) bool {
+	if g.keysDown == nil {
+		g.keysDown = make(map[ebiten.Key]bool)
+	}
+	pressed := ebiten.IsKeyPressed(key)
+	wasPressed := g.keysDown[key]
+	g.keysDown[key] = pressed
+	return pressed && !wasPressed
+}
+
+// resumeSavedGame loads the saved game and leaves it paused so the player can resume
+func (g *Game) resumeSavedGame() {
+	if err := g.loadGame(g.savePath); err != nil {
+		gameLog.Error("Failed to load game", "error", err)
+		g.saveStatus = "Load failed"
+		return
+	}
+	gameLog.Info("Game loaded", "path", g.savePath)
+	g.saveStatus = "Game loaded"
+	g.setState(components.StatePaused)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
@@ -268,8 +350,6 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 
 // cleanupGameEntities removes all game-related entities (packets, power-ups) but keeps the load balancer and backends
 func (g *Game) cleanupGameEntities() {
-	fmt.Println("[Game] Cleaning up game entities...")
-
 	// Keep track of entities to remove
 	entitiesToRemove := make([]*entities.Entity, 0)
 
@@ -277,29 +357,110 @@ func (g *Game) cleanupGameEntities() {
 		// Check if this is a game entity (packet or power-up) that should be removed
 		if entity.HasComponent("PacketType") || entity.HasComponent("PowerUpType") {
 			entitiesToRemove = append(entitiesToRemove, entity)
-			fmt.Printf("[Game] Marking entity %d for removal (has PacketType or PowerUpType)\n", entity.ID)
 		}
 	}
 
 	// Remove the marked entities
 	for _, entity := range entitiesToRemove {
 		g.World.RemoveEntity(entity)
-		fmt.Printf("[Game] Removed entity %d\n", entity.ID)
 	}
 
-	fmt.Printf("[Game] Cleanup complete. Removed %d entities. World now has %d entities.\n",
-		len(entitiesToRemove), len(g.World.Entities))
+	gameLog.Debug("Cleaned up game entities", "removed", len(entitiesToRemove), "remaining", len(g.World.Entities))
 }
 
 func main() {
+	// Subcommands run without opening the game
+	if len(os.Args) > 1 && os.Args[1] == "systems" {
+		if err := logging.Init("", ""); err != nil {
+			log.Fatal(err)
+		}
+		if err := runCommand(os.Args[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ebiten.SetWindowSize(800, 600)
 	ebiten.SetWindowTitle("LBaaS Packet Catcher - ECS Edition")
 	// Don't set fullscreen automatically for WebAssembly - let user request it
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
-	game := NewGame()
+	seedFlag := flag.Int64("seed", 0, "seed for a reproducible run (random when not set)")
+	systemsFlag := flag.String("systems", "", "JSON file choosing the enabled systems")
+	journalFlag := flag.String("journal", "", "record every game event to this NDJSON file")
+	debugEventsFlag := flag.Bool("debug-events", false, "log every game event")
+	godFlag := flag.Bool("god", false, "never run out of error budget")
+	profileFlag := flag.String("profile", "", "write per-system timings to this JSON (or .csv) file on exit")
+	logFlag := flag.String("log", "", "log levels, e.g. info,spawn=debug (default $"+logging.EnvLevels+")")
+	logFormatFlag := flag.String("log-format", "", "log format: text or json (default $"+logging.EnvFormat+")")
+	flag.Parse()
+
+	if err := logging.Init(*logFlag, *logFormatFlag); err != nil {
+		log.Fatal(err)
+	}
+
+	seed := random.NewSeed()
+	seeded := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seed = *seedFlag
+			seeded = true
+		}
+	})
+
+	systemConfig := systems.SystemConfig{}
+	if *systemsFlag != "" {
+		config, err := systems.LoadSystemConfig(*systemsFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		systemConfig = config
+	}
+
+	game := NewGameWithConfig(seed, systemConfig)
+	if seeded {
+		// Parallel stages record commands in whatever order their systems
+		// finish, so reproducible runs execute systems one at a time
+		game.systemManager.SetExecutionMode(systems.ExecutionDeterministic)
+	}
 
-	if err := ebiten.RunGame(game); err != nil {
+	if *debugEventsFlag {
+		game.eventDispatcher.Use(events.Logger())
+	}
+	if *godFlag {
+		game.eventDispatcher.Use(events.Swallow(events.EventGameOver))
+	}
+
+	game.profilePath = *profileFlag
+	game.updateProfiling()
+
+	var recorder *events.Recorder
+	var journal *os.File
+	if *journalFlag != "" {
+		// Entries are written straight to the file, so the journal is
+		// complete up to the last event even if the game crashes
+		var err error
+		journal, err = os.Create(*journalFlag)
+		if err != nil {
+			log.Fatalf("Failed to create event journal: %v", err)
+		}
+		recorder = events.NewRecorder(journal, game.World.Clock)
+		recorder.Record(game.eventDispatcher)
+	}
+
+	// The run loop ends when the window is closed or an Exit event is
+	// published; either way the profile and journal are finished here
+	err := ebiten.RunGame(game)
+	game.writeProfile()
+	if recorder != nil {
+		if closeErr := recorder.Close(); closeErr != nil {
+			gameLog.Error("Event journal incomplete", "error", closeErr)
+		}
+		if closeErr := journal.Close(); closeErr != nil {
+			gameLog.Error("Failed to close event journal", "error", closeErr)
+		}
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
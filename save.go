@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lbbaspack/engine/ecs"
+	"lbbaspack/engine/systems"
+)
+
+// uiStateKey stores the UI system's counters alongside the managed systems
+const uiStateKey = "ui"
+
+// defaultSavePath returns the save file location in the user's config
+// directory, falling back to the working directory
+func defaultSavePath() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "lbbaspack", "save.bin")
+	}
+	return "lbbaspack-save.bin"
+}
+
+// saveGame writes the world and all system state to path. Paths ending in
+// .json are written as JSON, anything else in the binary format.
+func (g *Game) saveGame(path string) error {
+	snapshot, err := g.World.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot world: %w", err)
+	}
+
+	states, err := g.systemManager.SaveStates()
+	if err != nil {
+		return err
+	}
+	for systemType, data := range states {
+		snapshot.Systems[string(systemType)] = data
+	}
+	if g.UISys != nil {
+		data, err := g.UISys.SaveState()
+		if err != nil {
+			return fmt.Errorf("failed to save UI state: %w", err)
+		}
+		snapshot.Systems[uiStateKey] = data
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create save directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create save file: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = snapshot.EncodeJSON(file)
+	} else {
+		err = snapshot.EncodeBinary(file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	return file.Close()
+}
+
+// loadGame replaces the running game with the one saved at path
+func (g *Game) loadGame(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open save file: %w", err)
+	}
+	defer file.Close()
+
+	snapshot, err := ecs.DecodeSnapshot(file)
+	if err != nil {
+		return err
+	}
+	if err := g.World.Restore(snapshot); err != nil {
+		return fmt.Errorf("failed to restore world: %w", err)
+	}
+
+	states := make(map[systems.SystemType][]byte, len(snapshot.Systems))
+	for name, data := range snapshot.Systems {
+		if name != uiStateKey {
+			states[systems.SystemType(name)] = data
+		}
+	}
+	if err := g.systemManager.LoadStates(states); err != nil {
+		return err
+	}
+	if data, exists := snapshot.Systems[uiStateKey]; exists && g.UISys != nil {
+		if err := g.UISys.LoadState(data); err != nil {
+			return fmt.Errorf("failed to load UI state: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/systems"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// startedGame returns a game that has received the game start event
+func startedGame(t *testing.T) *Game {
+	t.Helper()
+	game := NewGame()
+	game.eventDispatcher.PublishImmediate(events.NewEvent(events.EventGameStart, &events.EventData{
+		SLA:    float64Ptr(99.9),
+		Errors: intPtr(5),
+	}))
+	return game
+}
+
+// TestGame_SaveLoadRoundtrip tests that a saved game resumes with its entities and counters
+func TestGame_SaveLoadRoundtrip(t *testing.T) {
+	for _, name := range []string{"save.bin", "save.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", name)
+
+			game := startedGame(t)
+			packet := game.World.NewEntity()
+			packet.AddComponent(components.NewTransform(123, 45))
+			packet.AddComponent(components.NewPacketType("HTTPS", 15))
+
+			slaSys, _ := game.systemManager.GetSystem(systems.SystemTypeSLA)
+			slaSys.(*systems.SLASystem).SetErrorBudget(3)
+			game.UISys.SetErrorBudget(3)
+			entityCount := len(game.World.Entities)
+
+			if err := game.saveGame(path); err != nil {
+				t.Fatalf("saveGame failed: %v", err)
+			}
+
+			resumed := NewGame()
+			if err := resumed.loadGame(path); err != nil {
+				t.Fatalf("loadGame failed: %v", err)
+			}
+
+			if len(resumed.World.Entities) != entityCount {
+				t.Errorf("Expected %d entities after load, got %d", entityCount, len(resumed.World.Entities))
+			}
+
+			found := false
+			for _, entity := range resumed.World.Entities {
+				if entity.ID == packet.ID && entity.HasComponent("PacketType") {
+					transform := entity.GetTransform()
+					found = transform != nil && transform.GetX() == 123 && transform.GetY() == 45
+				}
+			}
+			if !found {
+				t.Error("Expected saved packet to be restored with its position")
+			}
+
+			resumedSLA, _ := resumed.systemManager.GetSystem(systems.SystemTypeSLA)
+			if budget := resumedSLA.(*systems.SLASystem).GetErrorBudget(); budget != 3 {
+				t.Errorf("Expected SLA error budget 3, got %d", budget)
+			}
+			if budget := resumed.UISys.GetErrorBudget(); budget != 3 {
+				t.Errorf("Expected UI error budget 3, got %d", budget)
+			}
+		})
+	}
+}
+
+// TestGame_LoadMissingSave tests that a missing save file is reported without changing the world
+func TestGame_LoadMissingSave(t *testing.T) {
+	game := NewGame()
+	entityCount := len(game.World.Entities)
+	game.savePath = filepath.Join(t.TempDir(), "missing.bin")
+
+	if err := game.loadGame(game.savePath); err == nil {
+		t.Error("Expected loading a missing save to fail")
+	}
+
+	game.setState(components.StateGameOver)
+	game.resumeSavedGame()
+	if game.state() != components.StateGameOver {
+		t.Errorf("Expected game state to stay StateGameOver, got %v", game.state())
+	}
+	if game.saveStatus == "" {
+		t.Error("Expected a failed load to be reported on screen")
+	}
+	if len(game.World.Entities) != entityCount {
+		t.Errorf("Expected %d entities, got %d", entityCount, len(game.World.Entities))
+	}
+}
+
+// TestGame_ResumeSavedGame tests that loading a save leaves the game paused
+func TestGame_ResumeSavedGame(t *testing.T) {
+	game := startedGame(t)
+	game.savePath = filepath.Join(t.TempDir(), "save.bin")
+	if err := game.saveGame(game.savePath); err != nil {
+		t.Fatalf("saveGame failed: %v", err)
+	}
+	if _, err := os.Stat(game.savePath); err != nil {
+		t.Fatalf("Expected save file to exist: %v", err)
+	}
+
+	game.setState(components.StateMenu)
+	game.resumeSavedGame()
+	if game.state() != components.StatePaused {
+		t.Errorf("Expected game state to be StatePaused after load, got %v", game.state())
+	}
+}
+
+// TestGame_Update_Paused tests that systems do not run while paused
+func TestGame_Update_Paused(t *testing.T) {
+	game := startedGame(t)
+	game.setState(components.StatePaused)
+
+	gameStateSys, _ := game.systemManager.GetSystem(systems.SystemTypeGameState)
+	before := gameStateSys.(*systems.GameStateSystem).GetGameTime()
+
+	if err := game.Update(); err != nil {
+		t.Errorf("Expected no error from Update, got %v", err)
+	}
+
+	if game.state() != components.StatePaused {
+		t.Errorf("Expected game state to remain StatePaused, got %v", game.state())
+	}
+	if after := gameStateSys.(*systems.GameStateSystem).GetGameTime(); after != before {
+		t.Errorf("Expected game time to stay at %f while paused, got %f", before, after)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"image/color"
+
+	"lbbaspack/engine/components"
+	"lbbaspack/engine/events"
+	"lbbaspack/engine/scenes"
+	"lbbaspack/engine/systems"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/basicfont"
+)
+
+// gameScene is a scene that stands for one of the game's states. Entering or
+// resuming it writes that state to every State component, so that systems
+// see the screen the player is on.
+type gameScene struct {
+	scenes.BaseScene
+	game  *Game
+	state components.StateType
+}
+
+// newGameScene creates a scene for state that updates the given systems each step
+func newGameScene(game *Game, state components.StateType, sceneSystems ...systems.System) gameScene {
+	return gameScene{
+		BaseScene: scenes.BaseScene{
+			Systems:    sceneSystems,
+			Entities:   game.World.EntityList,
+			Dispatcher: game.eventDispatcher,
+		},
+		game:  game,
+		state: state,
+	}
+}
+
+// State returns the game state the scene stands for
+func (gs *gameScene) State() components.StateType {
+	return gs.state
+}
+
+// Enter implements scenes.Scene
+func (gs *gameScene) Enter(stack *scenes.Stack) {
+	gs.BaseScene.Enter(stack)
+	gs.syncStateComponents()
+}
+
+// Resume implements scenes.Scene
+func (gs *gameScene) Resume() {
+	gs.syncStateComponents()
+}
+
+// syncStateComponents writes the scene's state to every State component
+func (gs *gameScene) syncStateComponents() {
+	name := components.NewState(gs.state).GetState()
+	for _, entity := range gs.game.World.Entities {
+		if stateComp := entity.GetState(); stateComp != nil {
+			stateComp.SetState(name)
+		}
+	}
+}
+
+// menuScene shows the main menu
+type menuScene struct {
+	gameScene
+}
+
+// HandleInput implements scenes.Scene
+func (ms *menuScene) HandleInput() {
+	if ms.game.keyJustPressed(ebiten.KeyL) {
+		ms.game.resumeSavedGame()
+	}
+}
+
+// Draw implements scenes.Scene
+func (ms *menuScene) Draw(screen *ebiten.Image) {
+	if ms.game.MenuSys != nil {
+		ms.game.MenuSys.Draw(screen)
+	}
+}
+
+// playingScene runs the simulation
+type playingScene struct {
+	gameScene
+}
+
+// HandleInput implements scenes.Scene
+func (ps *playingScene) HandleInput() {
+	if ps.game.keyJustPressed(ebiten.KeyP) {
+		gameLog.Debug("P pressed, pausing game")
+		ps.game.saveStatus = ""
+		ps.Stack().Push(ps.game.pausedScene)
+	}
+}
+
+// Step implements scenes.Scene
+func (ps *playingScene) Step(deltaTime float64) {
+	ps.game.stepSimulation(deltaTime)
+}
+
+// Draw implements scenes.Scene
+func (ps *playingScene) Draw(screen *ebiten.Image) {
+	g := ps.game
+	entitiesInterface := g.World.EntityList()
+
+	// Particles and routes submit to the render system's renderer, which
+	// draws them by layer together with the sprites
+	g.systemManager.DrawAll(screen, entitiesInterface)
+
+	g.RenderSys.SetInterpolationAlpha(g.World.Clock.Alpha())
+	g.measure(systems.SystemTypeRender, g.RenderSys, func() {
+		g.RenderSys.UpdateWithScreen(g.World.Clock.Step(), entitiesInterface, g.eventDispatcher, screen)
+	})
+
+	g.measure(systems.SystemTypeUI, g.UISys, func() {
+		g.UISys.Draw(screen, entitiesInterface)
+	})
+}
+
+// pausedScene is drawn over the frozen game and offers saving and loading
+type pausedScene struct {
+	gameScene
+}
+
+// HandleInput implements scenes.Scene
+func (ps *pausedScene) HandleInput() {
+	g := ps.game
+	switch {
+	case g.keyJustPressed(ebiten.KeyP):
+		gameLog.Debug("P pressed, resuming game")
+		ps.Stack().Pop()
+	case g.keyJustPressed(ebiten.KeyS):
+		if err := g.saveGame(g.savePath); err != nil {
+			gameLog.Error("Failed to save game", "error", err)
+			g.saveStatus = "Save failed"
+		} else {
+			gameLog.Info("Game saved", "path", g.savePath)
+			g.saveStatus = "Game saved"
+		}
+	case g.keyJustPressed(ebiten.KeyL):
+		g.resumeSavedGame()
+	case ebiten.IsKeyPressed(ebiten.KeyEscape):
+		gameLog.Debug("Escape pressed while paused, returning to menu")
+		g.cleanupGameEntities()
+		events.PublishImmediate(g.eventDispatcher, events.ReturnToMenu{})
+	}
+}
+
+// Draw implements scenes.Scene
+func (ps *pausedScene) Draw(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, 800, 600, color.RGBA{20, 20, 40, 220}, false)
+	text.Draw(screen, "PAUSED", basicfont.Face7x13, 370, 260, color.White)
+	text.Draw(screen, "P - Resume   S - Save   L - Load   ESC - Menu", basicfont.Face7x13, 245, 300, color.White)
+	text.Draw(screen, ps.game.saveStatus, basicfont.Face7x13, 350, 330, color.White)
+}
+
+// gameOverScene shows the end of a run
+type gameOverScene struct {
+	gameScene
+}
+
+// HandleInput implements scenes.Scene
+func (gs *gameOverScene) HandleInput() {
+	g := gs.game
+	if g.keyJustPressed(ebiten.KeyL) {
+		g.resumeSavedGame()
+		return
+	}
+	// Handle escape key to return to menu
+	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		gameLog.Debug("Escape pressed in game over state, returning to menu")
+		// Clean up game entities when returning to menu
+		g.cleanupGameEntities()
+		// Publish return to menu event
+		events.PublishImmediate(g.eventDispatcher, events.ReturnToMenu{})
+	}
+}
+
+// Draw implements scenes.Scene
+func (gs *gameOverScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{20, 20, 40, 255})
+	text.Draw(screen, "GAME OVER", basicfont.Face7x13, 350, 280, color.White)
+	text.Draw(screen, "Press ESC to return to menu", basicfont.Face7x13, 300, 320, color.White)
+	text.Draw(screen, "Press L to load saved game", basicfont.Face7x13, 305, 340, color.White)
+	text.Draw(screen, gs.game.saveStatus, basicfont.Face7x13, 350, 370, color.White)
+}
+
+// setupScenes creates the game's scenes and starts on the menu. The menu and
+// game over screens only run the input system, for global shortcuts.
+func (g *Game) setupScenes() {
+	var inputSystems []systems.System
+	if inputSys, exists := g.systemManager.GetSystem(systems.SystemTypeInput); exists {
+		inputSystems = append(inputSystems, inputSys)
+	}
+	menuSystems := append([]systems.System{}, inputSystems...)
+	if g.MenuSys != nil {
+		menuSystems = append(menuSystems, g.MenuSys)
+	}
+
+	g.menuScene = &menuScene{newGameScene(g, components.StateMenu, menuSystems...)}
+	g.playingScene = &playingScene{newGameScene(g, components.StatePlaying)}
+	g.pausedScene = &pausedScene{newGameScene(g, components.StatePaused)}
+	g.pausedScene.Overlay = true
+	g.gameOverScene = &gameOverScene{newGameScene(g, components.StateGameOver, inputSystems...)}
+	g.scenes.Reset(g.menuScene)
+}
+
+// state returns the game state of the scene in control
+func (g *Game) state() components.StateType {
+	if scene, ok := g.scenes.Top().(interface{ State() components.StateType }); ok {
+		return scene.State()
+	}
+	return components.StateMenu
+}
+
+// setState switches to the scene for a game state. A paused game keeps the
+// running game beneath the pause overlay.
+func (g *Game) setState(state components.StateType) {
+	switch state {
+	case components.StatePlaying:
+		g.scenes.Reset(g.playingScene)
+	case components.StatePaused:
+		g.scenes.Reset(g.playingScene)
+		g.scenes.Push(g.pausedScene)
+	case components.StateGameOver:
+		g.scenes.Reset(g.gameOverScene)
+	default:
+		g.scenes.Reset(g.menuScene)
+	}
+}
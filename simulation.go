@@ -0,0 +1,49 @@
+package main
+
+import (
+	"lbbaspack/engine/ecs"
+	"lbbaspack/engine/systems"
+)
+
+// newSystemManager creates the game systems config enables and attaches them
+// to world. A quiet manager does not report its execution order.
+func newSystemManager(world *ecs.World, config systems.SystemConfig, quiet bool) (*systems.SystemFactory, *systems.SystemManager, error) {
+	// Create system factory and manager
+	// Spawned packets and power-ups are recycled through the world's pool
+	systemFactory := systems.NewSystemFactory(func() systems.Entity {
+		return world.AcquireEntity()
+	}, world.EventDispatcher)
+	systemFactory.SetQuiet(quiet)
+
+	systemManager, err := systemFactory.CreateConfiguredSystemManager(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Give systems cached entity views maintained by the world
+	systemManager.AttachViews(world)
+
+	// Let the busiest systems read components straight from the world's
+	// tables instead of looking them up by name
+	systemManager.AttachTables(world)
+
+	// Let systems follow entity handles carried in events and components
+	systemManager.AttachResolver(world)
+
+	// Draw components for spawned entities from the world's pool and build
+	// them from the world's prefabs
+	systemManager.AttachComponentPool(world)
+	systemManager.AttachPrefabs(world.Prefabs)
+
+	// Draw all randomness from the world's seeded streams
+	systemManager.AttachRNG(world.RNG)
+
+	// Defer entity creation, destruction and component changes made by
+	// systems to sync points between them
+	systemManager.AttachCommands(ecs.NewCommandBuffer(world))
+
+	// Remove deactivated entities from the world between phases
+	systemManager.AttachEntityStore(world)
+
+	return systemFactory, systemManager, nil
+}